@@ -29,6 +29,22 @@ func run(c *cli.Context) {
 	_cli.Run(c)
 }
 
+func compare(c *cli.Context) {
+	_cli.Compare(c)
+}
+
+func coverage(c *cli.Context) {
+	_cli.Coverage(c)
+}
+
+func diff(c *cli.Context) {
+	_cli.Diff(c)
+}
+
+func export(c *cli.Context) {
+	_cli.Export(c)
+}
+
 func main() {
 	viper.AutomaticEnv()
 
@@ -54,12 +70,129 @@ func main() {
 				Name:  "save",
 				Usage: "save speculator state to a given path after learning",
 			},
+			cli.StringFlag{
+				Name:  "metadata",
+				Usage: "path to a yaml file of the generated specs metadata (global and per spec key info, servers and tags)",
+			},
 		},
 	}
 	runCommand.UsageText = runCommand.Name
 
+	compareCommand := cli.Command{
+		Name:      "compare",
+		Usage:     "CLI to compare two OAS files (v2 or v3, json or yaml)",
+		UsageText: "compare [--format text|json|markdown] original.yaml modified.yaml",
+		Action:    compare,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "format",
+				Usage: "output format (text, json or markdown)",
+				Value: _cli.OutputFormatText,
+			},
+		},
+	}
+
+	coverageCommand := cli.Command{
+		Name:      "coverage",
+		Usage:     "CLI to report the coverage of a provided OAS by HTTP transaction files",
+		UsageText: "coverage --spec provided.yaml [--format text|json] -t file1.json -t file2.json",
+		Action:    coverage,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "spec",
+				Usage: "path to the provided spec file (v2 or v3, json or yaml), directory or zip/tar archive of a multi-file spec",
+			},
+			cli.StringSliceFlag{
+				Name:  "t",
+				Usage: "path to a telemetry json file (can be ran with multiple files, e.g. -t file1.json -t file2.json)",
+			},
+			cli.StringFlag{
+				Name:  "state",
+				Usage: "path to an encoded speculator state file",
+			},
+			cli.StringFlag{
+				Name:  "save",
+				Usage: "save speculator state to a given path after learning",
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Usage: "output format (text or json)",
+				Value: _cli.OutputFormatText,
+			},
+		},
+	}
+
+	diffCommand := cli.Command{
+		Name:      "diff",
+		Usage:     "CLI to diff HTTP transaction files against a provided OAS or the approved spec of a state",
+		UsageText: "diff [--spec provided.yaml] [--state state.gob] [--suppression-rules rules.yaml] [--format text|json] -t file1.json -t file2.json",
+		Action:    diff,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "spec",
+				Usage: "path to the provided spec file (v2 or v3, json or yaml), directory or zip/tar archive of a multi-file spec, the approved spec of the state is used if not set",
+			},
+			cli.StringSliceFlag{
+				Name:  "t",
+				Usage: "path to a telemetry json file (can be ran with multiple files, e.g. -t file1.json -t file2.json)",
+			},
+			cli.StringFlag{
+				Name:  "state",
+				Usage: "path to an encoded speculator state file",
+			},
+			cli.StringFlag{
+				Name:  "suppression-rules",
+				Usage: "path to a yaml file of diff suppression rules",
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Usage: "output format (text or json)",
+				Value: _cli.OutputFormatText,
+			},
+		},
+	}
+
+	exportCommand := cli.Command{
+		Name:      "export",
+		Usage:     "CLI to export the approved or learning specs of a state (and HTTP transaction files) to a Postman collection",
+		UsageText: "export [--state state.gob] [--source approved|learning] [--key host:port] [--output dir] --format postman [-t file1.json]",
+		Action:    export,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "state",
+				Usage: "path to an encoded speculator state file",
+			},
+			cli.StringSliceFlag{
+				Name:  "t",
+				Usage: "path to a telemetry json file to learn before the export (can be ran with multiple files, e.g. -t file1.json -t file2.json)",
+			},
+			cli.StringFlag{
+				Name:  "source",
+				Usage: "spec to export (approved or learning)",
+				Value: "approved",
+			},
+			cli.StringFlag{
+				Name:  "key",
+				Usage: "key (host:port) of the spec to export, all the specs are exported if not set",
+			},
+			cli.StringFlag{
+				Name:  "output",
+				Usage: "directory to write a collection file per spec, the collection is printed if not set",
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Usage: "export format (postman)",
+				Value: _cli.ExportFormatPostman,
+			},
+		},
+	}
+
 	app.Commands = []cli.Command{
 		runCommand,
+		compareCommand,
+		coverageCommand,
+		diffCommand,
+		exportCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -0,0 +1,148 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/openclarity/speculator/pkg/spec"
+)
+
+const (
+	OutputFormatText     = "text"
+	OutputFormatJSON     = "json"
+	OutputFormatMarkdown = "markdown"
+)
+
+func Compare(c *cli.Context) {
+	if c.NArg() != 2 { // nolint:gomnd
+		log.Fatalf("Expected 2 spec files to compare, got %v", c.NArg())
+	}
+	originalPath, modifiedPath := c.Args().Get(0), c.Args().Get(1)
+
+	original, err := ioutil.ReadFile(originalPath)
+	if err != nil {
+		log.Fatalf("Failed to read from file: %v. %v", originalPath, err)
+	}
+	modified, err := ioutil.ReadFile(modifiedPath)
+	if err != nil {
+		log.Fatalf("Failed to read from file: %v. %v", modifiedPath, err)
+	}
+
+	comparison, err := spec.CompareRawSpecs(original, modified)
+	if err != nil {
+		log.Fatalf("Failed to compare specs. %v", err)
+	}
+
+	output, err := formatSpecComparison(comparison, c.String("format"))
+	if err != nil {
+		log.Fatalf("Failed to format comparison. %v", err)
+	}
+	fmt.Print(output)
+}
+
+func formatSpecComparison(comparison *spec.SpecComparison, format string) (string, error) {
+	switch format {
+	case OutputFormatText, "":
+		return formatSpecComparisonText(comparison), nil
+	case OutputFormatJSON:
+		comparisonB, err := json.MarshalIndent(comparison, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal comparison: %w", err)
+		}
+		return string(comparisonB) + "\n", nil
+	case OutputFormatMarkdown:
+		return formatSpecComparisonMarkdown(comparison), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func formatSpecComparisonText(comparison *spec.SpecComparison) string {
+	var sb strings.Builder
+
+	for _, change := range comparison.Changes {
+		fmt.Fprintf(&sb, "[consumer: %v, provider: %v] %v %v", change.ConsumerSeverity, change.ProviderSeverity,
+			change.Kind, getChangeTarget(change))
+		if values := getChangeValues(change); values != "" {
+			fmt.Fprintf(&sb, ": %v", values)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "%v changes, severity: %v\n", len(comparison.Changes), comparison.Severity)
+
+	return sb.String()
+}
+
+func formatSpecComparisonMarkdown(comparison *spec.SpecComparison) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "## Spec comparison: %v\n\n", comparison.Severity)
+	if len(comparison.Changes) == 0 {
+		sb.WriteString("No changes.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Consumer | Provider | Change | Target | Values |\n")
+	sb.WriteString("|---|---|---|---|---|\n")
+	for _, change := range comparison.Changes {
+		fmt.Fprintf(&sb, "| %v | %v | %v | `%v` | %v |\n", change.ConsumerSeverity, change.ProviderSeverity,
+			change.Kind, getChangeTarget(change), strings.ReplaceAll(getChangeValues(change), "|", "\\|"))
+	}
+
+	return sb.String()
+}
+
+// getChangeTarget returns the changed path, operation or location, e.g. GET /users/{id} /responses/200.
+func getChangeTarget(change spec.SpecChange) string {
+	target := change.Path
+	if change.Method != "" {
+		target = change.Method + " " + target
+	}
+	if len(change.Location) > 0 {
+		target += " " + change.Location.String()
+	}
+
+	return target
+}
+
+func getChangeValues(change spec.SpecChange) string {
+	switch change.Kind {
+	case spec.DiffChangeKindTypeChanged, spec.DiffChangeKindFormatChanged, spec.DiffChangeKindChanged:
+		return fmt.Sprintf("%v -> %v", formatChangeValue(change.OldValue), formatChangeValue(change.NewValue))
+	default:
+		return ""
+	}
+}
+
+func formatChangeValue(value interface{}) string {
+	if value == nil {
+		return "none"
+	}
+
+	valueB, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(valueB)
+}
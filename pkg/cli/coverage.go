@@ -0,0 +1,172 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/openclarity/speculator/pkg/spec"
+	"github.com/openclarity/speculator/pkg/speculator"
+)
+
+func Coverage(c *cli.Context) {
+	specPath := c.String("spec")
+	if specPath == "" {
+		log.Fatalf("Missing provided spec file")
+	}
+	providedSpec := readProvidedSpec(specPath)
+
+	s := loadOrCreateSpeculator(c.String("state"), createSpeculatorConfig())
+	for _, fileName := range c.StringSlice("t") {
+		telemetry, err := readTelemetry(fileName)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if err := loadProvidedSpecIfNeeded(s, telemetry, providedSpec); err != nil {
+			log.Errorf("Failed to load provided spec. %v", err)
+			continue
+		}
+		if err := s.LearnTelemetry(telemetry); err != nil {
+			log.Errorf("Failed to learn telemetry. %v", err)
+			continue
+		}
+	}
+
+	reports := make(map[speculator.SpecKey]*spec.CoverageReport)
+	for specKey := range s.Specs {
+		if !s.HasProvidedSpec(specKey) {
+			continue
+		}
+		report, err := s.GetProvidedSpecCoverage(specKey)
+		if err != nil {
+			log.Fatalf("Failed to get coverage of %v. %v", specKey, err)
+		}
+		reports[specKey] = report
+	}
+
+	output, err := formatCoverageReports(reports, c.String("format"))
+	if err != nil {
+		log.Fatalf("Failed to format coverage. %v", err)
+	}
+	fmt.Print(output)
+	saveSpeculator(s, c.String("save"))
+}
+
+// readProvidedSpec reads and validates the provided spec bundle from a spec file, a directory or a zip, tar or tar.gz
+// archive. The bundle of a spec file includes the files that are reached through its external refs.
+func readProvidedSpec(specPath string) *spec.SpecBundle {
+	info, err := os.Stat(specPath)
+	if err != nil {
+		log.Fatalf("Failed to read from file: %v. %v", specPath, err)
+	}
+
+	var bundle *spec.SpecBundle
+	if info.IsDir() {
+		bundle, err = spec.LoadSpecBundleFromDir(specPath, "")
+	} else {
+		bundle, err = spec.LoadSpecBundleFromFile(specPath)
+	}
+	if err != nil {
+		log.Fatalf("Failed to read provided spec bundle: %v. %v", specPath, err)
+	}
+
+	if _, _, err := spec.LoadAndValidateSpecBundle(bundle); err != nil {
+		log.Fatalf("Failed to load provided spec. %v", err)
+	}
+
+	return bundle
+}
+
+// loadProvidedSpecIfNeeded loads the provided spec to the telemetry spec (with generated path IDs), if it was not
+// loaded already.
+func loadProvidedSpecIfNeeded(s *speculator.Speculator, telemetry *spec.Telemetry, providedSpec *spec.SpecBundle) error {
+	destInfo, err := speculator.GetAddressInfoFromAddress(telemetry.DestinationAddress)
+	if err != nil {
+		return fmt.Errorf("failed get destination info: %v", err)
+	}
+	specKey := speculator.GetSpecKey(telemetry.Request.Host, destInfo.Port)
+	if s.HasProvidedSpec(specKey) {
+		return nil
+	}
+
+	if _, ok := s.Specs[specKey]; !ok {
+		if err := s.InitSpec(telemetry.Request.Host, destInfo.Port); err != nil {
+			return fmt.Errorf("failed to init spec: %v", err)
+		}
+	}
+
+	return s.LoadProvidedSpecBundle(specKey, providedSpec, nil)
+}
+
+func formatCoverageReports(reports map[speculator.SpecKey]*spec.CoverageReport, format string) (string, error) {
+	switch format {
+	case OutputFormatText, "":
+		return formatCoverageReportsText(reports), nil
+	case OutputFormatJSON:
+		reportsB, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal coverage reports: %w", err)
+		}
+		return string(reportsB) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func formatCoverageReportsText(reports map[speculator.SpecKey]*spec.CoverageReport) string {
+	var sb strings.Builder
+
+	specKeys := make([]string, 0, len(reports))
+	for specKey := range reports {
+		specKeys = append(specKeys, string(specKey))
+	}
+	sort.Strings(specKeys)
+
+	for _, specKey := range specKeys {
+		report := reports[speculator.SpecKey(specKey)]
+		fmt.Fprintf(&sb, "Coverage of %v\n", specKey)
+		writeCoverageStats(&sb, "Operations", report.Operations)
+		writeCoverageStats(&sb, "Status codes", report.StatusCodes)
+		writeCoverageStats(&sb, "Parameters", report.Parameters)
+		writeCoverageStats(&sb, "Content types", report.ContentTypes)
+		if len(report.UnusedOperations) > 0 {
+			sb.WriteString("  Unused operations:\n")
+			for _, operation := range report.UnusedOperations {
+				fmt.Fprintf(&sb, "    %v %v\n", operation.Method, operation.Path)
+			}
+		}
+		if len(report.PartiallyCoveredOperations) > 0 {
+			sb.WriteString("  Partially covered operations:\n")
+			for _, operation := range report.PartiallyCoveredOperations {
+				fmt.Fprintf(&sb, "    %v %v, unseen: %v\n", operation.Method, operation.Path, strings.Join(operation.Unseen, ", "))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func writeCoverageStats(sb *strings.Builder, name string, stats spec.CoverageStats) {
+	fmt.Fprintf(sb, "  %v: %v/%v (%.2f%%)\n", name, stats.Covered, stats.Total, stats.Percentage)
+}
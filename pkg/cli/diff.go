@@ -0,0 +1,119 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/openclarity/speculator/pkg/spec"
+)
+
+// Diff diffs the telemetries against the provided spec, or against the approved spec of the state if no provided spec
+// is given, and prints the diffs.
+func Diff(c *cli.Context) {
+	speculatorConfig := createSpeculatorConfig()
+	if rulesPath := c.String("suppression-rules"); rulesPath != "" {
+		rulesB, err := ioutil.ReadFile(rulesPath)
+		if err != nil {
+			log.Fatalf("Failed to read from file: %v. %v", rulesPath, err)
+		}
+		speculatorConfig.SuppressionRules, err = spec.LoadSuppressionRules(rulesB)
+		if err != nil {
+			log.Fatalf("Failed to load suppression rules. %v", err)
+		}
+	}
+	s := loadOrCreateSpeculator(c.String("state"), speculatorConfig)
+
+	specSource := spec.SpecSourceReconstructed
+	var providedSpec *spec.SpecBundle
+	if specPath := c.String("spec"); specPath != "" {
+		providedSpec = readProvidedSpec(specPath)
+		specSource = spec.SpecSourceProvided
+	}
+
+	var diffs []*spec.APIDiff
+	for _, fileName := range c.StringSlice("t") {
+		telemetry, err := readTelemetry(fileName)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if providedSpec != nil {
+			if err := loadProvidedSpecIfNeeded(s, telemetry, providedSpec); err != nil {
+				log.Errorf("Failed to load provided spec. %v", err)
+				continue
+			}
+		}
+		apiDiff, err := s.DiffTelemetry(telemetry, specSource)
+		if err != nil {
+			log.Errorf("Failed to diff telemetry. %v", err)
+			continue
+		}
+		if apiDiff != nil && apiDiff.Type != spec.DiffTypeNoDiff {
+			diffs = append(diffs, apiDiff)
+		}
+	}
+
+	output, err := formatAPIDiffs(diffs, c.String("format"))
+	if err != nil {
+		log.Fatalf("Failed to format diffs. %v", err)
+	}
+	fmt.Print(output)
+}
+
+func formatAPIDiffs(diffs []*spec.APIDiff, format string) (string, error) {
+	switch format {
+	case OutputFormatText, "":
+		return formatAPIDiffsText(diffs), nil
+	case OutputFormatJSON:
+		diffsB, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal diffs: %w", err)
+		}
+		return string(diffsB) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func formatAPIDiffsText(diffs []*spec.APIDiff) string {
+	var sb strings.Builder
+
+	for _, apiDiff := range diffs {
+		fmt.Fprintf(&sb, "%v %v", apiDiff.Type, apiDiff.Path)
+		if apiDiff.Severity != "" {
+			fmt.Fprintf(&sb, " (%v)", apiDiff.Severity)
+		}
+		sb.WriteString("\n")
+		for _, change := range apiDiff.Changes {
+			fmt.Fprintf(&sb, "  [consumer: %v, provider: %v] %v %v", change.ConsumerSeverity, change.ProviderSeverity,
+				change.Kind, change.Location)
+			if values := getChangeValues(spec.SpecChange{DiffChange: change}); values != "" {
+				fmt.Fprintf(&sb, ": %v", values)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(&sb, "%v diffs\n", len(diffs))
+
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/openclarity/speculator/pkg/spec"
+	"github.com/openclarity/speculator/pkg/speculator"
+)
+
+const (
+	ExportFormatPostman = "postman"
+
+	postmanCollectionFileSuffix = ".postman_collection.json"
+)
+
+// Export exports the approved or learning specs of the state (after learning the telemetries), to a collection file
+// per spec in the output directory, or to the stdout if a single spec is exported.
+func Export(c *cli.Context) {
+	format := c.String("format")
+	if format != ExportFormatPostman {
+		log.Fatalf("Unknown export format %q", format)
+	}
+	source := spec.ExportSource(strings.ToUpper(c.String("source")))
+
+	s := loadOrCreateSpeculator(c.String("state"), createSpeculatorConfig())
+	for _, fileName := range c.StringSlice("t") {
+		telemetry, err := readTelemetry(fileName)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if err := s.LearnTelemetry(telemetry); err != nil {
+			log.Errorf("Failed to learn telemetry. %v", err)
+			continue
+		}
+	}
+
+	specKeys := getExportSpecKeys(s, c.String("key"))
+	outputDir := c.String("output")
+	if outputDir == "" && len(specKeys) != 1 {
+		log.Fatalf("Found %v specs, set a spec key or an output directory", len(specKeys))
+	}
+
+	for _, specKey := range specKeys {
+		collection, err := s.ExportPostmanCollection(specKey, source)
+		if err != nil {
+			log.Fatalf("Failed to export spec %v. %v", specKey, err)
+		}
+		collectionB, err := json.MarshalIndent(collection, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to marshal Postman collection. %v", err)
+		}
+
+		if outputDir == "" {
+			fmt.Println(string(collectionB))
+			continue
+		}
+		fileName := filepath.Join(outputDir, strings.ReplaceAll(string(specKey), ":", "_")+postmanCollectionFileSuffix)
+		if err := ioutil.WriteFile(fileName, append(collectionB, '\n'), 0o600); err != nil { // nolint:gomnd
+			log.Fatalf("Failed to write to file: %v. %v", fileName, err)
+		}
+		log.Infof("Exported spec %v to %v", specKey, fileName)
+	}
+}
+
+func getExportSpecKeys(s *speculator.Speculator, key string) []speculator.SpecKey {
+	if key != "" {
+		return []speculator.SpecKey{speculator.SpecKey(key)}
+	}
+
+	specKeys := make([]speculator.SpecKey, 0, len(s.Specs))
+	for specKey := range s.Specs {
+		specKeys = append(specKeys, specKey)
+	}
+	sort.Slice(specKeys, func(i, j int) bool {
+		return specKeys[i] < specKeys[j]
+	})
+
+	return specKeys
+}
@@ -17,6 +17,7 @@ package cli
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 
 	log "github.com/sirupsen/logrus"
@@ -28,34 +29,15 @@ import (
 )
 
 func Run(c *cli.Context) {
-	statePath := c.String("state")
-	var s *speculator.Speculator
-
-	speculatorConfig := createSpeculatorConfig()
-	if statePath != "" {
-		var err error
-		s, err = speculator.DecodeState(statePath, speculatorConfig)
-		if err != nil {
-			log.Fatalf("Failed to decode stored state in path %v", statePath)
-		}
-	} else {
-		s = speculator.CreateSpeculator(speculatorConfig)
-	}
+	s := loadOrCreateSpeculator(c.String("state"), createSpeculatorConfig())
 	fileNames := c.StringSlice("t")
 
 	log.Infof("Reading interactions from files...")
 
 	for _, fileName := range fileNames {
-		log.Infof("Reading telemetry from %s", fileName)
-		telemetryB, err := ioutil.ReadFile(fileName)
+		telemetry, err := readTelemetry(fileName)
 		if err != nil {
-			log.Errorf("Failed to read from file: %v. %v", fileName, err)
-			continue
-		}
-		telemetry := &spec.Telemetry{}
-		err = json.Unmarshal(telemetryB, telemetry)
-		if err != nil {
-			log.Errorf("Failed to unmarshal telemetry. %v", err)
+			log.Error(err)
 			continue
 		}
 		log.Infof("Learning HTTP interaction for %v %v%v", telemetry.Request.Method, telemetry.Request.Host, telemetry.Request.Path)
@@ -66,20 +48,81 @@ func Run(c *cli.Context) {
 		}
 		log.Infof("Learned HTTP interaction for %v %v%v", telemetry.Request.Method, telemetry.Request.Host, telemetry.Request.Path)
 	}
+	if metadataPath := c.String("metadata"); metadataPath != "" {
+		setSpecsMetadata(s, metadataPath)
+	}
 	log.Infof("Generating specs")
 	s.DumpSpecs()
-	if c.String("save") != "" {
-		if err := s.EncodeState(c.String("save")); err != nil {
-			log.Fatalf("Failed to encode speculator: %v", err)
+	saveSpeculator(s, c.String("save"))
+}
+
+func loadOrCreateSpeculator(statePath string, speculatorConfig speculator.Config) *speculator.Speculator {
+	if statePath == "" {
+		return speculator.CreateSpeculator(speculatorConfig)
+	}
+
+	s, err := speculator.DecodeState(statePath, speculatorConfig)
+	if err != nil {
+		log.Fatalf("Failed to decode stored state in path %v", statePath)
+	}
+
+	return s
+}
+
+func saveSpeculator(s *speculator.Speculator, savePath string) {
+	if savePath == "" {
+		return
+	}
+
+	if err := s.EncodeState(savePath); err != nil {
+		log.Fatalf("Failed to encode speculator: %v", err)
+	}
+}
+
+// setSpecsMetadata sets the global metadata and the metadata of the learned specs from the metadata file.
+func setSpecsMetadata(s *speculator.Speculator, metadataPath string) {
+	metadataB, err := ioutil.ReadFile(metadataPath)
+	if err != nil {
+		log.Fatalf("Failed to read from file: %v. %v", metadataPath, err)
+	}
+	specsMetadata, err := spec.LoadSpecsMetadata(metadataB)
+	if err != nil {
+		log.Fatalf("Failed to load specs metadata. %v", err)
+	}
+
+	if specsMetadata.Global != nil {
+		s.SetGlobalSpecMetadata(specsMetadata.Global)
+	}
+	for specKey, metadata := range specsMetadata.Specs {
+		if err := s.SetSpecMetadata(speculator.SpecKey(specKey), metadata); err != nil {
+			log.Warnf("Failed to set spec metadata. %v", err)
 		}
 	}
 }
 
+func readTelemetry(fileName string) (*spec.Telemetry, error) {
+	log.Infof("Reading telemetry from %s", fileName)
+	telemetryB, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from file: %v. %v", fileName, err)
+	}
+
+	telemetry := &spec.Telemetry{}
+	if err := json.Unmarshal(telemetryB, telemetry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal telemetry. %v", err)
+	}
+
+	return telemetry, nil
+}
+
 func createSpeculatorConfig() speculator.Config {
 	return speculator.Config{
 		OperationGeneratorConfig: spec.OperationGeneratorConfig{
 			ResponseHeadersToIgnore: viper.GetStringSlice("RESPONSE_HEADERS_TO_IGNORE"),
 			RequestHeadersToIgnore:  viper.GetStringSlice("REQUEST_HEADERS_TO_IGNORE"),
 		},
+		DiffMode:        spec.DiffMode(viper.GetString("DIFF_MODE")),
+		ValidateValues:  viper.GetBool("VALIDATE_VALUES"),
+		CanonicalFormat: viper.GetBool("CANONICAL_FORMAT"),
 	}
 }
@@ -0,0 +1,146 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathtrie
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+// AmbiguousPaths is a pair of path templates that can both match the same path
+// and have the same amount of path params segments, so only the tie-breaking rules decide between them.
+type AmbiguousPaths struct {
+	// PreferredPath is the path template that will be matched.
+	PreferredPath string
+	// OtherPath is the path template that will not be matched.
+	OtherPath string
+}
+
+type ambiguousPathsGroupKey struct {
+	segmentsCount    int
+	pathParamCounter int
+}
+
+type splitValueNode struct {
+	node     *TrieNode
+	segments []string
+}
+
+// GetAmbiguousPaths returns all the ambiguous path templates pairs in the trie, sorted by PreferredPath and OtherPath.
+// Only path templates with the same amount of segments and path params can be ambiguous, so the path templates are
+// split once and compared only within their group.
+func (pt *PathTrie) GetAmbiguousPaths() []AmbiguousPaths {
+	var ret []AmbiguousPaths
+
+	groups := make(map[ambiguousPathsGroupKey][]splitValueNode)
+	for _, node := range append(pt.Trie.getValueNodes(), pt.ParamTrie.getValueNodes()...) {
+		segments := strings.Split(node.FullPath, pt.PathSeparator)
+		key := ambiguousPathsGroupKey{segmentsCount: len(segments), pathParamCounter: node.PathParamCounter}
+		groups[key] = append(groups[key], splitValueNode{node: node, segments: segments})
+	}
+
+	for _, nodes := range groups {
+		for i := range nodes {
+			for j := i + 1; j < len(nodes); j++ {
+				if !isSegmentsListOverlap(nodes[i].segments, nodes[j].segments) {
+					continue
+				}
+				preferred, other := nodes[i].node, nodes[j].node
+				if pt.isMoreSpecificNode(other, preferred) {
+					preferred, other = other, preferred
+				}
+				ret = append(ret, AmbiguousPaths{
+					PreferredPath: preferred.FullPath,
+					OtherPath:     other.FullPath,
+				})
+			}
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].PreferredPath != ret[j].PreferredPath {
+			return ret[i].PreferredPath < ret[j].PreferredPath
+		}
+		return ret[i].OtherPath < ret[j].OtherPath
+	})
+
+	return ret
+}
+
+func (trie PathToTrieNode) getValueNodes() []*TrieNode {
+	var nodes []*TrieNode
+
+	for _, node := range trie {
+		if node.Value != nil {
+			nodes = append(nodes, node)
+		}
+		nodes = append(nodes, node.Children.getValueNodes()...)
+		nodes = append(nodes, node.ParamChildren.getValueNodes()...)
+	}
+
+	return nodes
+}
+
+// isSegmentsListOverlap returns true if every segment pair (of the same length segments lists) overlaps.
+func isSegmentsListOverlap(segments, otherSegments []string) bool {
+	for i := range segments {
+		if !isSegmentsOverlap(segments[i], otherSegments[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isSegmentsOverlap returns true if there can be a segment that is matched by both segment templates.
+// For two partial segment templates the check is conservative, only the static prefix and suffix are compared.
+func isSegmentsOverlap(segment, other string) bool {
+	if utils.IsPathParam(segment) || utils.IsPathParam(other) {
+		return true
+	}
+
+	isPartial, isOtherPartial := utils.HasPathParam(segment), utils.HasPathParam(other)
+	switch {
+	case !isPartial && !isOtherPartial:
+		return segment == other
+	case isPartial && !isOtherPartial:
+		_, ok := utils.MatchPathSegmentTemplate(segment, other)
+		return ok
+	case !isPartial && isOtherPartial:
+		_, ok := utils.MatchPathSegmentTemplate(other, segment)
+		return ok
+	}
+
+	prefix, suffix := getStaticPrefixAndSuffix(segment)
+	otherPrefix, otherSuffix := getStaticPrefixAndSuffix(other)
+
+	return (strings.HasPrefix(prefix, otherPrefix) || strings.HasPrefix(otherPrefix, prefix)) &&
+		(strings.HasSuffix(suffix, otherSuffix) || strings.HasSuffix(otherSuffix, suffix))
+}
+
+func getStaticPrefixAndSuffix(segment string) (prefix, suffix string) {
+	tokens := utils.SplitPathSegmentTemplate(segment)
+	if first := tokens[0]; !utils.IsPathParam(first) {
+		prefix = first
+	}
+	if last := tokens[len(tokens)-1]; !utils.IsPathParam(last) {
+		suffix = last
+	}
+
+	return prefix, suffix
+}
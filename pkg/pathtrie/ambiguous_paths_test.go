@@ -0,0 +1,149 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathTrie_GetAmbiguousPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  []AmbiguousPaths
+	}{
+		{
+			name:  "no ambiguous paths",
+			paths: []string{"/a/{x}", "/a/b/c", "/a/b/{y}/d"},
+			want:  nil,
+		},
+		{
+			name:  "different path params count is not ambiguous",
+			paths: []string{"/a/b/{x}", "/a/{x}/{y}"},
+			want:  nil,
+		},
+		{
+			name:  "static segment earlier wins",
+			paths: []string{"/a/{x}/c", "/a/b/{y}"},
+			want: []AmbiguousPaths{
+				{
+					PreferredPath: "/a/b/{y}",
+					OtherPath:     "/a/{x}/c",
+				},
+			},
+		},
+		{
+			name:  "same template with different param names",
+			paths: []string{"/a/{y}", "/a/{x}"},
+			want: []AmbiguousPaths{
+				{
+					PreferredPath: "/a/{x}",
+					OtherPath:     "/a/{y}",
+				},
+			},
+		},
+		{
+			name:  "partial segment templates",
+			paths: []string{"/files/{name}.json", "/files/{name}", "/files/{name}.yaml"},
+			want: []AmbiguousPaths{
+				{
+					PreferredPath: "/files/{name}.json",
+					OtherPath:     "/files/{name}",
+				},
+				{
+					PreferredPath: "/files/{name}.yaml",
+					OtherPath:     "/files/{name}",
+				},
+			},
+		},
+		{
+			name:  "static segments do not overlap",
+			paths: []string{"/a/{x}/c", "/b/{y}/c"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := New()
+			for _, path := range tt.paths {
+				pt.Insert(path, path)
+			}
+			if got := pt.GetAmbiguousPaths(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAmbiguousPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isSegmentsOverlap(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment string
+		other   string
+		want    bool
+	}{
+		{
+			name:    "same static segments",
+			segment: "a",
+			other:   "a",
+			want:    true,
+		},
+		{
+			name:    "different static segments",
+			segment: "a",
+			other:   "b",
+			want:    false,
+		},
+		{
+			name:    "path param",
+			segment: "{x}",
+			other:   "report-{id}",
+			want:    true,
+		},
+		{
+			name:    "partial template matches static segment",
+			segment: "report-{id}",
+			other:   "report-1",
+			want:    true,
+		},
+		{
+			name:    "partial template does not match static segment",
+			segment: "report-{id}",
+			other:   "summary",
+			want:    false,
+		},
+		{
+			name:    "partial templates with different suffix",
+			segment: "{name}.json",
+			other:   "{name}.yaml",
+			want:    false,
+		},
+		{
+			name:    "partial templates with compatible prefix",
+			segment: "report-{id}",
+			other:   "report-{year}-{month}",
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSegmentsOverlap(tt.segment, tt.other); got != tt.want {
+				t.Errorf("isSegmentsOverlap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,14 +17,21 @@
 package pathtrie
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/openclarity/speculator/pkg/utils"
 )
 
 type TrieNode struct {
+	// Children holds the static path segments children, indexed by the segment name.
 	Children PathToTrieNode
 
+	// ParamChildren holds the path param segments children (whole or partial, e.g. {id} or report-{id}),
+	// indexed by the segment template. Kept apart from Children, so that a static segment can be looked up
+	// by its name and only the path param children need to be scanned. Nil until the first one is inserted.
+	ParamChildren PathToTrieNode
+
 	// Name of the path segment corresponding to this node.
 	// E.g. if this node represents /v1/foo/bar,
 	// the Name would be "bar" and the FullPath would be "/v1/foo/bar".
@@ -33,7 +40,7 @@ type TrieNode struct {
 	// FullPath includes the node's name and uniquely identifies the node in the tree.
 	FullPath string
 
-	// PathParamCounter counts the amount of path params in the FullPath
+	// PathParamCounter counts the amount of path params segments (whole or partial) in the FullPath
 	PathParamCounter int
 
 	// Value of the full path
@@ -43,7 +50,9 @@ type TrieNode struct {
 type PathToTrieNode map[string]*TrieNode
 
 type PathTrie struct {
+	// Trie holds the static root segments, ParamTrie holds the path param root segments.
 	Trie          PathToTrieNode
+	ParamTrie     PathToTrieNode
 	PathSeparator string
 }
 
@@ -65,7 +74,6 @@ func NewWithPathSeparator(pathSeparator string) PathTrie {
 // Insert val at path, with path segments separated by PathSeparator.
 // Returns true if a new path was created, false if an existing path
 // was overwritten.
-//
 func (pt *PathTrie) Insert(path string, val interface{}) bool {
 	return pt.InsertMerge(path, val, func(existing, newV *interface{}) {
 		*existing = *newV
@@ -79,7 +87,7 @@ func (pt *PathTrie) Insert(path string, val interface{}) bool {
 // The merge function is responsible for updating the existing value
 // with the new value.
 func (pt *PathTrie) InsertMerge(path string, val interface{}, merge ValueMergeFunc) (isNewPath bool) {
-	trie := pt.Trie
+	children, paramChildren := &pt.Trie, &pt.ParamTrie
 	isNewPath = true
 	// TODO: what about path that ends with pt.PathSeparator is it different ?
 	segments := strings.Split(path, pt.PathSeparator)
@@ -87,7 +95,11 @@ func (pt *PathTrie) InsertMerge(path string, val interface{}, merge ValueMergeFu
 	// Traverse the Trie along path, inserting nodes where necessary.
 	for idx, segment := range segments {
 		isLastSegment := idx == len(segments)-1
-		if node, ok := trie[segment]; ok {
+		trie := children
+		if utils.HasPathParam(segment) {
+			trie = paramChildren
+		}
+		if node, ok := (*trie)[segment]; ok {
 			if isLastSegment {
 				// If this is the last path segment, then this is the node to update.
 				// If node value is not empty it means that an existing path is overwritten
@@ -95,18 +107,47 @@ func (pt *PathTrie) InsertMerge(path string, val interface{}, merge ValueMergeFu
 				merge(&node.Value, &val)
 			} else {
 				// Otherwise, continue descending.
-				trie = node.Children
+				children, paramChildren = &node.Children, &node.ParamChildren
 			}
 		} else {
+			if *trie == nil {
+				*trie = make(PathToTrieNode)
+			}
 			newNode := pt.createPathTrieNode(segments, idx, isLastSegment, val)
-			trie[segment] = newNode
-			trie = newNode.Children
+			(*trie)[segment] = newNode
+			children, paramChildren = &newNode.Children, &newNode.ParamChildren
 		}
 	}
 
 	return isNewPath
 }
 
+// NormalizeParamChildren moves the path param nodes that are indexed as static children into the param children.
+// Tries that were encoded before path param nodes were kept apart have all the nodes under Children (and Trie),
+// so this should be called after decoding a trie.
+func (pt *PathTrie) NormalizeParamChildren() {
+	normalizeParamChildren(&pt.Trie, &pt.ParamTrie)
+}
+
+func normalizeParamChildren(children, paramChildren *PathToTrieNode) {
+	for segment, node := range *children {
+		if !utils.HasPathParam(segment) {
+			continue
+		}
+		if *paramChildren == nil {
+			*paramChildren = make(PathToTrieNode)
+		}
+		(*paramChildren)[segment] = node
+		delete(*children, segment)
+	}
+
+	for _, trie := range []PathToTrieNode{*children, *paramChildren} {
+		for _, node := range trie {
+			normalizeParamChildren(&node.Children, &node.ParamChildren)
+		}
+	}
+}
+
 func (pt *PathTrie) createPathTrieNode(segments []string, idx int, isLastSegment bool, val interface{}) *TrieNode {
 	fullPathSegments := segments[:idx+1]
 	node := &TrieNode{
@@ -126,7 +167,7 @@ func countPathParam(segments []string) int {
 	count := 0
 
 	for _, segment := range segments {
-		if utils.IsPathParam(segment) {
+		if utils.HasPathParam(segment) {
 			count += 1
 		}
 	}
@@ -154,10 +195,127 @@ func (pt *PathTrie) GetPathAndValue(path string) (string, interface{}, bool) {
 	return node.FullPath, node.Value, true
 }
 
+// MatchedPath is a path template that matches a given path.
+type MatchedPath struct {
+	// Path is the matching path template.
+	Path string
+	// Value of the matching path template.
+	Value interface{}
+}
+
+// MatchAll returns all the path templates matching the given path, ranked from the most accurate match
+// (the one returned by GetPathAndValue) to the least accurate one. Returns nil if no path template matches.
+func (pt *PathTrie) MatchAll(path string) []MatchedPath {
+	nodes := pt.getMatchNodes(strings.Split(path, pt.PathSeparator), false)
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return pt.isMoreAccurateNode(nodes[i], nodes[j], path)
+	})
+
+	ret := make([]MatchedPath, 0, len(nodes))
+	for _, node := range nodes {
+		ret = append(ret, MatchedPath{
+			Path:  node.FullPath,
+			Value: node.Value,
+		})
+	}
+
+	return ret
+}
+
+// Delete removes the value of the given path template, and prunes the nodes that are left empty.
+// The path is matched exactly (e.g. /api/{id} will not delete /api/1, and vice versa).
+// Returns true if the path was found and deleted.
+func (pt *PathTrie) Delete(path string) bool {
+	return deleteNode(pt.Trie, pt.ParamTrie, strings.Split(path, pt.PathSeparator), 0)
+}
+
+func deleteNode(children, paramChildren PathToTrieNode, segments []string, idx int) bool {
+	segment := segments[idx]
+	trie := children
+	if utils.HasPathParam(segment) {
+		trie = paramChildren
+	}
+
+	node, ok := trie[segment]
+	if !ok {
+		return false
+	}
+
+	if idx == len(segments)-1 {
+		if utils.IsNil(node.Value) {
+			return false
+		}
+		node.Value = nil
+	} else if !deleteNode(node.Children, node.ParamChildren, segments, idx+1) {
+		return false
+	}
+
+	// prune the node if it is not part of any other path
+	if utils.IsNil(node.Value) && len(node.Children) == 0 && len(node.ParamChildren) == 0 {
+		delete(trie, segment)
+	}
+
+	return true
+}
+
+// WalkFunc is called for every path in the trie. Returning false stops the walk.
+type WalkFunc func(path string, value interface{}) bool
+
+// Walk calls fn for every path in the trie that holds a value.
+// The order is deterministic: a path is visited before the longer paths under it (e.g. /api before /api/users),
+// and sibling segments are visited in lexicographic order.
+func (pt *PathTrie) Walk(fn WalkFunc) {
+	walkNodes(pt.Trie, pt.ParamTrie, fn)
+}
+
+func walkNodes(children, paramChildren PathToTrieNode, fn WalkFunc) bool {
+	for _, node := range sortedNodes(children, paramChildren) {
+		if node.Value != nil && !fn(node.FullPath, node.Value) {
+			return false
+		}
+		if !walkNodes(node.Children, node.ParamChildren, fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedNodes(children, paramChildren PathToTrieNode) []*TrieNode {
+	nodes := make([]*TrieNode, 0, len(children)+len(paramChildren))
+	for _, node := range children {
+		nodes = append(nodes, node)
+	}
+	for _, node := range paramChildren {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes
+}
+
+// Len returns the number of paths in the trie that hold a value.
+func (pt *PathTrie) Len() int {
+	count := 0
+	pt.Walk(func(_ string, _ interface{}) bool {
+		count++
+		return true
+	})
+
+	return count
+}
+
 func (pt *PathTrie) getNode(path string) *TrieNode {
 	segments := strings.Split(path, pt.PathSeparator)
 
-	nodes := pt.Trie.getMatchNodes(segments, 0)
+	nodes := pt.getMatchNodes(segments, true)
 
 	if len(nodes) == 0 {
 		return nil
@@ -168,53 +326,72 @@ func (pt *PathTrie) getNode(path string) *TrieNode {
 	}
 
 	// if multiple nodes found, return the node with less path params segments
-	return getMostAccurateNode(nodes, path, len(segments))
+	return pt.getMostAccurateNode(nodes, path)
 }
 
-func (trie PathToTrieNode) getMatchNodes(segments []string, idx int) []*TrieNode {
-	var nodes []*TrieNode
+// getMatchNodes returns the nodes holding a value that match the given path segments.
+// When prune is set, path param branches that can't lead to a node with less (or equal) path params segments
+// than an already matched node are skipped, since such nodes will never be the most accurate node.
+func (pt *PathTrie) getMatchNodes(segments []string, prune bool) []*TrieNode {
+	matcher := &nodesMatcher{
+		segments:            segments,
+		prune:               prune,
+		minPathParamCounter: len(segments) + 1,
+	}
+	matcher.match(pt.Trie, pt.ParamTrie, 0)
 
-	isLastSegment := idx == len(segments)-1
+	return matcher.nodes
+}
 
-	for _, node := range trie {
-		// Check for node segment match
-		if !node.isNameMatch(segments[idx]) {
+type nodesMatcher struct {
+	segments []string
+	prune    bool
+	// minPathParamCounter is the minimal amount of path params segments of the nodes matched so far.
+	minPathParamCounter int
+	nodes               []*TrieNode
+}
+
+func (m *nodesMatcher) match(children, paramChildren PathToTrieNode, idx int) {
+	// static segments are matched by their name, and are checked first so that the pruning will kick in early
+	if node, ok := children[m.segments[idx]]; ok {
+		m.matchNode(node, idx)
+	}
+
+	for _, node := range paramChildren {
+		// PathParamCounter can only grow while descending
+		if m.prune && node.PathParamCounter > m.minPathParamCounter {
 			continue
 		}
-
-		// If this is the last path segment, then return node if it holds a value.
-		if isLastSegment {
-			if node.Value != nil {
-				nodes = append(nodes, node)
-			}
+		// Check for node segment match
+		if !node.isNameMatch(m.segments[idx]) {
 			continue
 		}
+		m.matchNode(node, idx)
+	}
+}
 
-		// Otherwise, continue descending.
-		newNodes := node.Children.getMatchNodes(segments, idx+1)
-		if len(newNodes) > 0 {
-			nodes = append(nodes, newNodes...)
+func (m *nodesMatcher) matchNode(node *TrieNode, idx int) {
+	// If this is the last path segment, then add node if it holds a value.
+	if idx == len(m.segments)-1 {
+		if node.Value != nil {
+			m.nodes = append(m.nodes, node)
+			if node.PathParamCounter < m.minPathParamCounter {
+				m.minPathParamCounter = node.PathParamCounter
+			}
 		}
+		return
 	}
 
-	return nodes
+	// Otherwise, continue descending.
+	m.match(node.Children, node.ParamChildren, idx+1)
 }
 
-// getMostAccurateNode returns the node with less path params segments.
-func getMostAccurateNode(nodes []*TrieNode, path string, segmentsLen int) *TrieNode {
+// getMostAccurateNode returns the most accurate node, see isMoreAccurateNode.
+func (pt *PathTrie) getMostAccurateNode(nodes []*TrieNode, path string) *TrieNode {
 	var retNode *TrieNode
-	minPathParamSegmentsCount := segmentsLen + 1
 
 	for _, node := range nodes {
-		if node.isFullPathMatch(path) {
-			// return exact match
-			return node
-		}
-
-		// TODO: if node.PathParamCounter == minPathParamSegmentsCount
-		if node.PathParamCounter < minPathParamSegmentsCount {
-			// found more accurate node
-			minPathParamSegmentsCount = node.PathParamCounter
+		if retNode == nil || pt.isMoreAccurateNode(node, retNode, path) {
 			retNode = node
 		}
 	}
@@ -222,6 +399,68 @@ func getMostAccurateNode(nodes []*TrieNode, path string, segmentsLen int) *TrieN
 	return retNode
 }
 
+// isMoreAccurateNode returns true if node is a more accurate match for path than other.
+// An exact match is the most accurate, then the node with less path params segments.
+// If both nodes have the same amount of path params segments, the node with a more specific segment
+// earlier in the path is more accurate (e.g. for /a/b/c, /a/b/{y} is preferred over /a/{x}/c),
+// and as a final tiebreak the node with the lexicographically smaller full path.
+func (pt *PathTrie) isMoreAccurateNode(node, other *TrieNode, path string) bool {
+	if node.isFullPathMatch(path) != other.isFullPathMatch(path) {
+		return node.isFullPathMatch(path)
+	}
+
+	if node.PathParamCounter != other.PathParamCounter {
+		return node.PathParamCounter < other.PathParamCounter
+	}
+
+	return pt.isMoreSpecificNode(node, other)
+}
+
+// isMoreSpecificNode returns true if node is more specific than other, comparing the segments from left to right.
+// A static segment is more specific than a partial segment template (e.g. report-{id}),
+// which is more specific than a path param.
+func (pt *PathTrie) isMoreSpecificNode(node, other *TrieNode) bool {
+	if cmp := pt.compareSpecificity(node.FullPath, other.FullPath); cmp != 0 {
+		return cmp < 0
+	}
+
+	return node.FullPath < other.FullPath
+}
+
+// compareSpecificity returns a negative number if path is more specific than otherPath, a positive number
+// if otherPath is more specific, and 0 if the paths have the same specificity.
+func (pt *PathTrie) compareSpecificity(path, otherPath string) int {
+	segments := strings.Split(path, pt.PathSeparator)
+	otherSegments := strings.Split(otherPath, pt.PathSeparator)
+
+	for i := 0; i < len(segments) && i < len(otherSegments); i++ {
+		if diff := segmentSpecificity(segments[i]) - segmentSpecificity(otherSegments[i]); diff != 0 {
+			return diff
+		}
+	}
+
+	return 0
+}
+
+const (
+	staticSegmentSpecificity = iota
+	partialPathParamSpecificity
+	pathParamSpecificity
+)
+
+// segmentSpecificity returns the segment specificity rank, the lower the rank, the more specific the segment is.
+func segmentSpecificity(segment string) int {
+	if utils.IsPathParam(segment) {
+		return pathParamSpecificity
+	}
+
+	if utils.HasPathParam(segment) {
+		return partialPathParamSpecificity
+	}
+
+	return staticSegmentSpecificity
+}
+
 func (node *TrieNode) isNameMatch(segment string) bool {
 	if utils.IsPathParam(node.Name) {
 		return true
@@ -231,6 +470,12 @@ func (node *TrieNode) isNameMatch(segment string) bool {
 		return true
 	}
 
+	// partial segment template, e.g. {name}.{ext} or report-{id}
+	if utils.HasPathParam(node.Name) {
+		_, ok := utils.MatchPathSegmentTemplate(node.Name, segment)
+		return ok
+	}
+
 	return false
 }
 
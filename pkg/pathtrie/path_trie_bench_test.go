@@ -0,0 +1,104 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathtrie
+
+import (
+	"fmt"
+	"testing"
+)
+
+var benchmarkTrieSizes = []int{100, 1000, 10000}
+
+// createBenchmarkPathTrie creates a trie with resourcesCount resources, each one with the same paths layout as
+// TestPathTrie_getNode, so that every lookup has both static and path param candidates to choose from.
+func createBenchmarkPathTrie(resourcesCount int) PathTrie {
+	pt := New()
+	for i := 0; i < resourcesCount; i++ {
+		pt.Insert(fmt.Sprintf("/api%d/{param1}/items", i), i)
+		pt.Insert(fmt.Sprintf("/api%d/items", i), i)
+		pt.Insert(fmt.Sprintf("/api%d/{param1}/{param2}", i), i)
+		pt.Insert(fmt.Sprintf("/api%d/{param1}/cat", i), i)
+		pt.Insert(fmt.Sprintf("/api%d/items/cat", i), i)
+		pt.Insert(fmt.Sprintf("/api%d/reports/report-{id}", i), i)
+		pt.Insert(fmt.Sprintf("/api%d/files/{name}.{ext}", i), i)
+	}
+
+	return pt
+}
+
+func BenchmarkPathTrie_Insert(b *testing.B) {
+	for _, size := range benchmarkTrieSizes {
+		b.Run(fmt.Sprintf("resources=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				createBenchmarkPathTrie(size)
+			}
+		})
+	}
+}
+
+func BenchmarkPathTrie_GetPathAndValue(b *testing.B) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "most accurate match",
+			path: "/api%d/1/items",
+		},
+		{
+			name: "exact match with path param",
+			path: "/api%d/{param1}/items",
+		},
+		{
+			name: "short match",
+			path: "/api%d/items",
+		},
+		{
+			name: "simple path param match",
+			path: "/api%d/1/2",
+		},
+		{
+			name: "exact match with no path param",
+			path: "/api%d/items/cat",
+		},
+		{
+			name: "partial path param match",
+			path: "/api%d/reports/report-1",
+		},
+		{
+			name: "multiple partial path params match",
+			path: "/api%d/files/report.json",
+		},
+		{
+			name: "no match",
+			path: "/no-api%d/items/cat",
+		},
+	}
+	for _, size := range benchmarkTrieSizes {
+		pt := createBenchmarkPathTrie(size)
+		for _, tt := range tests {
+			// look up the last resource, to avoid depending on the insertion order
+			path := fmt.Sprintf(tt.path, size-1)
+			b.Run(fmt.Sprintf("%s/resources=%d", tt.name, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					pt.GetPathAndValue(path)
+				}
+			})
+		}
+	}
+}
@@ -16,6 +16,8 @@
 package pathtrie
 
 import (
+	"bytes"
+	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -226,22 +228,86 @@ func TestPathTrie_GetPathAndValue(t *testing.T) {
 	}
 }
 
+func TestPathTrie_GetPathAndValue_PartialPathParams(t *testing.T) {
+	pt := New()
+	assert.Equal(t, pt.Insert("/files/{name}.{ext}", 1), true)
+	assert.Equal(t, pt.Insert("/reports/report-{id}", 2), true)
+	assert.Equal(t, pt.Insert("/summaries/{name}", 3), true)
+	assert.Equal(t, pt.Insert("/reports/{reportName}", 4), true)
+	tests := []struct {
+		name      string
+		path      string
+		wantPath  string
+		wantValue interface{}
+		wantFound bool
+	}{
+		{
+			name:      "embedded params",
+			path:      "/files/image.png",
+			wantPath:  "/files/{name}.{ext}",
+			wantValue: 1,
+			wantFound: true,
+		},
+		{
+			name:      "embedded params no match",
+			path:      "/files/image",
+			wantFound: false,
+		},
+		{
+			name:      "prefixed param",
+			path:      "/reports/report-1",
+			wantPath:  "/reports/report-{id}",
+			wantValue: 2,
+			wantFound: true,
+		},
+		{
+			name:      "path param when partial template does not match",
+			path:      "/reports/summary",
+			wantPath:  "/reports/{reportName}",
+			wantValue: 4,
+			wantFound: true,
+		},
+		{
+			name:      "whole segment param",
+			path:      "/summaries/daily",
+			wantPath:  "/summaries/{name}",
+			wantValue: 3,
+			wantFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotValue, gotFound := pt.GetPathAndValue(tt.path)
+			if gotPath != tt.wantPath {
+				t.Errorf("GetPathAndValue() gotPath = %v, want %v", gotPath, tt.wantPath)
+			}
+			if !reflect.DeepEqual(gotValue, tt.wantValue) {
+				t.Errorf("GetPathAndValue() gotValue = %v, want %v", gotValue, tt.wantValue)
+			}
+			if gotFound != tt.wantFound {
+				t.Errorf("GetPathAndValue() gotFound = %v, want %v", gotFound, tt.wantFound)
+			}
+		})
+	}
+}
+
 func TestPathTrieMap_getMatchNodes(t *testing.T) {
 	type args struct {
 		segments []string
-		idx      int
 	}
 	tests := []struct {
-		name string
-		trie PathToTrieNode
-		args args
-		want []*TrieNode
+		name  string
+		trie  PathToTrieNode
+		prune bool
+		args  args
+		want  []*TrieNode
 	}{
 		{
 			name: "return 2 matches nodes",
 			trie: map[string]*TrieNode{
 				"api": {
-					Children: map[string]*TrieNode{
+					Children: make(PathToTrieNode),
+					ParamChildren: map[string]*TrieNode{
 						"{param1}": {
 							Children: map[string]*TrieNode{
 								"test": {
@@ -251,6 +317,8 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 									PathParamCounter: 1,
 									Value:            1,
 								},
+							},
+							ParamChildren: map[string]*TrieNode{
 								"{param2}": {
 									Children:         make(PathToTrieNode),
 									Name:             "{param2}",
@@ -270,7 +338,6 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 			},
 			args: args{
 				segments: []string{"api", "123", "test"},
-				idx:      0,
 			},
 			want: []*TrieNode{
 				{
@@ -289,11 +356,60 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "prune - return only the match with less path params (/api/{param1}/test)",
+			trie: map[string]*TrieNode{
+				"api": {
+					Children: make(PathToTrieNode),
+					ParamChildren: map[string]*TrieNode{
+						"{param1}": {
+							Children: map[string]*TrieNode{
+								"test": {
+									Children:         make(PathToTrieNode),
+									Name:             "test",
+									FullPath:         "/api/{param1}/test",
+									PathParamCounter: 1,
+									Value:            1,
+								},
+							},
+							ParamChildren: map[string]*TrieNode{
+								"{param2}": {
+									Children:         make(PathToTrieNode),
+									Name:             "{param2}",
+									FullPath:         "/api/{param1}/{param2}",
+									PathParamCounter: 2,
+									Value:            2,
+								},
+							},
+							Name:             "{param1}",
+							FullPath:         "/api/{param1}",
+							PathParamCounter: 1,
+						},
+					},
+					Name:     "api",
+					FullPath: "/api",
+				},
+			},
+			prune: true,
+			args: args{
+				segments: []string{"api", "123", "test"},
+			},
+			want: []*TrieNode{
+				{
+					Children:         make(PathToTrieNode),
+					Name:             "test",
+					FullPath:         "/api/{param1}/test",
+					PathParamCounter: 1,
+					Value:            1,
+				},
+			},
+		},
 		{
 			name: "last path segment has nil value - return only 1 matches nodes (/api/{param1}/{param2})",
 			trie: map[string]*TrieNode{
 				"api": {
-					Children: map[string]*TrieNode{
+					Children: make(PathToTrieNode),
+					ParamChildren: map[string]*TrieNode{
 						"{param1}": {
 							Children: map[string]*TrieNode{
 								"test": {
@@ -311,6 +427,8 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 									PathParamCounter: 1,
 									Value:            nil,
 								},
+							},
+							ParamChildren: map[string]*TrieNode{
 								"{param2}": {
 									Children:         make(PathToTrieNode),
 									Name:             "{param2}",
@@ -330,7 +448,6 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 			},
 			args: args{
 				segments: []string{"api", "123", "test"},
-				idx:      0,
 			},
 			want: []*TrieNode{
 				{
@@ -346,7 +463,8 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 			name: "0 nodes match",
 			trie: map[string]*TrieNode{
 				"api": {
-					Children: map[string]*TrieNode{
+					Children: make(PathToTrieNode),
+					ParamChildren: map[string]*TrieNode{
 						"{param1}": {
 							Children: map[string]*TrieNode{
 								"test": {
@@ -356,6 +474,8 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 									PathParamCounter: 1,
 									Value:            1,
 								},
+							},
+							ParamChildren: map[string]*TrieNode{
 								"{param2}": {
 									Children:         make(PathToTrieNode),
 									Name:             "{param2}",
@@ -376,14 +496,17 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 			},
 			args: args{
 				segments: []string{"api", "cats", "dogs", "test"},
-				idx:      0,
 			},
 			want: nil,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.trie.getMatchNodes(tt.args.segments, tt.args.idx)
+			pt := &PathTrie{
+				Trie:          tt.trie,
+				PathSeparator: "/",
+			}
+			got := pt.getMatchNodes(tt.args.segments, tt.prune)
 			sort.Slice(got, func(i, j int) bool {
 				return got[i].FullPath < got[j].FullPath
 			})
@@ -400,9 +523,8 @@ func TestPathTrieMap_getMatchNodes(t *testing.T) {
 func Test_getMostAccurateNode(t *testing.T) {
 	pt := New()
 	type args struct {
-		nodes       []*TrieNode
-		path        string
-		segmentsLen int
+		nodes []*TrieNode
+		path  string
 	}
 	tests := []struct {
 		name string
@@ -416,8 +538,7 @@ func Test_getMostAccurateNode(t *testing.T) {
 					pt.createPathTrieNode([]string{"", "api", "{param1}", "test"}, 3, true, 1),
 					pt.createPathTrieNode([]string{"", "api", "{param1}", "{param2}"}, 3, true, 2),
 				},
-				path:        "/api/{param1}/test",
-				segmentsLen: 4,
+				path: "/api/{param1}/test",
 			},
 			want: pt.createPathTrieNode([]string{"", "api", "{param1}", "test"}, 3, true, 1),
 		},
@@ -428,26 +549,68 @@ func Test_getMostAccurateNode(t *testing.T) {
 					pt.createPathTrieNode([]string{"", "api", "{param1}", "test", "{param2}"}, 4, true, 1),
 					pt.createPathTrieNode([]string{"", "api", "{param1}", "{param2}", "{param3}"}, 4, true, 2),
 				},
-				path:        "/api/cats/test/dogs",
-				segmentsLen: 5,
+				path: "/api/cats/test/dogs",
 			},
 			want: pt.createPathTrieNode([]string{"", "api", "{param1}", "test", "{param2}"}, 4, true, 1),
 		},
+		{
+			name: "same path params count - static segment earlier in the path wins",
+			args: args{
+				nodes: []*TrieNode{
+					pt.createPathTrieNode([]string{"", "a", "{x}", "c"}, 3, true, 1),
+					pt.createPathTrieNode([]string{"", "a", "b", "{y}"}, 3, true, 2),
+				},
+				path: "/a/b/c",
+			},
+			want: pt.createPathTrieNode([]string{"", "a", "b", "{y}"}, 3, true, 2),
+		},
+		{
+			name: "same path params count - static segment earlier in the path wins (reversed order)",
+			args: args{
+				nodes: []*TrieNode{
+					pt.createPathTrieNode([]string{"", "a", "b", "{y}"}, 3, true, 2),
+					pt.createPathTrieNode([]string{"", "a", "{x}", "c"}, 3, true, 1),
+				},
+				path: "/a/b/c",
+			},
+			want: pt.createPathTrieNode([]string{"", "a", "b", "{y}"}, 3, true, 2),
+		},
+		{
+			name: "same path params count - partial segment template wins over path param",
+			args: args{
+				nodes: []*TrieNode{
+					pt.createPathTrieNode([]string{"", "reports", "{name}"}, 2, true, 1),
+					pt.createPathTrieNode([]string{"", "reports", "report-{id}"}, 2, true, 2),
+				},
+				path: "/reports/report-1",
+			},
+			want: pt.createPathTrieNode([]string{"", "reports", "report-{id}"}, 2, true, 2),
+		},
+		{
+			name: "same specificity - lexicographic order",
+			args: args{
+				nodes: []*TrieNode{
+					pt.createPathTrieNode([]string{"", "a", "{y}"}, 2, true, 2),
+					pt.createPathTrieNode([]string{"", "a", "{x}"}, 2, true, 1),
+				},
+				path: "/a/b",
+			},
+			want: pt.createPathTrieNode([]string{"", "a", "{x}"}, 2, true, 1),
+		},
 		{
 			name: "single match",
 			args: args{
 				nodes: []*TrieNode{
 					pt.createPathTrieNode([]string{"", "api", "{param1}", "test"}, 3, true, 1),
 				},
-				path:        "/api/cats/test",
-				segmentsLen: 4,
+				path: "/api/cats/test",
 			},
 			want: pt.createPathTrieNode([]string{"", "api", "{param1}", "test"}, 3, true, 1),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := getMostAccurateNode(tt.args.nodes, tt.args.path, tt.args.segmentsLen); !reflect.DeepEqual(got, tt.want) {
+			if got := pt.getMostAccurateNode(tt.args.nodes, tt.args.path); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("getMostAccurateNode() = %v, want %v", got, tt.want)
 			}
 		})
@@ -497,6 +660,46 @@ func TestPathTrieNode_isNameMatch(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "partial path param match",
+			fields: fields{
+				Name: "{name}.{ext}",
+			},
+			args: args{
+				segment: "file.json",
+			},
+			want: true,
+		},
+		{
+			name: "partial path param prefix match",
+			fields: fields{
+				Name: "report-{id}",
+			},
+			args: args{
+				segment: "report-1",
+			},
+			want: true,
+		},
+		{
+			name: "partial path param not match",
+			fields: fields{
+				Name: "report-{id}",
+			},
+			args: args{
+				segment: "summary-1",
+			},
+			want: false,
+		},
+		{
+			name: "partial path param empty value not match",
+			fields: fields{
+				Name: "report-{id}",
+			},
+			args: args{
+				segment: "report-",
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -586,6 +789,13 @@ func Test_countPathParam(t *testing.T) {
 			},
 			want: 2,
 		},
+		{
+			name: "partial path params",
+			args: args{
+				segments: []string{"", "api", "report-{id}", "{name}.{ext}"},
+			},
+			want: 2,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -852,7 +1062,8 @@ func TestPathTrie_InsertMerge(t *testing.T) {
 				"": &TrieNode{
 					Children: map[string]*TrieNode{
 						"api": {
-							Children: map[string]*TrieNode{
+							Children: make(PathToTrieNode),
+							ParamChildren: map[string]*TrieNode{
 								"{param}": {
 									Children:         make(PathToTrieNode),
 									Name:             "{param}",
@@ -882,11 +1093,13 @@ func TestPathTrie_InsertMerge(t *testing.T) {
 					"": &TrieNode{
 						Children: map[string]*TrieNode{
 							"carts": {
-								Children: map[string]*TrieNode{
+								Children: make(PathToTrieNode),
+								ParamChildren: map[string]*TrieNode{
 									"{customerID}": {
 										Children: map[string]*TrieNode{
 											"items": {
-												Children: map[string]*TrieNode{
+												Children: make(PathToTrieNode),
+												ParamChildren: map[string]*TrieNode{
 													"{itemID}": {
 														Children:         make(PathToTrieNode),
 														Name:             "{itemID}",
@@ -928,11 +1141,13 @@ func TestPathTrie_InsertMerge(t *testing.T) {
 				"": &TrieNode{
 					Children: map[string]*TrieNode{
 						"carts": {
-							Children: map[string]*TrieNode{
+							Children: make(PathToTrieNode),
+							ParamChildren: map[string]*TrieNode{
 								"{customerID}": {
 									Children: map[string]*TrieNode{
 										"items": {
-											Children: map[string]*TrieNode{
+											Children: make(PathToTrieNode),
+											ParamChildren: map[string]*TrieNode{
 												"{itemID}": {
 													Children:         map[string]*TrieNode{},
 													Name:             "{itemID}",
@@ -985,3 +1200,315 @@ func marshal(obj interface{}) string {
 	objB, _ := json.Marshal(obj)
 	return string(objB)
 }
+
+func TestPathTrie_MatchAll(t *testing.T) {
+	pt := New()
+	pt.Insert("/api/{param1}/items", 1)
+	pt.Insert("/api/items", 2)
+	pt.Insert("/api/{param1}/{param2}", 3)
+	pt.Insert("/api/items/{param2}", 4)
+	pt.Insert("/api/items/cat", 5)
+	pt.Insert("/api/{param1}/report-{id}", 6)
+
+	tests := []struct {
+		name string
+		path string
+		want []MatchedPath
+	}{
+		{
+			name: "ranked by path params segments and specificity",
+			path: "/api/items/items",
+			want: []MatchedPath{
+				{Path: "/api/items/{param2}", Value: 4},
+				{Path: "/api/{param1}/items", Value: 1},
+				{Path: "/api/{param1}/{param2}", Value: 3},
+			},
+		},
+		{
+			name: "partial path param is ranked before path param",
+			path: "/api/1/report-2",
+			want: []MatchedPath{
+				{Path: "/api/{param1}/report-{id}", Value: 6},
+				{Path: "/api/{param1}/{param2}", Value: 3},
+			},
+		},
+		{
+			name: "exact match is ranked first",
+			path: "/api/{param1}/{param2}",
+			want: []MatchedPath{
+				{Path: "/api/{param1}/{param2}", Value: 3},
+			},
+		},
+		{
+			name: "static match",
+			path: "/api/items/cat",
+			want: []MatchedPath{
+				{Path: "/api/items/cat", Value: 5},
+				{Path: "/api/items/{param2}", Value: 4},
+				{Path: "/api/{param1}/{param2}", Value: 3},
+			},
+		},
+		{
+			name: "no match",
+			path: "/api/items/cat/dog",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pt.MatchAll(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchAll() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathTrie_Delete(t *testing.T) {
+	tests := []struct {
+		name          string
+		paths         []string
+		path          string
+		want          bool
+		expectedPaths []string
+		expectedTrie  PathToTrieNode
+	}{
+		{
+			name:          "delete leaf - prune empty nodes",
+			paths:         []string{"/api/items", "/api/{param1}/cats/{param2}"},
+			path:          "/api/{param1}/cats/{param2}",
+			want:          true,
+			expectedPaths: []string{"/api/items"},
+			expectedTrie: PathToTrieNode{
+				"": &TrieNode{
+					Children: map[string]*TrieNode{
+						"api": {
+							Children: map[string]*TrieNode{
+								"items": {
+									Children:         make(PathToTrieNode),
+									Name:             "items",
+									FullPath:         "/api/items",
+									PathParamCounter: 0,
+									Value:            1,
+								},
+							},
+							ParamChildren: make(PathToTrieNode),
+							Name:          "api",
+							FullPath:      "/api",
+						},
+					},
+					Name:     "",
+					FullPath: "",
+				},
+			},
+		},
+		{
+			name:          "delete node with children - keep node",
+			paths:         []string{"/api/items", "/api/items/cat"},
+			path:          "/api/items",
+			want:          true,
+			expectedPaths: []string{"/api/items/cat"},
+			expectedTrie: PathToTrieNode{
+				"": &TrieNode{
+					Children: map[string]*TrieNode{
+						"api": {
+							Children: map[string]*TrieNode{
+								"items": {
+									Children: map[string]*TrieNode{
+										"cat": {
+											Children:         make(PathToTrieNode),
+											Name:             "cat",
+											FullPath:         "/api/items/cat",
+											PathParamCounter: 0,
+											Value:            2,
+										},
+									},
+									Name:     "items",
+									FullPath: "/api/items",
+								},
+							},
+							Name:     "api",
+							FullPath: "/api",
+						},
+					},
+					Name:     "",
+					FullPath: "",
+				},
+			},
+		},
+		{
+			name:          "delete last path - empty trie",
+			paths:         []string{"/api/{param1}"},
+			path:          "/api/{param1}",
+			want:          true,
+			expectedPaths: nil,
+			expectedTrie:  PathToTrieNode{},
+		},
+		{
+			name:          "path template is matched exactly",
+			paths:         []string{"/api/{param1}"},
+			path:          "/api/1",
+			want:          false,
+			expectedPaths: []string{"/api/{param1}"},
+		},
+		{
+			name:          "path without value",
+			paths:         []string{"/api/items/cat"},
+			path:          "/api/items",
+			want:          false,
+			expectedPaths: []string{"/api/items/cat"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := New()
+			for i, path := range tt.paths {
+				pt.Insert(path, i+1)
+			}
+			if got := pt.Delete(tt.path); got != tt.want {
+				t.Errorf("Delete() = %v, want %v", got, tt.want)
+			}
+			var gotPaths []string
+			pt.Walk(func(path string, _ interface{}) bool {
+				gotPaths = append(gotPaths, path)
+				return true
+			})
+			if !reflect.DeepEqual(gotPaths, tt.expectedPaths) {
+				t.Errorf("Delete() paths = %v, want %v", gotPaths, tt.expectedPaths)
+			}
+			if tt.expectedTrie != nil && !reflect.DeepEqual(pt.Trie, tt.expectedTrie) {
+				t.Errorf("Delete() Trie = %+v, want %+v", marshal(pt.Trie), marshal(tt.expectedTrie))
+			}
+		})
+	}
+}
+
+func TestPathTrie_Walk(t *testing.T) {
+	pt := New()
+	pt.Insert("/api/{param1}/items", 1)
+	pt.Insert("/api/items", 2)
+	pt.Insert("/api/{param1}/{param2}", 3)
+	pt.Insert("/api/cats", 4)
+	pt.Insert("/api/items/cat", 5)
+
+	tests := []struct {
+		name      string
+		stopAfter int
+		want      []string
+	}{
+		{
+			name: "walk all paths",
+			want: []string{
+				"/api/cats",
+				"/api/items",
+				"/api/items/cat",
+				"/api/{param1}/items",
+				"/api/{param1}/{param2}",
+			},
+		},
+		{
+			name:      "stop walk",
+			stopAfter: 2,
+			want: []string{
+				"/api/cats",
+				"/api/items",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			pt.Walk(func(path string, _ interface{}) bool {
+				got = append(got, path)
+				return len(got) != tt.stopAfter
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Walk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathTrie_Len(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  int
+	}{
+		{
+			name: "empty trie",
+			want: 0,
+		},
+		{
+			name:  "nodes without value are not counted",
+			paths: []string{"/api/items/cat", "/api/{param1}/{param2}"},
+			want:  2,
+		},
+		{
+			name:  "existing path is counted once",
+			paths: []string{"/api/items", "/api/items", "/api/items/{param1}"},
+			want:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := New()
+			for _, path := range tt.paths {
+				pt.Insert(path, path)
+			}
+			if got := pt.Len(); got != tt.want {
+				t.Errorf("Len() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathTrie_NormalizeParamChildren(t *testing.T) {
+	// a trie encoded before the path param nodes were kept apart from the static nodes
+	oldLayout := PathTrie{
+		Trie: PathToTrieNode{
+			"": &TrieNode{
+				Children: PathToTrieNode{
+					"api": &TrieNode{
+						Children: PathToTrieNode{
+							"{id}": &TrieNode{
+								Children:         PathToTrieNode{},
+								Name:             "{id}",
+								FullPath:         "/api/{id}",
+								PathParamCounter: 1,
+								Value:            "1",
+							},
+							"items": &TrieNode{
+								Children: PathToTrieNode{},
+								Name:     "items",
+								FullPath: "/api/items",
+								Value:    "2",
+							},
+						},
+						Name:     "api",
+						FullPath: "/api",
+					},
+				},
+				Name:     "",
+				FullPath: "",
+			},
+		},
+		PathSeparator: "/",
+	}
+
+	var buf bytes.Buffer
+	assert.NilError(t, gob.NewEncoder(&buf).Encode(oldLayout))
+	pt := PathTrie{}
+	assert.NilError(t, gob.NewDecoder(&buf).Decode(&pt))
+
+	pt.NormalizeParamChildren()
+
+	path, value, found := pt.GetPathAndValue("/api/123")
+	assert.Assert(t, found)
+	assert.Equal(t, path, "/api/{id}")
+	assert.Equal(t, value, "1")
+	path, value, found = pt.GetPathAndValue("/api/items")
+	assert.Assert(t, found)
+	assert.Equal(t, path, "/api/items")
+	assert.Equal(t, value, "2")
+	assert.Equal(t, pt.Len(), 2)
+}
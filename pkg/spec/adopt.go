@@ -0,0 +1,119 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+// AdoptProvidedSpec seeds the approved spec with the provided spec paths (including the base path) and security
+// schemes, so the reconstructed spec evolves from the provided spec by the learning and review flow.
+// An approved path that matches a provided path template (ignoring param names) is renamed to the provided path,
+// and its operations that are missing from the provided path are kept.
+// The adopted paths keep their provided spec path IDs.
+func (s *Spec) AdoptProvidedSpec() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.HasProvidedSpec() {
+		return ErrNoProvidedSpec
+	}
+
+	// first update a copy of the state, in case the validation will fail
+	clonedSpec, err := s.SpecInfoClone()
+	if err != nil {
+		return fmt.Errorf("failed to clone spec. %v", err)
+	}
+	if clonedSpec.ApprovedSpec == nil {
+		clonedSpec.ApprovedSpec = &ApprovedSpec{}
+	}
+	if clonedSpec.ApprovedSpec.PathItems == nil {
+		clonedSpec.ApprovedSpec.PathItems = map[string]*oapi_spec.PathItem{}
+	}
+	if clonedSpec.ApprovedSpec.SecuritySchemes == nil {
+		clonedSpec.ApprovedSpec.SecuritySchemes = oapi_spec.SecuritySchemes{}
+	}
+	if clonedSpec.AdoptedPaths == nil {
+		clonedSpec.AdoptedPaths = map[string]bool{}
+	}
+
+	doc, err := cloneDoc(s.ProvidedSpec.Doc)
+	if err != nil {
+		return fmt.Errorf("failed to clone provided spec: %w", err)
+	}
+
+	approvedPathsByKey := make(map[string]string, len(clonedSpec.ApprovedSpec.PathItems))
+	for path := range clonedSpec.ApprovedSpec.PathItems {
+		approvedPathsByKey[utils.ClearPathParamNames(path)] = path
+	}
+
+	basePath := s.ProvidedSpec.GetBasePath()
+	for _, path := range sortedPaths(doc.Paths) {
+		fullPath := addBasePathIfNeeded(basePath, path)
+		pathItem := doc.Paths[path]
+		if approvedPath, ok := approvedPathsByKey[utils.ClearPathParamNames(fullPath)]; ok {
+			log.Debugf("Merging approved path %v into provided path %v", approvedPath, fullPath)
+			mergeApprovedOperations(pathItem, clonedSpec.ApprovedSpec.PathItems[approvedPath], getPathParamNamesMapping(approvedPath, fullPath))
+			delete(clonedSpec.ApprovedSpec.PathItems, approvedPath)
+			delete(clonedSpec.AdoptedPaths, approvedPath)
+			clonedSpec.ApprovedPathTrie.Delete(approvedPath)
+		}
+
+		pathID := s.GeneratePathID(fullPath)
+		if _, value, found := s.ProvidedPathTrie.GetPathAndValue(path); found {
+			if providedPathID, ok := value.(string); ok {
+				pathID = providedPathID
+			}
+		}
+
+		clonedSpec.ApprovedSpec.PathItems[fullPath] = pathItem
+		clonedSpec.AdoptedPaths[fullPath] = true
+		clonedSpec.ApprovedPathTrie.Insert(fullPath, pathID)
+	}
+
+	for name, securityScheme := range doc.Components.SecuritySchemes {
+		clonedSpec.ApprovedSpec.SecuritySchemes[name] = securityScheme
+	}
+
+	setOperationsInfo(clonedSpec.ApprovedSpec.PathItems, clonedSpec.OperationOverrides)
+
+	version := s.ProvidedSpec.GetSpecVersion()
+	if _, err := clonedSpec.GenerateOASJson(version); err != nil {
+		return fmt.Errorf("failed to generate Open API Spec. %w", err)
+	}
+	clonedSpec.ApprovedSpec.SpecVersion = version
+
+	s.SpecInfo = clonedSpec.SpecInfo
+	log.Debugf("Adopted provided spec with version %q as the approved spec for %s:%s", version, s.Host, s.Port)
+
+	return nil
+}
+
+// mergeApprovedOperations adds the approved path item operations that are missing from the provided path item,
+// with their path params renamed to the provided path param names.
+func mergeApprovedOperations(providedPathItem, approvedPathItem *oapi_spec.PathItem, names map[string]string) {
+	for method, operation := range approvedPathItem.Operations() {
+		if providedPathItem.GetOperation(method) != nil {
+			continue
+		}
+		providedPathItem.SetOperation(method, withRenamedPathParams(operation, names))
+	}
+}
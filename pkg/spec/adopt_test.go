@@ -0,0 +1,116 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+
+	"gotest.tools/assert"
+)
+
+var adoptTestProvidedSpec = []byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "adopt", "version": "1.0.0"},
+  "servers": [{"url": "https://example.com/api"}],
+  "paths": {
+    "/pets/{petId}": {
+      "parameters": [
+        {"name": "petId", "in": "path", "required": true, "schema": {"type": "integer"}}
+      ],
+      "get": {
+        "security": [{"apiKey": []}],
+        "responses": {
+          "200": {
+            "description": "pet",
+            "content": {
+              "application/json": {
+                "schema": {"$ref": "#/components/schemas/Pet"}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+    },
+    "securitySchemes": {
+      "apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"}
+    }
+  }
+}`)
+
+func TestSpec_AdoptProvidedSpec(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(adoptTestProvidedSpec, map[string]string{"/pets/{petId}": "pets-id"}))
+	// an approved path that matches a provided path template is merged into the provided path
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("1", http.MethodDelete, "/api/pets/1", "example.com", "204", "", ""),
+		createTelemetry("2", http.MethodDelete, "/api/pets/2", "example.com", "204", "", ""),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+	approveSuggestedReview(t, s)
+
+	assert.NilError(t, s.AdoptProvidedSpec())
+
+	assert.Equal(t, len(s.ApprovedSpec.PathItems), 1)
+	pathItem := s.ApprovedSpec.GetPathItem("/api/pets/{petId}")
+	assert.Assert(t, pathItem != nil)
+	assert.Assert(t, pathItem.Get != nil)
+	assert.Assert(t, pathItem.Delete != nil)
+	// refs are resolved
+	assert.Equal(t, pathItem.Get.Responses["200"].Value.Content["application/json"].Schema.Value.Properties["name"].Value.Type, "string")
+	assert.Assert(t, s.ApprovedSpec.SecuritySchemes["apiKey"] != nil)
+	assert.Equal(t, s.ApprovedSpec.GetSpecVersion(), OASv3)
+
+	pathID, err := s.GetPathID("/api/pets/5", SpecSourceReconstructed)
+	assert.NilError(t, err)
+	assert.Equal(t, pathID, "pets-id")
+
+	// the approved spec evolves from the adopted spec
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("3", http.MethodPut, "/api/pets/1", "example.com", "204", `{"name": "a"}`, ""),
+		createTelemetry("4", http.MethodPut, "/api/pets/2", "example.com", "204", `{"name": "b"}`, ""),
+		createTelemetry("5", http.MethodGet, "/api/owners", "example.com", "200", "", `{"name": "a"}`),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+	approveSuggestedReview(t, s)
+
+	assert.Equal(t, len(s.ApprovedSpec.PathItems), 2)
+	pathItem = s.ApprovedSpec.GetPathItem("/api/pets/{petId}")
+	assert.Assert(t, pathItem.Get != nil)
+	assert.Assert(t, pathItem.Put != nil)
+	assert.Assert(t, pathItem.Delete != nil)
+	// the provided path params are kept
+	assert.Equal(t, len(pathItem.Parameters), 1)
+	assert.Equal(t, pathItem.Parameters.GetByInAndName(oapi_spec.ParameterInPath, "petId").Schema.Value.Type, "integer")
+	assert.Assert(t, s.ApprovedSpec.GetPathItem("/api/owners") != nil)
+}
+
+func TestSpec_AdoptProvidedSpec_NoProvidedSpec(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+
+	err := s.AdoptProvidedSpec()
+	assert.Assert(t, errors.Is(err, ErrNoProvidedSpec))
+}
@@ -0,0 +1,95 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"encoding/json"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+const (
+	// maxBodySamplesPerOperation limits the samples of an operation, a sample is kept for each status code.
+	maxBodySamplesPerOperation = 3
+	// maxBodySampleOperations limits the number of sampled operations of a spec.
+	maxBodySampleOperations = 1000
+	// maxBodySampleSize limits the size of a sampled body, larger bodies are not sampled.
+	maxBodySampleSize = 4 << 10 // 4KB
+)
+
+// BodySample holds the json request and response bodies of a learned telemetry, used as the example bodies of the
+// exported collections.
+type BodySample struct {
+	StatusCode   string
+	RequestBody  string
+	ResponseBody string
+}
+
+// recordBodySample records the telemetry json bodies as a sample of the operation, if the operation does not have
+// a sample of the telemetry status code, and the samples limits were not reached.
+func (s *Spec) recordBodySample(telemetry *Telemetry) {
+	sample := &BodySample{
+		RequestBody: getBodySample(telemetry.Request.Common),
+	}
+	if telemetry.Response != nil {
+		sample.StatusCode = telemetry.Response.StatusCode
+		sample.ResponseBody = getBodySample(telemetry.Response.Common)
+	}
+	if sample.RequestBody == "" && sample.ResponseBody == "" {
+		return
+	}
+
+	path, _ := GetPathAndQuery(telemetry.Request.Path)
+	key := getBodySamplesKey(telemetry.Request.Method, createParameterizedPath(path))
+	samples, ok := s.BodySamples[key]
+	if !ok && len(s.BodySamples) >= maxBodySampleOperations {
+		return
+	}
+	if len(samples) >= maxBodySamplesPerOperation {
+		return
+	}
+	for _, existing := range samples {
+		if existing.StatusCode == sample.StatusCode {
+			return
+		}
+	}
+
+	if s.BodySamples == nil {
+		s.BodySamples = make(map[string][]*BodySample)
+	}
+	s.BodySamples[key] = append(samples, sample)
+}
+
+// getBodySamples returns the body samples of the operation, the path params names are ignored.
+func (s *Spec) getBodySamples(method, pathTemplate string) []*BodySample {
+	return s.BodySamples[getBodySamplesKey(method, pathTemplate)]
+}
+
+func getBodySamplesKey(method, pathTemplate string) string {
+	return method + " " + utils.ClearPathParamNames(pathTemplate)
+}
+
+// getBodySample returns the body if it is a complete json body that is not too large, and an empty string otherwise.
+func getBodySample(common *Common) string {
+	if common == nil || common.TruncatedBody || len(common.Body) == 0 || len(common.Body) > maxBodySampleSize {
+		return ""
+	}
+	if !json.Valid(common.Body) {
+		return ""
+	}
+
+	return string(common.Body)
+}
@@ -0,0 +1,78 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+)
+
+const canonicalJSONIndent = "  "
+
+// WithCanonicalFormat generates the spec in a canonical form, regardless of the order the spec was learned in:
+// parameters are sorted by name and location, security requirements are sorted by scheme name,
+// and the JSON is indented with two spaces and ends with a new line.
+func WithCanonicalFormat() GenerateOption {
+	return func(params *GenerateParams) {
+		params.canonical = true
+	}
+}
+
+func canonicalizePathItems(pathItems map[string]*oapi_spec.PathItem) {
+	for _, pathItem := range pathItems {
+		if pathItem == nil {
+			continue
+		}
+		sortParameterRefs(pathItem.Parameters)
+		for _, operation := range pathItem.Operations() {
+			sortParameters(operation)
+			if operation.Security != nil {
+				sortSecurityRequirements(*operation.Security)
+			}
+		}
+	}
+}
+
+// sortSecurityRequirements sorts the security requirements by their sorted scheme names.
+func sortSecurityRequirements(requirements oapi_spec.SecurityRequirements) {
+	getKey := func(requirement oapi_spec.SecurityRequirement) string {
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		key, _ := json.Marshal(names)
+		return string(key)
+	}
+
+	sort.SliceStable(requirements, func(i, j int) bool {
+		return getKey(requirements[i]) < getKey(requirements[j])
+	})
+}
+
+func formatCanonicalJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", canonicalJSONIndent); err != nil {
+		return nil, fmt.Errorf("failed to indent the spec: %w", err)
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
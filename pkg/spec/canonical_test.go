@@ -0,0 +1,89 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func createStableTestSpec(t *testing.T, queries ...string) *Spec {
+	t.Helper()
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("1", http.MethodGet, "/users", "example.com", "200", "", `{"user": {"name": "a"}, "tags": [1, "a", true]}`),
+		createTelemetry("2", http.MethodGet, "/admins", "example.com", "200", "", `{"user": {"id": 1}}`),
+		createTelemetry("3", http.MethodPost, "/groups", "example.com", "201", `{"user": {"id": 1, "name": "a"}}`, `{"user": {"id": 1}}`),
+	} {
+		for _, name := range []string{"X-B", "X-A", "X-C"} {
+			telemetry.Request.Common.Headers = append(telemetry.Request.Common.Headers, &Header{Key: name, Value: "1"})
+		}
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+	// the learned query params are merged in the learning order
+	for _, query := range queries {
+		assert.NilError(t, s.LearnTelemetry(createTelemetry("4", http.MethodGet, "/users?"+query, "example.com", "200", "", `{"user": {"name": "a"}}`)))
+	}
+	approveSuggestedReview(t, s)
+
+	return s
+}
+
+func TestSpec_GenerateOASJson_Stable(t *testing.T) {
+	for _, version := range []OASVersion{OASv2, OASv3, OASv31} {
+		t.Run(version.String(), func(t *testing.T) {
+			s := createStableTestSpec(t)
+			want, err := s.GenerateOASJson(version)
+			assert.NilError(t, err)
+
+			for i := 0; i < 20; i++ {
+				got, err := s.GenerateOASJson(version)
+				assert.NilError(t, err)
+				assert.Equal(t, string(got), string(want))
+			}
+
+			// learning the same telemetries generates the same spec
+			got, err := createStableTestSpec(t).GenerateOASJson(version)
+			assert.NilError(t, err)
+			assert.Equal(t, string(got), string(want))
+		})
+	}
+}
+
+func TestSpec_GenerateOASJson_CanonicalFormat(t *testing.T) {
+	s := createStableTestSpec(t, "b=1", "a=1", "c=1")
+	other := createStableTestSpec(t, "c=1", "a=1", "b=1")
+
+	for _, version := range []OASVersion{OASv2, OASv3, OASv31} {
+		t.Run(version.String(), func(t *testing.T) {
+			want, err := s.GenerateOASJson(version, WithCanonicalFormat())
+			assert.NilError(t, err)
+			assert.Assert(t, bytes.HasSuffix(want, []byte("}\n")))
+			assert.Assert(t, bytes.Contains(want, []byte("{\n  \"")))
+
+			// the learning order does not affect the canonical spec
+			got, err := other.GenerateOASJson(version, WithCanonicalFormat())
+			assert.NilError(t, err)
+			assert.Equal(t, string(got), string(want))
+
+			_, _, err = LoadAndValidateRawJSONSpec(got)
+			assert.NilError(t, err)
+		})
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+var ErrMissingSpecToCompare = errors.New("missing spec to compare")
+
+type SpecComparison struct {
+	// Changes are sorted by path, method and location.
+	Changes []SpecChange
+	// Severity is breaking if any of the changes is breaking for the API consumer or provider.
+	Severity DiffSeverity
+}
+
+type SpecChange struct {
+	// Path template including the base path, taken from the modified spec unless the path was removed.
+	Path string
+	// Method is empty for added and removed paths.
+	Method string
+	// DiffChange location is relative to the operation, and is empty for added and removed paths and operations.
+	// The values of added and removed paths and operations are not set.
+	DiffChange
+}
+
+// CompareRawSpecs loads the (v2 or v3, json or yaml) specs and compares them, see CompareSpecs.
+func CompareRawSpecs(original, modified []byte) (*SpecComparison, error) {
+	originalDoc, _, err := LoadAndValidateRawJSONSpec(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load original spec: %w", err)
+	}
+
+	modifiedDoc, _, err := LoadAndValidateRawJSONSpec(modified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load modified spec: %w", err)
+	}
+
+	return CompareSpecs(originalDoc, modifiedDoc)
+}
+
+// CompareSpecs returns the path, operation and schema level changes from the original spec to the modified spec.
+// Paths are matched by their template including the base path and ignoring param names (e.g. /api/users/{id}
+// matches /api/users/{userId}), and path level parameters are compared as part of each operation.
+// Refs are cleared from clones of the documents (keeping the resolved values) so schemas are compared by value,
+// and the given documents are not modified.
+func CompareSpecs(original, modified *oapi_spec.T) (*SpecComparison, error) {
+	if original == nil || modified == nil {
+		return nil, ErrMissingSpecToCompare
+	}
+
+	clonedOriginal, err := cloneComparedDoc(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone original spec: %w", err)
+	}
+	clonedModified, err := cloneComparedDoc(modified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone modified spec: %w", err)
+	}
+
+	originalPaths := getComparedPaths(clearRefFromDoc(clonedOriginal))
+	modifiedPaths := getComparedPaths(clearRefFromDoc(clonedModified))
+
+	var changes []SpecChange
+	for _, key := range sortedUnionComparedPathKeys(originalPaths, modifiedPaths) {
+		originalPath, inOriginal := originalPaths[key]
+		modifiedPath, inModified := modifiedPaths[key]
+		switch {
+		case !inOriginal:
+			changes = append(changes, createSpecChange(modifiedPath.path, "", DiffChangeKindAdded))
+		case !inModified:
+			changes = append(changes, createSpecChange(originalPath.path, "", DiffChangeKindRemoved))
+		default:
+			pathChanges, err := comparePathItems(originalPath, modifiedPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare path %v: %w", modifiedPath.path, err)
+			}
+			changes = append(changes, pathChanges...)
+		}
+	}
+
+	return &SpecComparison{
+		Changes:  changes,
+		Severity: getSpecChangesSeverity(changes),
+	}, nil
+}
+
+// CompareProvidedWithApproved compares the provided spec (as the original) with the approved reconstructed spec
+// (as the modified), see CompareSpecs.
+func (s *Spec) CompareProvidedWithApproved() (*SpecComparison, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.HasProvidedSpec() || !s.HasApprovedSpec() {
+		return nil, ErrMissingSpecToCompare
+	}
+
+	clonedApprovedSpec, err := s.ApprovedSpec.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone approved spec: %w", err)
+	}
+
+	return CompareSpecs(s.ProvidedSpec.Doc, &oapi_spec.T{Paths: clonedApprovedSpec.PathItems})
+}
+
+// cloneComparedDoc clones the document, and resolves the refs of the clone since only the refs are cloned.
+func cloneComparedDoc(doc *oapi_spec.T) (*oapi_spec.T, error) {
+	clonedDoc, err := cloneDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := oapi_spec.NewLoader().ResolveRefsIn(clonedDoc, nil); err != nil {
+		return nil, fmt.Errorf("failed to resolve refs: %w", err)
+	}
+
+	return clonedDoc, nil
+}
+
+type comparedPath struct {
+	path     string
+	pathItem *oapi_spec.PathItem
+}
+
+// getComparedPaths maps the doc paths (including the base path) by their template key.
+func getComparedPaths(doc *oapi_spec.T) map[string]comparedPath {
+	basePath := (&ProvidedSpec{Doc: doc}).GetBasePath()
+
+	ret := make(map[string]comparedPath, len(doc.Paths))
+	for path, pathItem := range doc.Paths {
+		fullPath := addBasePathIfNeeded(basePath, path)
+		ret[utils.ClearPathParamNames(fullPath)] = comparedPath{
+			path:     fullPath,
+			pathItem: pathItem,
+		}
+	}
+
+	return ret
+}
+
+func comparePathItems(originalPath, modifiedPath comparedPath) ([]SpecChange, error) {
+	var changes []SpecChange
+
+	path := modifiedPath.path
+	original, modified := originalPath.pathItem, modifiedPath.pathItem
+	// original path params are renamed to the modified path param names, so they are compared by position
+	paramNames := getPathParamNamesMapping(originalPath.path, modifiedPath.path)
+
+	originalOperations := original.Operations()
+	modifiedOperations := modified.Operations()
+	for _, method := range sortedUnionMethods(originalOperations, modifiedOperations) {
+		originalOp, inOriginal := originalOperations[method]
+		modifiedOp, inModified := modifiedOperations[method]
+		switch {
+		case !inOriginal:
+			changes = append(changes, createSpecChange(path, method, DiffChangeKindAdded))
+		case !inModified:
+			changes = append(changes, createSpecChange(path, method, DiffChangeKindRemoved))
+		default:
+			originalOp = withRenamedPathParams(withPathItemParameters(original, originalOp), paramNames)
+			modifiedOp = withPathItemParameters(modified, modifiedOp)
+			opChanges, err := calculateOperationChanges(originalOp, modifiedOp)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate %v operation changes: %w", method, err)
+			}
+			for _, opChange := range opChanges {
+				changes = append(changes, SpecChange{Path: path, Method: method, DiffChange: opChange})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// withPathItemParameters returns a shallow copy of the operation with the path item parameters that are not
+// overridden by the operation parameters.
+func withPathItemParameters(pathItem *oapi_spec.PathItem, operation *oapi_spec.Operation) *oapi_spec.Operation {
+	if len(pathItem.Parameters) == 0 {
+		return operation
+	}
+
+	ret := *operation
+	ret.Parameters = append(oapi_spec.Parameters{}, operation.Parameters...)
+	for _, paramRef := range pathItem.Parameters {
+		if paramRef.Value == nil || operation.Parameters.GetByInAndName(paramRef.Value.In, paramRef.Value.Name) != nil {
+			continue
+		}
+		ret.Parameters = append(ret.Parameters, paramRef)
+	}
+
+	return &ret
+}
+
+// getPathParamNamesMapping maps the path params names of the original path template to the modified path template
+// names by position, e.g. /users/{id} and /users/{userId} will return {id: userId}.
+func getPathParamNamesMapping(originalPath, modifiedPath string) map[string]string {
+	originalNames := getPathParamNames(originalPath)
+	modifiedNames := getPathParamNames(modifiedPath)
+
+	ret := make(map[string]string)
+	for i := range originalNames {
+		if i < len(modifiedNames) && originalNames[i] != modifiedNames[i] {
+			ret[originalNames[i]] = modifiedNames[i]
+		}
+	}
+
+	return ret
+}
+
+func getPathParamNames(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		for _, token := range utils.SplitPathSegmentTemplate(segment) {
+			if utils.IsPathParam(token) {
+				names = append(names, strings.TrimSuffix(strings.TrimPrefix(token, utils.ParamPrefix), utils.ParamSuffix))
+			}
+		}
+	}
+
+	return names
+}
+
+// withRenamedPathParams returns a shallow copy of the operation with the path params renamed by names.
+func withRenamedPathParams(operation *oapi_spec.Operation, names map[string]string) *oapi_spec.Operation {
+	if len(names) == 0 {
+		return operation
+	}
+
+	ret := *operation
+	ret.Parameters = make(oapi_spec.Parameters, 0, len(operation.Parameters))
+	for _, paramRef := range operation.Parameters {
+		if paramRef.Value != nil && paramRef.Value.In == oapi_spec.ParameterInPath {
+			if name, ok := names[paramRef.Value.Name]; ok {
+				param := *paramRef.Value
+				param.Name = name
+				paramRef = &oapi_spec.ParameterRef{Ref: paramRef.Ref, Value: &param}
+			}
+		}
+		ret.Parameters = append(ret.Parameters, paramRef)
+	}
+
+	return &ret
+}
+
+// createSpecChange creates an added or removed path or operation change.
+// A removed path or operation is breaking for the consumer.
+func createSpecChange(path, method string, kind DiffChangeKind) SpecChange {
+	consumerSeverity := DiffSeverityNonBreaking
+	if kind == DiffChangeKindRemoved {
+		consumerSeverity = DiffSeverityBreaking
+	}
+
+	return SpecChange{
+		Path:   path,
+		Method: method,
+		DiffChange: DiffChange{
+			Kind:             kind,
+			ConsumerSeverity: consumerSeverity,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+	}
+}
+
+func getSpecChangesSeverity(changes []SpecChange) DiffSeverity {
+	diffChanges := make([]DiffChange, 0, len(changes))
+	for _, change := range changes {
+		diffChanges = append(diffChanges, change.DiffChange)
+	}
+
+	return getDiffSeverity(diffChanges)
+}
+
+// sortedUnionComparedPathKeys returns the keys sorted by the path of the modified spec (or original if removed).
+func sortedUnionComparedPathKeys(original, modified map[string]comparedPath) []string {
+	paths := make(map[string]string)
+	for key, p := range original {
+		paths[key] = p.path
+	}
+	for key, p := range modified {
+		paths[key] = p.path
+	}
+
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return paths[keys[i]] < paths[keys[j]]
+	})
+
+	return keys
+}
+
+func sortedUnionMethods(original, modified map[string]*oapi_spec.Operation) []string {
+	methodsSet := make(map[string]bool)
+	for method := range original {
+		methodsSet[method] = true
+	}
+	for method := range modified {
+		methodsSet[method] = true
+	}
+
+	methods := make([]string, 0, len(methodsSet))
+	for method := range methodsSet {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
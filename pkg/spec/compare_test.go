@@ -0,0 +1,240 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+var compareTestOriginalSpec = []byte(`
+openapi: 3.0.3
+info:
+  title: original
+  version: 1.0.0
+servers:
+  - url: https://example.com/api
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      responses:
+        '200':
+          description: user
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+    delete:
+      responses:
+        '204':
+          description: deleted
+  /health:
+    get:
+      responses:
+        '200':
+          description: ok
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+`)
+
+var compareTestModifiedSpec = []byte(`
+swagger: '2.0'
+info:
+  title: modified
+  version: 2.0.0
+host: example.com
+basePath: /api
+paths:
+  /users/{userId}:
+    parameters:
+      - name: userId
+        in: path
+        required: true
+        type: string
+    get:
+      produces:
+        - application/json
+      responses:
+        '200':
+          description: user
+          schema:
+            type: object
+            properties:
+              name:
+                type: string
+              age:
+                type: string
+              email:
+                type: string
+  /users:
+    post:
+      responses:
+        '201':
+          description: created
+`)
+
+func TestCompareRawSpecs(t *testing.T) {
+	got, err := CompareRawSpecs(compareTestOriginalSpec, compareTestModifiedSpec)
+	assert.NilError(t, err)
+
+	want := &SpecComparison{
+		Changes: []SpecChange{
+			{
+				Path: "/api/health",
+				DiffChange: DiffChange{
+					Kind:             DiffChangeKindRemoved,
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+			{
+				Path: "/api/users",
+				DiffChange: DiffChange{
+					Kind:             DiffChangeKindAdded,
+					ConsumerSeverity: DiffSeverityNonBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+			{
+				Path:   "/api/users/{userId}",
+				Method: http.MethodDelete,
+				DiffChange: DiffChange{
+					Kind:             DiffChangeKindRemoved,
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+			{
+				Path:   "/api/users/{userId}",
+				Method: http.MethodGet,
+				DiffChange: DiffChange{
+					Location:         Location{"responses", "200", "content", "application/json", "schema", "properties", "age"},
+					Kind:             DiffChangeKindTypeChanged,
+					OldValue:         "integer",
+					NewValue:         "string",
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+			{
+				Path:   "/api/users/{userId}",
+				Method: http.MethodGet,
+				DiffChange: DiffChange{
+					Location:         Location{"responses", "200", "content", "application/json", "schema", "properties", "email"},
+					Kind:             DiffChangeKindAdded,
+					NewValue:         map[string]interface{}{"type": "string"},
+					ConsumerSeverity: DiffSeverityNonBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+		},
+		Severity: DiffSeverityBreaking,
+	}
+	assert.DeepEqual(t, got, want)
+}
+
+func TestSpec_CompareProvidedWithApproved(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	_, err := s.CompareProvidedWithApproved()
+	assert.Assert(t, errors.Is(err, ErrMissingSpecToCompare))
+
+	assert.NilError(t, s.LoadProvidedSpec(compareTestOriginalSpec, nil))
+	s.ApprovedSpec = &ApprovedSpec{
+		PathItems: map[string]*spec.PathItem{
+			"/api/health": &NewTestPathItem().WithOperation(http.MethodGet,
+				createTestOperation().WithResponse(200, spec.NewResponse().WithDescription("ok")).Op).PathItem,
+		},
+	}
+
+	got, err := s.CompareProvidedWithApproved()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, &SpecComparison{
+		Changes: []SpecChange{
+			{
+				Path: "/api/users/{id}",
+				DiffChange: DiffChange{
+					Kind:             DiffChangeKindRemoved,
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+		},
+		Severity: DiffSeverityBreaking,
+	})
+}
+
+var compareTestRefSpec = `
+openapi: 3.0.3
+info:
+  title: refs
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        age:
+          type: %v
+`
+
+func TestCompareSpecs_InputsUnchanged(t *testing.T) {
+	original, _, err := LoadAndValidateRawJSONSpec([]byte(fmt.Sprintf(compareTestRefSpec, "integer")))
+	assert.NilError(t, err)
+	modified, _, err := LoadAndValidateRawJSONSpec([]byte(fmt.Sprintf(compareTestRefSpec, "string")))
+	assert.NilError(t, err)
+
+	got, err := CompareSpecs(original, modified)
+	assert.NilError(t, err)
+	assert.Equal(t, len(got.Changes), 1)
+	assert.DeepEqual(t, got.Changes[0].Location, Location{"responses", "200", "content", "application/json", "schema", "properties", "age"})
+	assert.Equal(t, got.Changes[0].Kind, DiffChangeKindTypeChanged)
+
+	// the refs of the given documents are not cleared
+	for _, doc := range []*spec.T{original, modified} {
+		schemaRef := doc.Paths["/users"].Get.Responses["200"].Value.Content["application/json"].Schema
+		assert.Equal(t, schemaRef.Ref, "#/components/schemas/User")
+		assert.Equal(t, schemaRef.Value, doc.Components.Schemas["User"].Value)
+	}
+	assert.Equal(t, original.Components.Schemas["User"].Value.Properties["age"].Value.Type, "integer")
+}
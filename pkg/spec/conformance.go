@@ -0,0 +1,380 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+type DiffMode string
+
+const (
+	// DiffModeExact reports a diff whenever the observed operation differs from the spec operation.
+	DiffModeExact DiffMode = "EXACT"
+	// DiffModeConformance reports a diff only when the observed operation violates the spec operation.
+	DiffModeConformance DiffMode = "CONFORMANCE"
+)
+
+// conformanceChecker collects the violations of an observed (telemetry) operation against a spec operation.
+// The observed operation conforms to the spec operation if it is a subset of it:
+//   - Undeclared query and cookie params, properties that are not allowed by `additionalProperties`,
+//     request bodies, media types and status codes that are not declared by the spec are violations.
+//     Undeclared headers are tolerated, since clients and proxies add headers freely.
+//   - Missing `required` params, request body and properties are violations, missing optional ones are not.
+//   - Types must be compatible (an integer fits a number, and any serialized param value fits a string).
+//   - A `nullable` spec schema accepts a null value. Since a null value is learned as a string schema,
+//     any observed string fits a nullable spec schema.
+type conformanceChecker struct {
+	// headers that are not learned as params, so they can't be checked for being required
+	requestHeadersToIgnore  map[string]struct{}
+	responseHeadersToIgnore map[string]struct{}
+
+	violations []DiffChange
+	err        error
+}
+
+// calculateOperationViolations returns the observed operation violations against the spec operation,
+// for the given response status code, sorted by location.
+func (s *Spec) calculateOperationViolations(specOp, observedOp *oapi_spec.Operation, statusCode string) ([]DiffChange, error) {
+	c := &conformanceChecker{}
+	if s.OpGenerator != nil {
+		c.requestHeadersToIgnore = s.OpGenerator.RequestHeadersToIgnore
+		c.responseHeadersToIgnore = s.OpGenerator.ResponseHeadersToIgnore
+	}
+
+	c.checkParameters(Location{locationParameters}, specOp.Parameters, observedOp.Parameters)
+	c.checkRequestBody(Location{locationRequestBody}, specOp.RequestBody, observedOp.RequestBody)
+	c.checkResponse(Location{locationResponses, statusCode}, getSpecResponse(specOp.Responses, statusCode), observedOp.Responses[statusCode])
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	sortDiffChanges(c.violations)
+
+	return c.violations, nil
+}
+
+func (c *conformanceChecker) addViolation(location Location, kind DiffChangeKind, specValue, observedValue interface{}) {
+	specObj, err := toGenericObject(specValue)
+	if err != nil {
+		c.err = fmt.Errorf("failed to convert spec value: %w", err)
+		return
+	}
+
+	observedObj, err := toGenericObject(observedValue)
+	if err != nil {
+		c.err = fmt.Errorf("failed to convert observed value: %w", err)
+		return
+	}
+
+	c.violations = append(c.violations, createDiffChange(location, kind, specObj, observedObj))
+}
+
+func (c *conformanceChecker) checkParameters(location Location, specParams, observedParams oapi_spec.Parameters) {
+	specParamsMap := createConformanceParametersMap(specParams)
+	observedParamsMap := createConformanceParametersMap(observedParams)
+
+	for key, observedParam := range observedParamsMap {
+		paramLocation := appendLocation(location, observedParam.In, observedParam.Name)
+		specParam, ok := specParamsMap[key]
+		if !ok {
+			// path params are matched by the path trie
+			if observedParam.In != oapi_spec.ParameterInHeader && observedParam.In != oapi_spec.ParameterInPath {
+				c.addViolation(paramLocation, DiffChangeKindNewParam, nil, observedParam)
+			}
+			continue
+		}
+		c.checkSchema(appendLocation(paramLocation, "schema"), specParam.Schema, observedParam.Schema, true)
+	}
+
+	for key, specParam := range specParamsMap {
+		if _, ok := observedParamsMap[key]; ok {
+			continue
+		}
+		if specParam.Required && specParam.In != oapi_spec.ParameterInPath && c.isLearnedRequestParam(specParam) {
+			c.addViolation(appendLocation(location, specParam.In, specParam.Name), DiffChangeKindRemoved, specParam, nil)
+		}
+	}
+}
+
+// isLearnedRequestParam returns false for headers that are not learned as header params
+// (e.g. ignored headers, cookies and security headers).
+func (c *conformanceChecker) isLearnedRequestParam(param *oapi_spec.Parameter) bool {
+	if param.In != oapi_spec.ParameterInHeader {
+		return true
+	}
+
+	lowerName := strings.ToLower(param.Name)
+
+	return !shouldIgnoreHeader(c.requestHeadersToIgnore, lowerName) &&
+		!APIKeyNames[lowerName] && lowerName != cookieTypeHeaderName
+}
+
+// createConformanceParametersMap maps the parameters by `in` and name. Header names are case-insensitive.
+func createConformanceParametersMap(params oapi_spec.Parameters) map[string]*oapi_spec.Parameter {
+	ret := make(map[string]*oapi_spec.Parameter)
+
+	for _, paramRef := range params {
+		if paramRef == nil || paramRef.Value == nil {
+			continue
+		}
+		name := paramRef.Value.Name
+		if paramRef.Value.In == oapi_spec.ParameterInHeader {
+			name = strings.ToLower(name)
+		}
+		ret[getParameterKey(paramRef.Value.In, name)] = paramRef.Value
+	}
+
+	return ret
+}
+
+func (c *conformanceChecker) checkRequestBody(location Location, specBody, observedBody *oapi_spec.RequestBodyRef) {
+	var observedContent oapi_spec.Content
+	if observedBody != nil && observedBody.Value != nil {
+		observedContent = observedBody.Value.Content
+	}
+
+	if specBody == nil || specBody.Value == nil {
+		if len(observedContent) > 0 {
+			c.addViolation(location, DiffChangeKindAdded, nil, observedBody)
+		}
+		return
+	}
+
+	if len(observedContent) == 0 {
+		if specBody.Value.Required {
+			c.addViolation(location, DiffChangeKindRemoved, specBody, nil)
+		}
+		return
+	}
+
+	c.checkContent(appendLocation(location, "content"), specBody.Value.Content, observedContent)
+}
+
+// getSpecResponse returns the spec response for the status code, the status code range (e.g. 2XX) or the default response.
+func getSpecResponse(responses oapi_spec.Responses, statusCode string) *oapi_spec.ResponseRef {
+	if response, ok := responses[statusCode]; ok {
+		return response
+	}
+
+	if len(statusCode) > 0 {
+		statusCodeRange := statusCode[:1] + "XX"
+		if response, ok := responses[statusCodeRange]; ok {
+			return response
+		}
+		if response, ok := responses[strings.ToLower(statusCodeRange)]; ok {
+			return response
+		}
+	}
+
+	return responses.Default()
+}
+
+func (c *conformanceChecker) checkResponse(location Location, specResponse, observedResponse *oapi_spec.ResponseRef) {
+	if observedResponse == nil || observedResponse.Value == nil {
+		return
+	}
+
+	if specResponse == nil || specResponse.Value == nil {
+		c.addViolation(location, DiffChangeKindNewStatusCode, nil, observedResponse)
+		return
+	}
+
+	// a missing response body is tolerated (e.g. a HEAD request or a truncated body)
+	if len(observedResponse.Value.Content) > 0 {
+		c.checkContent(appendLocation(location, "content"), specResponse.Value.Content, observedResponse.Value.Content)
+	}
+
+	c.checkResponseHeaders(appendLocation(location, "headers"), specResponse.Value.Headers, observedResponse.Value.Headers)
+}
+
+func (c *conformanceChecker) checkResponseHeaders(location Location, specHeaders, observedHeaders oapi_spec.Headers) {
+	observedHeadersMap := make(map[string]*oapi_spec.HeaderRef)
+	for name, header := range observedHeaders {
+		observedHeadersMap[strings.ToLower(name)] = header
+	}
+
+	for name, specHeader := range specHeaders {
+		if specHeader == nil || specHeader.Value == nil {
+			continue
+		}
+		observedHeader, ok := observedHeadersMap[strings.ToLower(name)]
+		if !ok {
+			if specHeader.Value.Required && !shouldIgnoreHeader(c.responseHeadersToIgnore, name) {
+				c.addViolation(appendLocation(location, name), DiffChangeKindRemoved, specHeader, nil)
+			}
+			continue
+		}
+		if observedHeader != nil && observedHeader.Value != nil {
+			c.checkSchema(appendLocation(location, name, "schema"), specHeader.Value.Schema, observedHeader.Value.Schema, true)
+		}
+	}
+}
+
+func (c *conformanceChecker) checkContent(location Location, specContent, observedContent oapi_spec.Content) {
+	for mediaType, observedMediaType := range observedContent {
+		mediaTypeLocation := appendLocation(location, mediaType)
+		specMediaType := specContent.Get(mediaType)
+		if specMediaType == nil {
+			c.addViolation(mediaTypeLocation, DiffChangeKindAdded, nil, observedMediaType)
+			continue
+		}
+		if observedMediaType == nil {
+			continue
+		}
+		// non JSON bodies (e.g. form data) values are serialized the same as params
+		serialized := !utils.IsApplicationJSONMediaType(mediaType)
+		c.checkSchema(appendLocation(mediaTypeLocation, "schema"), specMediaType.Schema, observedMediaType.Schema, serialized)
+	}
+}
+
+// checkSchema checks that the observed schema fits the spec schema.
+// serialized is set when the observed values were serialized as strings (e.g. params), so their inferred type is not final.
+func (c *conformanceChecker) checkSchema(location Location, specRef, observedRef *oapi_spec.SchemaRef, serialized bool) {
+	if specRef == nil || specRef.Value == nil || observedRef == nil || observedRef.Value == nil {
+		return
+	}
+	specSchema, observed := specRef.Value, observedRef.Value
+
+	// mixed type arrays items are learned as oneOf
+	if len(observed.OneOf) > 0 {
+		for _, observedItem := range observed.OneOf {
+			c.checkSchema(location, specRef, observedItem, serialized)
+		}
+		return
+	}
+
+	if len(specSchema.AllOf) > 0 {
+		for _, specItem := range specSchema.AllOf {
+			c.checkSchema(location, specItem, observedRef, serialized)
+		}
+	}
+
+	if alternatives := append(append(oapi_spec.SchemaRefs{}, specSchema.OneOf...), specSchema.AnyOf...); len(alternatives) > 0 {
+		if !c.fitsAnySchema(location, alternatives, observedRef, serialized) {
+			c.addViolation(location, DiffChangeKindChanged, specSchema, observed)
+		}
+	}
+
+	if specSchema.Type == "" && len(specSchema.Properties) == 0 {
+		return
+	}
+
+	if specSchema.Nullable && isNullSchema(observed) {
+		return
+	}
+
+	specType := specSchema.Type
+	if specType == "" {
+		specType = oapi_spec.TypeObject
+	}
+
+	if !isTypeCompatible(specType, observed.Type, serialized) {
+		c.addViolation(location, DiffChangeKindTypeChanged, specType, observed.Type)
+		return
+	}
+
+	switch specType {
+	case oapi_spec.TypeString:
+		if observed.Type == oapi_spec.TypeString && isInferredFormat(specSchema.Format) && observed.Format != specSchema.Format {
+			c.addViolation(location, DiffChangeKindFormatChanged, specSchema.Format, observed.Format)
+		}
+	case oapi_spec.TypeObject:
+		c.checkObjectSchema(location, specSchema, observed, serialized)
+	case oapi_spec.TypeArray:
+		if observed.Type == oapi_spec.TypeArray {
+			c.checkSchema(appendLocation(location, "items"), specSchema.Items, observed.Items, serialized)
+		} else {
+			// a single serialized value fits an array
+			c.checkSchema(location, specSchema.Items, observedRef, serialized)
+		}
+	}
+}
+
+func (c *conformanceChecker) checkObjectSchema(location Location, specSchema, observed *oapi_spec.Schema, serialized bool) {
+	for name, observedProperty := range observed.Properties {
+		propertyLocation := appendLocation(location, "properties", name)
+		if specProperty, ok := specSchema.Properties[name]; ok {
+			c.checkSchema(propertyLocation, specProperty, observedProperty, serialized)
+			continue
+		}
+		if specSchema.AdditionalProperties != nil {
+			c.checkSchema(propertyLocation, specSchema.AdditionalProperties, observedProperty, serialized)
+			continue
+		}
+		if specSchema.AdditionalPropertiesAllowed != nil && !*specSchema.AdditionalPropertiesAllowed {
+			c.addViolation(propertyLocation, DiffChangeKindAdded, nil, observedProperty)
+		}
+	}
+
+	for _, name := range specSchema.Required {
+		if _, ok := observed.Properties[name]; !ok {
+			c.addViolation(appendLocation(location, "properties", name), DiffChangeKindRemoved, specSchema.Properties[name], nil)
+		}
+	}
+}
+
+func (c *conformanceChecker) fitsAnySchema(location Location, specRefs oapi_spec.SchemaRefs, observedRef *oapi_spec.SchemaRef, serialized bool) bool {
+	for _, specRef := range specRefs {
+		alternativeChecker := &conformanceChecker{}
+		alternativeChecker.checkSchema(location, specRef, observedRef, serialized)
+		if len(alternativeChecker.violations) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTypeCompatible(specType, observedType string, serialized bool) bool {
+	if specType == observedType {
+		return true
+	}
+
+	if specType == oapi_spec.TypeNumber && observedType == oapi_spec.TypeInteger {
+		return true
+	}
+
+	if serialized {
+		// any serialized value is a valid string, and a single value is a valid array
+		return specType == oapi_spec.TypeString || specType == oapi_spec.TypeArray
+	}
+
+	return false
+}
+
+// isNullSchema returns true if schema may have been learned from a null value.
+func isNullSchema(schema *oapi_spec.Schema) bool {
+	return schema.Type == oapi_spec.TypeString && schema.Format == ""
+}
+
+// isInferredFormat returns true if the format is one that is inferred on learning,
+// other formats can't be checked against the learned schema.
+func isInferredFormat(format string) bool {
+	for _, inferredFormat := range formats {
+		if format == inferredFormat {
+			return true
+		}
+	}
+
+	return false
+}
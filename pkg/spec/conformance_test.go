@@ -0,0 +1,343 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	spec "github.com/getkin/kin-openapi/openapi3"
+	uuid "github.com/satori/go.uuid"
+	"gotest.tools/assert"
+)
+
+func createTestJSONResponse(schema *spec.Schema) *spec.Response {
+	return spec.NewResponse().WithDescription("response").WithJSONSchema(schema)
+}
+
+func Test_calculateOperationViolations(t *testing.T) {
+	falseValue := false
+	type args struct {
+		specOp     *spec.Operation
+		observedOp *spec.Operation
+		statusCode string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []DiffChange
+	}{
+		{
+			name: "missing optional query param and undeclared header - conforms",
+			args: args{
+				specOp: createTestOperation().
+					WithParameter(spec.NewQueryParameter("optional").WithSchema(spec.NewStringSchema())).Op,
+				observedOp: createTestOperation().
+					WithParameter(spec.NewHeaderParameter("X-Undeclared").WithSchema(spec.NewStringSchema())).Op,
+			},
+			want: nil,
+		},
+		{
+			name: "spec declares extra headers - conforms",
+			args: args{
+				specOp: createTestOperation().
+					WithParameter(spec.NewHeaderParameter("X-Optional").WithSchema(spec.NewStringSchema())).
+					WithParameter(spec.NewHeaderParameter("Authorization").WithSchema(spec.NewStringSchema()).WithRequired(true)).Op,
+				observedOp: createTestOperation().Op,
+			},
+			want: nil,
+		},
+		{
+			name: "missing required query param",
+			args: args{
+				specOp: createTestOperation().
+					WithParameter(spec.NewQueryParameter("limit").WithSchema(spec.NewInt64Schema()).WithRequired(true)).Op,
+				observedOp: createTestOperation().Op,
+			},
+			want: []DiffChange{
+				{
+					Location: Location{"parameters", "query", "limit"},
+					Kind:     DiffChangeKindRemoved,
+					OldValue: map[string]interface{}{
+						"in": "query", "name": "limit", "required": true,
+						"schema": map[string]interface{}{"type": "integer", "format": "int64"},
+					},
+				},
+			},
+		},
+		{
+			name: "undeclared query param",
+			args: args{
+				specOp: createTestOperation().Op,
+				observedOp: createTestOperation().
+					WithParameter(spec.NewQueryParameter("debug").WithSchema(spec.NewBoolSchema())).Op,
+			},
+			want: []DiffChange{
+				{
+					Location: Location{"parameters", "query", "debug"},
+					Kind:     DiffChangeKindNewParam,
+					NewValue: map[string]interface{}{
+						"in": "query", "name": "debug",
+						"schema": map[string]interface{}{"type": "boolean"},
+					},
+				},
+			},
+		},
+		{
+			name: "serialized param value fits a string and an array",
+			args: args{
+				specOp: createTestOperation().
+					WithParameter(spec.NewQueryParameter("name").WithSchema(spec.NewStringSchema())).
+					WithParameter(spec.NewQueryParameter("ids").WithSchema(spec.NewArraySchema().WithItems(spec.NewInt64Schema()))).Op,
+				observedOp: createTestOperation().
+					WithParameter(spec.NewQueryParameter("name").WithSchema(spec.NewInt64Schema())).
+					WithParameter(spec.NewQueryParameter("ids").WithSchema(spec.NewInt64Schema())).Op,
+			},
+			want: nil,
+		},
+		{
+			name: "param type violation",
+			args: args{
+				specOp: createTestOperation().
+					WithParameter(spec.NewQueryParameter("limit").WithSchema(spec.NewInt64Schema())).Op,
+				observedOp: createTestOperation().
+					WithParameter(spec.NewQueryParameter("limit").WithSchema(spec.NewStringSchema())).Op,
+			},
+			want: []DiffChange{
+				{
+					Location: Location{"parameters", "query", "limit", "schema"},
+					Kind:     DiffChangeKindTypeChanged,
+					OldValue: "integer",
+					NewValue: "string",
+				},
+			},
+		},
+		{
+			name: "response missing optional field and integer fits number - conforms",
+			args: args{
+				specOp: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("price", spec.NewFloat64Schema()).
+					WithProperty("description", spec.NewStringSchema()))).Op,
+				observedOp: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("price", spec.NewInt64Schema()))).Op,
+				statusCode: "200",
+			},
+			want: nil,
+		},
+		{
+			name: "response missing required field and wrong type",
+			args: args{
+				specOp: createTestOperation().WithResponse(200, createTestJSONResponse(&spec.Schema{
+					Type: spec.TypeObject,
+					Properties: spec.Schemas{
+						"id":   spec.NewSchemaRef("", spec.NewInt64Schema()),
+						"name": spec.NewSchemaRef("", spec.NewStringSchema()),
+					},
+					Required: []string{"name"},
+				})).Op,
+				observedOp: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("id", spec.NewStringSchema().WithFormat("uuid")))).Op,
+				statusCode: "200",
+			},
+			want: []DiffChange{
+				{
+					Location: Location{"responses", "200", "content", "application/json", "schema", "properties", "id"},
+					Kind:     DiffChangeKindTypeChanged,
+					OldValue: "integer",
+					NewValue: "string",
+				},
+				{
+					Location: Location{"responses", "200", "content", "application/json", "schema", "properties", "name"},
+					Kind:     DiffChangeKindRemoved,
+					OldValue: map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+		{
+			name: "additional properties",
+			args: args{
+				specOp: createTestOperation().WithRequestBody(spec.NewRequestBody().WithJSONSchema(spec.NewObjectSchema().
+					WithProperty("open", spec.NewObjectSchema()).
+					WithProperty("closed", &spec.Schema{Type: spec.TypeObject, AdditionalPropertiesAllowed: &falseValue}).
+					WithProperty("map", spec.NewObjectSchema().WithAdditionalProperties(spec.NewInt64Schema())))).Op,
+				observedOp: createTestOperation().WithRequestBody(spec.NewRequestBody().WithJSONSchema(spec.NewObjectSchema().
+					WithProperty("open", spec.NewObjectSchema().WithProperty("extra", spec.NewStringSchema())).
+					WithProperty("closed", spec.NewObjectSchema().WithProperty("extra", spec.NewStringSchema())).
+					WithProperty("map", spec.NewObjectSchema().WithProperty("key", spec.NewBoolSchema())))).Op,
+			},
+			want: []DiffChange{
+				{
+					Location: Location{"requestBody", "content", "application/json", "schema", "properties", "closed", "properties", "extra"},
+					Kind:     DiffChangeKindAdded,
+					NewValue: map[string]interface{}{"type": "string"},
+				},
+				{
+					Location: Location{"requestBody", "content", "application/json", "schema", "properties", "map", "properties", "key"},
+					Kind:     DiffChangeKindTypeChanged,
+					OldValue: "integer",
+					NewValue: "boolean",
+				},
+			},
+		},
+		{
+			name: "nullable field with a null value - conforms",
+			args: args{
+				specOp: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("count", spec.NewInt64Schema().WithNullable()))).Op,
+				observedOp: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("count", spec.NewStringSchema()))).Op,
+				statusCode: "200",
+			},
+			want: nil,
+		},
+		{
+			name: "status code range - conforms",
+			args: args{
+				specOp: &spec.Operation{Responses: spec.Responses{
+					"2XX": &spec.ResponseRef{Value: spec.NewResponse().WithDescription("success")},
+				}},
+				observedOp: createTestOperation().WithResponse(201, spec.NewResponse().WithDescription("response")).Op,
+				statusCode: "201",
+			},
+			want: nil,
+		},
+		{
+			name: "new status code",
+			args: args{
+				specOp:     createTestOperation().WithResponse(200, spec.NewResponse().WithDescription("response")).Op,
+				observedOp: createTestOperation().WithResponse(500, spec.NewResponse().WithDescription("response")).Op,
+				statusCode: "500",
+			},
+			want: []DiffChange{
+				{
+					Location: Location{"responses", "500"},
+					Kind:     DiffChangeKindNewStatusCode,
+					NewValue: map[string]interface{}{"description": "response"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Spec{OpGenerator: CreateTestNewOperationGenerator()}
+			got, err := s.calculateOperationViolations(tt.args.specOp, tt.args.observedOp, tt.args.statusCode)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestSpec_DiffTelemetry_Conformance(t *testing.T) {
+	reqID := "req-id"
+	specOp := NewOperation(t, &HTTPInteractionData{
+		RespBody:    `{"id": 1, "name": "name", "description": "description"}`,
+		RespHeaders: map[string]string{contentTypeHeaderName: mediaTypeApplicationJSON},
+		QueryParams: url.Values{"verbose": []string{"true"}},
+		statusCode:  200,
+	}).Op
+	deprecatedOp, err := CloneOperation(specOp)
+	assert.NilError(t, err)
+	deprecatedOp.Deprecated = true
+	sunsetOp, err := CloneOperation(deprecatedOp)
+	assert.NilError(t, err)
+	setOperationSunset(sunsetOp, time.Now().Add(-time.Hour))
+	s := &Spec{
+		SpecInfo: SpecInfo{
+			ID: uuid.NewV5(uuid.Nil, "spec-id"),
+			ApprovedSpec: &ApprovedSpec{
+				PathItems: map[string]*spec.PathItem{
+					"/api":        &NewTestPathItem().WithOperation(http.MethodGet, specOp).PathItem,
+					"/deprecated": &NewTestPathItem().WithOperation(http.MethodGet, deprecatedOp).PathItem,
+					"/sunset":     &NewTestPathItem().WithOperation(http.MethodGet, sunsetOp).PathItem,
+				},
+			},
+			ApprovedPathTrie: createPathTrie(map[string]string{
+				"/api":        "1",
+				"/deprecated": "2",
+				"/sunset":     "3",
+			}),
+		},
+		OpGenerator: CreateTestNewOperationGenerator(),
+	}
+
+	tests := []struct {
+		name           string
+		mode           DiffMode
+		path           string
+		respBody       string
+		wantType       DiffType
+		wantPastSunset bool
+	}{
+		{
+			name:     "exact - missing optional param and field is a diff",
+			mode:     DiffModeExact,
+			respBody: `{"id": 2, "name": "other"}`,
+			wantType: DiffTypeGeneralDiff,
+		},
+		{
+			name:     "conformance - missing optional param and field is not a diff",
+			mode:     DiffModeConformance,
+			respBody: `{"id": 2, "name": "other"}`,
+			wantType: DiffTypeNoDiff,
+		},
+		{
+			name:     "conformance - type violation is a diff",
+			mode:     DiffModeConformance,
+			respBody: `{"id": "2", "name": "other"}`,
+			wantType: DiffTypeGeneralDiff,
+		},
+		{
+			name:     "exact - call to deprecated operation is a zombie diff",
+			mode:     DiffModeExact,
+			path:     "/deprecated",
+			respBody: `{"id": 2, "name": "other", "description": "other"}`,
+			wantType: DiffTypeZombieDiff,
+		},
+		{
+			name:     "conformance - conforming call to deprecated operation is a zombie diff",
+			mode:     DiffModeConformance,
+			path:     "/deprecated",
+			respBody: `{"id": 2, "name": "other", "description": "other"}`,
+			wantType: DiffTypeZombieDiff,
+		},
+		{
+			name:           "conformance - conforming call to past sunset operation is escalated",
+			mode:           DiffModeConformance,
+			path:           "/sunset",
+			respBody:       `{"id": 2, "name": "other"}`,
+			wantType:       DiffTypeZombieDiff,
+			wantPastSunset: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = "/api"
+			}
+			telemetry := createTelemetry(reqID, http.MethodGet, path, "host", "200", "", tt.respBody)
+			got, err := s.DiffTelemetry(telemetry, SpecSourceReconstructed, WithDiffMode(tt.mode))
+			assert.NilError(t, err)
+			assert.Equal(t, got.Type, tt.wantType)
+			assert.Equal(t, got.PastSunset, tt.wantPastSunset)
+			if tt.wantPastSunset {
+				assert.Equal(t, got.Severity, DiffSeverityBreaking)
+			}
+		})
+	}
+}
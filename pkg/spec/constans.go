@@ -20,6 +20,9 @@ const (
 	acceptTypeHeaderName        = "accept"
 	authorizationTypeHeaderName = "authorization"
 	cookieTypeHeaderName        = "cookie"
+	deprecationHeaderName       = "deprecation"
+	sunsetHeaderName            = "sunset"
+	warningHeaderName           = "warning"
 )
 
 const (
@@ -0,0 +1,363 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+)
+
+var ErrNoProvidedSpec = errors.New("no provided spec")
+
+// maxCoverageRequestIDs bounds the recorded request IDs kept to count a telemetry once.
+const maxCoverageRequestIDs = 1000
+
+// ProvidedSpecCoverage holds the provided spec operations that were observed by traffic.
+type ProvidedSpecCoverage struct {
+	// Operations maps the provided spec path template (without the base path) and method to the operation observations.
+	Operations map[string]map[string]*OperationObservations
+	// RequestIDs holds the most recent recorded telemetry request IDs, oldest first.
+	RequestIDs []string
+}
+
+type OperationObservations struct {
+	Count uint64
+	// Parameters maps the observed parameters by `<in>.<name>`, header names are lower cased.
+	Parameters  map[string]bool
+	StatusCodes map[string]bool
+	// RequestContentTypes and ResponseContentTypes (by status code) hold the observed media types.
+	RequestContentTypes  map[string]bool
+	ResponseContentTypes map[string]map[string]bool
+}
+
+type CoverageStats struct {
+	Total   int
+	Covered int
+	// Percentage of the covered elements, 100 if there are no elements.
+	Percentage float64
+}
+
+type CoverageReport struct {
+	Operations   CoverageStats
+	StatusCodes  CoverageStats
+	Parameters   CoverageStats
+	ContentTypes CoverageStats
+	// UnusedOperations are the operations that were never observed (candidate dead endpoints).
+	UnusedOperations []OperationCoverage
+	// PartiallyCoveredOperations are the observed operations that have unseen status codes, parameters or content types.
+	PartiallyCoveredOperations []OperationCoverage
+}
+
+type OperationCoverage struct {
+	// Path template including the base path
+	Path   string
+	Method string
+	// Unseen lists the locations (as JSON pointers) of the status codes, parameters and content types that were
+	// never observed, e.g. /responses/404, /parameters/query/limit, /requestBody/content/application~1xml,
+	// /responses/200/content/application~1json. Not set for unused operations.
+	Unseen []string
+}
+
+// recordProvidedSpecCoverage records the telemetry observation if it matches a provided spec operation.
+// It is called when the telemetry is learned or diffed, a telemetry that is both learned and diffed
+// is counted once by its request ID.
+func (s *Spec) recordProvidedSpecCoverage(telemetry *Telemetry) error {
+	if !s.HasProvidedSpec() {
+		return nil
+	}
+	if s.ProvidedSpecCoverage.hasRequestID(telemetry.RequestID) {
+		return nil
+	}
+
+	path, _ := GetPathAndQuery(telemetry.Request.Path)
+	_, pathNoBase := s.ProvidedSpec.splitBasePath(path)
+	pathFromTrie, _, found := s.ProvidedPathTrie.GetPathAndValue(pathNoBase)
+	if !found {
+		return nil
+	}
+	pathItem := s.ProvidedSpec.GetPathItem(pathFromTrie)
+	if pathItem == nil || GetOperationFromPathItem(pathItem, telemetry.Request.Method) == nil {
+		return nil
+	}
+
+	req, err := createHTTPRequestFromTelemetry(telemetry)
+	if err != nil {
+		return fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	if s.ProvidedSpecCoverage == nil {
+		s.ProvidedSpecCoverage = &ProvidedSpecCoverage{}
+	}
+	s.ProvidedSpecCoverage.addRequestID(telemetry.RequestID)
+	observations := s.ProvidedSpecCoverage.getOperationObservations(pathFromTrie, req.Method)
+	observations.Count++
+
+	for _, name := range getPathParamNames(pathFromTrie) {
+		observations.Parameters[getCoverageParameterKey(oapi_spec.ParameterInPath, name)] = true
+	}
+	for name := range req.URL.Query() {
+		observations.Parameters[getCoverageParameterKey(oapi_spec.ParameterInQuery, name)] = true
+	}
+	for name := range req.Header {
+		observations.Parameters[getCoverageParameterKey(oapi_spec.ParameterInHeader, name)] = true
+	}
+	for _, cookie := range req.Cookies() {
+		observations.Parameters[getCoverageParameterKey(oapi_spec.ParameterInCookie, cookie.Name)] = true
+	}
+	if contentType := req.Header.Get(contentTypeHeaderName); contentType != "" {
+		observations.RequestContentTypes[contentType] = true
+	}
+
+	if telemetry.Response != nil {
+		statusCode := telemetry.Response.StatusCode
+		observations.StatusCodes[statusCode] = true
+		if contentType := createHTTPHeader(telemetry.Response.Common).Get(contentTypeHeaderName); contentType != "" {
+			if observations.ResponseContentTypes[statusCode] == nil {
+				observations.ResponseContentTypes[statusCode] = make(map[string]bool)
+			}
+			observations.ResponseContentTypes[statusCode][contentType] = true
+		}
+	}
+
+	return nil
+}
+
+func (c *ProvidedSpecCoverage) hasRequestID(requestID string) bool {
+	if c == nil || requestID == "" {
+		return false
+	}
+	for _, id := range c.RequestIDs {
+		if id == requestID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *ProvidedSpecCoverage) addRequestID(requestID string) {
+	if requestID == "" {
+		return
+	}
+	if len(c.RequestIDs) >= maxCoverageRequestIDs {
+		c.RequestIDs = c.RequestIDs[1:]
+	}
+	c.RequestIDs = append(c.RequestIDs, requestID)
+}
+
+func (c *ProvidedSpecCoverage) getOperationObservations(path, method string) *OperationObservations {
+	if c.Operations == nil {
+		c.Operations = make(map[string]map[string]*OperationObservations)
+	}
+	if c.Operations[path] == nil {
+		c.Operations[path] = make(map[string]*OperationObservations)
+	}
+	if c.Operations[path][method] == nil {
+		c.Operations[path][method] = &OperationObservations{
+			Parameters:           make(map[string]bool),
+			StatusCodes:          make(map[string]bool),
+			RequestContentTypes:  make(map[string]bool),
+			ResponseContentTypes: make(map[string]map[string]bool),
+		}
+	}
+
+	return c.Operations[path][method]
+}
+
+func getCoverageParameterKey(in, name string) string {
+	if in == oapi_spec.ParameterInHeader {
+		name = strings.ToLower(name)
+	}
+
+	return getParameterKey(in, name)
+}
+
+// GetProvidedSpecCoverage returns the coverage report of the provided spec by the observed traffic.
+func (s *Spec) GetProvidedSpecCoverage() (*CoverageReport, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.HasProvidedSpec() {
+		return nil, ErrNoProvidedSpec
+	}
+
+	report := &CoverageReport{}
+	basePath := s.ProvidedSpec.GetBasePath()
+	for _, path := range sortedPaths(s.ProvidedSpec.Doc.Paths) {
+		pathItem := s.ProvidedSpec.Doc.Paths[path]
+		operations := pathItem.Operations()
+		for _, method := range sortedUnionMethods(operations, nil) {
+			var observations *OperationObservations
+			if s.ProvidedSpecCoverage != nil {
+				observations = s.ProvidedSpecCoverage.Operations[path][method]
+			}
+			report.addOperation(addBasePathIfNeeded(basePath, path), method,
+				withPathItemParameters(pathItem, operations[method]), observations)
+		}
+	}
+
+	report.Operations.setPercentage()
+	report.StatusCodes.setPercentage()
+	report.Parameters.setPercentage()
+	report.ContentTypes.setPercentage()
+
+	return report, nil
+}
+
+func (r *CoverageReport) addOperation(path, method string, operation *oapi_spec.Operation, observations *OperationObservations) {
+	operationCoverage := OperationCoverage{
+		Path:   path,
+		Method: method,
+	}
+
+	r.Operations.Total++
+	if observations == nil {
+		r.UnusedOperations = append(r.UnusedOperations, operationCoverage)
+		observations = &OperationObservations{}
+	} else {
+		r.Operations.Covered++
+	}
+
+	for _, paramRef := range operation.Parameters {
+		if paramRef.Value == nil {
+			continue
+		}
+		location := Location{locationParameters, paramRef.Value.In, paramRef.Value.Name}.String()
+		covered := observations.Parameters[getCoverageParameterKey(paramRef.Value.In, paramRef.Value.Name)]
+		operationCoverage.Unseen = r.Parameters.add(operationCoverage.Unseen, location, covered)
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		content := operation.RequestBody.Value.Content
+		for _, mediaType := range sortedMediaTypes(content) {
+			location := Location{locationRequestBody, locationContent, mediaType}.String()
+			covered := isMediaTypeObserved(content, mediaType, observations.RequestContentTypes)
+			operationCoverage.Unseen = r.ContentTypes.add(operationCoverage.Unseen, location, covered)
+		}
+	}
+
+	for _, statusCode := range sortedStatusCodes(operation.Responses) {
+		responseLocation := Location{locationResponses, statusCode}
+		observedStatusCodes := getObservedStatusCodes(statusCode, observations.StatusCodes)
+		if statusCode != "default" {
+			operationCoverage.Unseen = r.StatusCodes.add(operationCoverage.Unseen, responseLocation.String(), len(observedStatusCodes) > 0)
+		}
+
+		response := operation.Responses[statusCode].Value
+		if response == nil {
+			continue
+		}
+		observedContentTypes := make(map[string]bool)
+		for _, observedStatusCode := range observedStatusCodes {
+			for contentType := range observations.ResponseContentTypes[observedStatusCode] {
+				observedContentTypes[contentType] = true
+			}
+		}
+		for _, mediaType := range sortedMediaTypes(response.Content) {
+			location := appendLocation(responseLocation, locationContent, mediaType).String()
+			covered := isMediaTypeObserved(response.Content, mediaType, observedContentTypes)
+			operationCoverage.Unseen = r.ContentTypes.add(operationCoverage.Unseen, location, covered)
+		}
+	}
+
+	if observations.Count > 0 && len(operationCoverage.Unseen) > 0 {
+		r.PartiallyCoveredOperations = append(r.PartiallyCoveredOperations, operationCoverage)
+	}
+}
+
+// getObservedStatusCodes returns the observed status codes that match the spec status code,
+// which can be a status code, a range (e.g. 2XX) or default (which matches all the observed status codes).
+func getObservedStatusCodes(specStatusCode string, observedStatusCodes map[string]bool) []string {
+	var ret []string
+
+	for observedStatusCode := range observedStatusCodes {
+		switch {
+		case specStatusCode == "default", specStatusCode == observedStatusCode:
+			ret = append(ret, observedStatusCode)
+		case len(specStatusCode) == 3 && strings.HasSuffix(strings.ToUpper(specStatusCode), "XX") &&
+			strings.HasPrefix(observedStatusCode, specStatusCode[:1]):
+			ret = append(ret, observedStatusCode)
+		}
+	}
+
+	return ret
+}
+
+// isMediaTypeObserved checks if any of the observed content types is matched to the media type in content
+// (including wildcards, e.g. application/*).
+func isMediaTypeObserved(content oapi_spec.Content, mediaType string, observedContentTypes map[string]bool) bool {
+	for contentType := range observedContentTypes {
+		if matched := content.Get(contentType); matched != nil && matched == content[mediaType] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add counts the element and returns unseen with the element location appended if it was not covered.
+func (c *CoverageStats) add(unseen []string, location string, covered bool) []string {
+	c.Total++
+	if covered {
+		c.Covered++
+		return unseen
+	}
+
+	return append(unseen, location)
+}
+
+func (c *CoverageStats) setPercentage() {
+	if c.Total == 0 {
+		c.Percentage = 100 // nolint:gomnd
+		return
+	}
+
+	c.Percentage = float64(c.Covered) * 100 / float64(c.Total) // nolint:gomnd
+}
+
+func sortedPaths(paths oapi_spec.Paths) []string {
+	ret := make([]string, 0, len(paths))
+	for path := range paths {
+		ret = append(ret, path)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func sortedMediaTypes(content oapi_spec.Content) []string {
+	ret := make([]string, 0, len(content))
+	for mediaType := range content {
+		ret = append(ret, mediaType)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func sortedStatusCodes(responses oapi_spec.Responses) []string {
+	ret := make([]string, 0, len(responses))
+	for statusCode := range responses {
+		ret = append(ret, statusCode)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
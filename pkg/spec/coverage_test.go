@@ -0,0 +1,141 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSpec_GetProvidedSpecCoverage(t *testing.T) {
+	tests := []struct {
+		name             string
+		learnTelemetries []*Telemetry
+		diffTelemetries  []*Telemetry
+		want             *CoverageReport
+	}{
+		{
+			name: "no traffic",
+			want: &CoverageReport{
+				Operations:   CoverageStats{Total: 2, Covered: 0, Percentage: 0},
+				StatusCodes:  CoverageStats{Total: 2, Covered: 0, Percentage: 0},
+				Parameters:   CoverageStats{Total: 3, Covered: 0, Percentage: 0},
+				ContentTypes: CoverageStats{Total: 2, Covered: 0, Percentage: 0},
+				UnusedOperations: []OperationCoverage{
+					{Path: "/api/pets/{petId}", Method: http.MethodGet},
+					{Path: "/api/pets/{petId}", Method: http.MethodPut},
+				},
+			},
+		},
+		{
+			name: "learned operation without query param, undocumented path is ignored",
+			learnTelemetries: []*Telemetry{
+				createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"name": "rex"}`),
+				createTelemetry("2", http.MethodGet, "/api/owners/1", "host", "200", "", `{"name": "bob"}`),
+			},
+			want: &CoverageReport{
+				Operations:   CoverageStats{Total: 2, Covered: 1, Percentage: 50},
+				StatusCodes:  CoverageStats{Total: 2, Covered: 1, Percentage: 50},
+				Parameters:   CoverageStats{Total: 3, Covered: 1, Percentage: 100.0 / 3},
+				ContentTypes: CoverageStats{Total: 2, Covered: 1, Percentage: 50},
+				UnusedOperations: []OperationCoverage{
+					{Path: "/api/pets/{petId}", Method: http.MethodPut},
+				},
+				PartiallyCoveredOperations: []OperationCoverage{
+					{Path: "/api/pets/{petId}", Method: http.MethodGet, Unseen: []string{"/parameters/query/status"}},
+				},
+			},
+		},
+		{
+			name: "learned and diffed operations",
+			learnTelemetries: []*Telemetry{
+				createTelemetry("1", http.MethodGet, "/api/pets/1?status=sold", "host", "200", "", `{"name": "rex"}`),
+			},
+			diffTelemetries: []*Telemetry{
+				createTelemetry("2", http.MethodPut, "/api/pets/2", "host", "204", `{"name": "rex"}`, ""),
+			},
+			want: &CoverageReport{
+				Operations:   CoverageStats{Total: 2, Covered: 2, Percentage: 100},
+				StatusCodes:  CoverageStats{Total: 2, Covered: 2, Percentage: 100},
+				Parameters:   CoverageStats{Total: 3, Covered: 3, Percentage: 100},
+				ContentTypes: CoverageStats{Total: 2, Covered: 2, Percentage: 100},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+			assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{"/pets/{petId}": "1"}))
+			for _, telemetry := range tt.learnTelemetries {
+				assert.NilError(t, s.LearnTelemetry(telemetry))
+			}
+			for _, telemetry := range tt.diffTelemetries {
+				_, err := s.DiffTelemetry(telemetry, SpecSourceProvided)
+				assert.NilError(t, err)
+			}
+
+			got, err := s.GetProvidedSpecCoverage()
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestSpec_ProvidedSpecCoverage_LearnAndDiff(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{"/pets/{petId}": "1"}))
+
+	telemetry := createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"name": "rex"}`)
+	assert.NilError(t, s.LearnTelemetry(telemetry))
+	_, err := s.DiffTelemetry(telemetry, SpecSourceProvided)
+	assert.NilError(t, err)
+
+	assert.Equal(t, s.ProvidedSpecCoverage.Operations["/pets/{petId}"][http.MethodGet].Count, uint64(1))
+
+	// a new provided spec starts without coverage
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{"/pets/{petId}": "1"}))
+	assert.Assert(t, s.ProvidedSpecCoverage == nil)
+}
+
+func TestSpec_GetProvidedSpecCoverage_NoProvidedSpec(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	_, err := s.GetProvidedSpecCoverage()
+	assert.Assert(t, errors.Is(err, ErrNoProvidedSpec))
+}
+
+func Test_getObservedStatusCodes(t *testing.T) {
+	observedStatusCodes := map[string]bool{"200": true}
+	tests := []struct {
+		name           string
+		specStatusCode string
+		want           int
+	}{
+		{name: "status code", specStatusCode: "200", want: 1},
+		{name: "other status code", specStatusCode: "201", want: 0},
+		{name: "range", specStatusCode: "2XX", want: 1},
+		{name: "lower case range", specStatusCode: "2xx", want: 1},
+		{name: "other range", specStatusCode: "4XX", want: 0},
+		{name: "default", specStatusCode: "default", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, len(getObservedStatusCodes(tt.specStatusCode, observedStatusCodes)), tt.want)
+		})
+	}
+}
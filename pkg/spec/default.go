@@ -56,3 +56,20 @@ func createDefaultSwaggerInfo() *spec.Info {
 		Version: "1.0.0",
 	}
 }
+
+// createDefaultServers returns a server for each of the schemes with the host and port, http is used if there are no schemes.
+func createDefaultServers(host, port string, schemes []string) spec.Servers {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+
+	servers := make(spec.Servers, 0, len(schemes))
+	for _, scheme := range schemes {
+		servers = append(servers, &spec.Server{
+			// https://swagger.io/docs/specification/api-host-and-base-path/
+			URL: scheme + "://" + host + ":" + port,
+		})
+	}
+
+	return servers
+}
@@ -0,0 +1,243 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	spec "github.com/getkin/kin-openapi/openapi3"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DeprecationExtensionName holds the operation deprecation date (RFC 3339), learned from the Deprecation response header.
+	DeprecationExtensionName = "x-deprecation"
+	// SunsetExtensionName holds the operation sunset date (RFC 3339), learned from the Sunset response header.
+	SunsetExtensionName = "x-sunset"
+
+	// https://datatracker.ietf.org/doc/html/rfc7234#section-5.5.7
+	warningCodeMiscellaneousPersistent = "299"
+)
+
+// setOperationDeprecation marks the operation as deprecated if the response has a Deprecation header
+// (https://datatracker.ietf.org/doc/html/rfc9745), a Sunset header (https://datatracker.ietf.org/doc/html/rfc8594)
+// or a 299 Warning header, and records the deprecation and sunset dates.
+func setOperationDeprecation(operation *spec.Operation, respHeaders map[string]string) {
+	if value, ok := respHeaders[deprecationHeaderName]; ok {
+		deprecated, deprecation, err := parseDeprecationHeader(value)
+		if err != nil {
+			log.Warnf("Failed to parse deprecation header %q: %v", value, err)
+		} else if deprecated {
+			operation.Deprecated = true
+			if !deprecation.IsZero() {
+				setOperationDateExtension(operation, DeprecationExtensionName, deprecation)
+			}
+		}
+	}
+
+	if value, ok := respHeaders[warningHeaderName]; ok && hasWarningCode(value, warningCodeMiscellaneousPersistent) {
+		operation.Deprecated = true
+	}
+
+	if value, ok := respHeaders[sunsetHeaderName]; ok {
+		operation.Deprecated = true
+		sunset, err := http.ParseTime(strings.TrimSpace(value))
+		if err != nil {
+			log.Warnf("Failed to parse sunset header %q: %v", value, err)
+			return
+		}
+		setOperationDateExtension(operation, SunsetExtensionName, sunset)
+	}
+}
+
+// parseDeprecationHeader parses a Deprecation header value, which is a structured field date (e.g. @1688169599)
+// as defined by RFC 9745, or an HTTP date or a boolean as in the earlier drafts.
+// The returned date is zero if the header has no date.
+func parseDeprecationHeader(value string) (deprecated bool, deprecation time.Time, err error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.EqualFold(value, "true"):
+		return true, time.Time{}, nil
+	case strings.EqualFold(value, "false"):
+		return false, time.Time{}, nil
+	case strings.HasPrefix(value, "@"):
+		seconds, err := strconv.ParseInt(strings.TrimPrefix(value, "@"), 10, 64)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid date: %w", err)
+		}
+		return true, time.Unix(seconds, 0).UTC(), nil
+	}
+
+	deprecation, err = http.ParseTime(value)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid date: %w", err)
+	}
+
+	return true, deprecation.UTC(), nil
+}
+
+// hasWarningCode checks if any of the (comma separated) warnings has the warn code,
+// e.g. 299 - "Deprecated API", 110 - "Response is Stale".
+func hasWarningCode(value, code string) bool {
+	for _, warning := range strings.Split(value, ",") {
+		if strings.HasPrefix(strings.TrimSpace(warning), code+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func setOperationDateExtension(operation *spec.Operation, name string, date time.Time) {
+	dateB, err := json.Marshal(date.UTC().Format(time.RFC3339))
+	if err != nil {
+		log.Errorf("Failed to marshal %v: %v", name, err)
+		return
+	}
+
+	if operation.Extensions == nil {
+		operation.Extensions = make(map[string]interface{})
+	}
+	operation.Extensions[name] = json.RawMessage(dateB)
+}
+
+func getOperationDateExtension(operation *spec.Operation, name string) (time.Time, bool) {
+	if operation == nil {
+		return time.Time{}, false
+	}
+
+	var date string
+	switch value := operation.Extensions[name].(type) {
+	case string:
+		date = value
+	case json.RawMessage:
+		if err := json.Unmarshal(value, &date); err != nil {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	ret, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return ret, true
+}
+
+func setOperationSunset(operation *spec.Operation, sunset time.Time) {
+	setOperationDateExtension(operation, SunsetExtensionName, sunset)
+}
+
+// GetOperationSunset returns the operation sunset date, if set.
+func GetOperationSunset(operation *spec.Operation) (time.Time, bool) {
+	return getOperationDateExtension(operation, SunsetExtensionName)
+}
+
+// GetOperationDeprecation returns the date the operation is deprecated from, if set.
+// A deprecated operation without a deprecation date is deprecated regardless of the time.
+func GetOperationDeprecation(operation *spec.Operation) (time.Time, bool) {
+	return getOperationDateExtension(operation, DeprecationExtensionName)
+}
+
+// isOperationDeprecated checks if the operation is deprecated at time t.
+func isOperationDeprecated(operation *spec.Operation, t time.Time) bool {
+	if !operation.Deprecated {
+		return false
+	}
+	deprecation, ok := GetOperationDeprecation(operation)
+
+	return !ok || !t.Before(deprecation)
+}
+
+// mergeOperationDeprecation sets the merged operation as deprecated if any of the operations is deprecated,
+// with the earliest deprecation and sunset dates.
+func mergeOperationDeprecation(merged, operation, operation2 *spec.Operation) {
+	merged.Deprecated = operation.Deprecated || operation2.Deprecated
+	delete(merged.Extensions, DeprecationExtensionName)
+	if deprecation, ok := getEarliestDeprecation(operation, operation2); ok {
+		setOperationDateExtension(merged, DeprecationExtensionName, deprecation)
+	}
+
+	sunset, ok := GetOperationSunset(operation)
+	if sunset2, ok2 := GetOperationSunset(operation2); ok2 && (!ok || sunset2.Before(sunset)) {
+		sunset, ok = sunset2, true
+	}
+	if ok {
+		setOperationSunset(merged, sunset)
+	}
+}
+
+// getEarliestDeprecation returns the earliest deprecation date of the deprecated operations.
+// There is no date if one of them is deprecated without a deprecation date.
+func getEarliestDeprecation(operations ...*spec.Operation) (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	for _, operation := range operations {
+		if !operation.Deprecated {
+			continue
+		}
+		deprecation, ok := GetOperationDeprecation(operation)
+		if !ok {
+			return time.Time{}, false
+		}
+		if !found || deprecation.Before(earliest) {
+			earliest, found = deprecation, true
+		}
+	}
+
+	return earliest, found
+}
+
+// isDeprecationChange checks if the change is of the deprecation removed from the telemetry operation
+// (see clearOperationDeprecation), which is not a change of the API.
+func isDeprecationChange(change DiffChange) bool {
+	if change.Kind != DiffChangeKindRemoved || len(change.Location) != 1 {
+		return false
+	}
+	switch change.Location[0] {
+	case "deprecated", DeprecationExtensionName, SunsetExtensionName:
+		return true
+	}
+
+	return false
+}
+
+// filterDeprecationChanges removes the deprecation changes (see isDeprecationChange) from the changes.
+func filterDeprecationChanges(changes []DiffChange) []DiffChange {
+	var ret []DiffChange
+	for _, change := range changes {
+		if !isDeprecationChange(change) {
+			ret = append(ret, change)
+		}
+	}
+
+	return ret
+}
+
+// clearOperationDeprecation clears the deprecation learned from the telemetry response headers,
+// so it is not compared with the spec operation.
+func clearOperationDeprecation(operation *spec.Operation) {
+	operation.Deprecated = false
+	delete(operation.Extensions, DeprecationExtensionName)
+	delete(operation.Extensions, SunsetExtensionName)
+}
@@ -0,0 +1,235 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+func Test_setOperationDeprecation(t *testing.T) {
+	sunset := time.Date(2021, time.November, 11, 23, 59, 59, 0, time.UTC)
+	tests := []struct {
+		name            string
+		respHeaders     map[string]string
+		wantDeprecated  bool
+		wantDeprecation time.Time
+		wantSunset      bool
+	}{
+		{
+			name:        "no deprecation headers",
+			respHeaders: map[string]string{"x-request-id": "1"},
+		},
+		{
+			name:            "deprecation header",
+			respHeaders:     map[string]string{deprecationHeaderName: "@1688169599"},
+			wantDeprecated:  true,
+			wantDeprecation: time.Date(2023, time.June, 30, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:            "deprecation header http date",
+			respHeaders:     map[string]string{deprecationHeaderName: "Thu, 11 Nov 2021 23:59:59 GMT"},
+			wantDeprecated:  true,
+			wantDeprecation: sunset,
+		},
+		{
+			name:           "deprecation header true",
+			respHeaders:    map[string]string{deprecationHeaderName: "true"},
+			wantDeprecated: true,
+		},
+		{
+			name:        "deprecation header false",
+			respHeaders: map[string]string{deprecationHeaderName: "false"},
+		},
+		{
+			name:        "invalid deprecation header",
+			respHeaders: map[string]string{deprecationHeaderName: "@tomorrow"},
+		},
+		{
+			name:           "warning header 299",
+			respHeaders:    map[string]string{warningHeaderName: `110 - "Response is Stale", 299 - "Deprecated API"`},
+			wantDeprecated: true,
+		},
+		{
+			name:        "other warning header",
+			respHeaders: map[string]string{warningHeaderName: `110 - "Response is Stale"`},
+		},
+		{
+			name:           "sunset header",
+			respHeaders:    map[string]string{sunsetHeaderName: "Thu, 11 Nov 2021 23:59:59 GMT"},
+			wantDeprecated: true,
+			wantSunset:     true,
+		},
+		{
+			name:           "invalid sunset header",
+			respHeaders:    map[string]string{sunsetHeaderName: "tomorrow"},
+			wantDeprecated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := oapi_spec.NewOperation()
+			setOperationDeprecation(operation, tt.respHeaders)
+			assert.Equal(t, operation.Deprecated, tt.wantDeprecated)
+
+			gotDeprecation, ok := GetOperationDeprecation(operation)
+			assert.Equal(t, ok, !tt.wantDeprecation.IsZero())
+			if ok {
+				assert.Assert(t, gotDeprecation.Equal(tt.wantDeprecation))
+			}
+
+			gotSunset, ok := GetOperationSunset(operation)
+			assert.Equal(t, ok, tt.wantSunset)
+			if tt.wantSunset {
+				assert.Assert(t, gotSunset.Equal(sunset))
+			}
+		})
+	}
+}
+
+func Test_mergeOperationDeprecation(t *testing.T) {
+	early := time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2021, time.December, 1, 0, 0, 0, 0, time.UTC)
+	createOperation := func(deprecated bool, sunset *time.Time) *oapi_spec.Operation {
+		operation := oapi_spec.NewOperation()
+		operation.Deprecated = deprecated
+		if sunset != nil {
+			setOperationSunset(operation, *sunset)
+		}
+		return operation
+	}
+
+	tests := []struct {
+		name           string
+		operation      *oapi_spec.Operation
+		operation2     *oapi_spec.Operation
+		wantDeprecated bool
+		wantSunset     *time.Time
+	}{
+		{
+			name:       "not deprecated",
+			operation:  createOperation(false, nil),
+			operation2: createOperation(false, nil),
+		},
+		{
+			name:           "second operation deprecated",
+			operation:      createOperation(false, nil),
+			operation2:     createOperation(true, &late),
+			wantDeprecated: true,
+			wantSunset:     &late,
+		},
+		{
+			name:           "earliest sunset",
+			operation:      createOperation(true, &early),
+			operation2:     createOperation(true, &late),
+			wantDeprecated: true,
+			wantSunset:     &early,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, _ := mergeOperation(tt.operation, tt.operation2)
+			assert.Equal(t, merged.Deprecated, tt.wantDeprecated)
+
+			gotSunset, ok := GetOperationSunset(merged)
+			assert.Equal(t, ok, tt.wantSunset != nil)
+			if tt.wantSunset != nil {
+				assert.Assert(t, gotSunset.Equal(*tt.wantSunset))
+			}
+		})
+	}
+}
+
+func TestSpec_DiffTelemetry_ReconstructedZombie(t *testing.T) {
+	sunset := time.Date(2021, time.November, 11, 23, 59, 59, 0, time.UTC)
+	telemetry := createTelemetry("1", http.MethodGet, "/api/pets", "host", "200", "", `{"name": "rex"}`)
+	telemetry.Response.Common.Headers = append(telemetry.Response.Common.Headers,
+		&Header{Key: "Sunset", Value: "Thu, 11 Nov 2021 23:59:59 GMT"})
+
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LearnTelemetry(telemetry))
+	approveSuggestedReview(t, s)
+	assert.Assert(t, s.ApprovedSpec.GetPathItem("/api/pets").Get.Deprecated)
+
+	tests := []struct {
+		name           string
+		time           time.Time
+		wantPastSunset bool
+		wantSeverity   DiffSeverity
+	}{
+		{
+			name:         "before sunset",
+			time:         sunset.Add(-time.Hour),
+			wantSeverity: DiffSeverityNonBreaking,
+		},
+		{
+			name:           "after sunset",
+			time:           sunset.Add(time.Hour),
+			wantPastSunset: true,
+			wantSeverity:   DiffSeverityBreaking,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiDiff, err := s.DiffTelemetry(telemetry, SpecSourceReconstructed, WithDiffTime(tt.time))
+			assert.NilError(t, err)
+			assert.Equal(t, apiDiff.Type, DiffTypeZombieDiff)
+			assert.Equal(t, apiDiff.PastSunset, tt.wantPastSunset)
+			assert.Equal(t, apiDiff.Severity, tt.wantSeverity)
+		})
+	}
+}
+
+func TestSpec_DiffTelemetry_DeprecationDate(t *testing.T) {
+	deprecation := time.Date(2023, time.June, 30, 23, 59, 59, 0, time.UTC)
+	telemetry := createTelemetry("1", http.MethodGet, "/api/pets", "host", "200", "", `{"name": "rex"}`)
+	telemetry.Response.Common.Headers = append(telemetry.Response.Common.Headers,
+		&Header{Key: "Deprecation", Value: "@1688169599"})
+
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LearnTelemetry(telemetry))
+	approveSuggestedReview(t, s)
+
+	tests := []struct {
+		name     string
+		time     time.Time
+		wantType DiffType
+	}{
+		{
+			name:     "before deprecation",
+			time:     deprecation.Add(-time.Hour),
+			wantType: DiffTypeNoDiff,
+		},
+		{
+			name:     "after deprecation",
+			time:     deprecation.Add(time.Hour),
+			wantType: DiffTypeZombieDiff,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiDiff, err := s.DiffTelemetry(telemetry, SpecSourceReconstructed, WithDiffTime(tt.time))
+			assert.NilError(t, err)
+			assert.Equal(t, apiDiff.Type, tt.wantType)
+			// the spec deprecation is not a change of the API
+			assert.Equal(t, len(apiDiff.Changes), 0)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	oapi_spec "github.com/getkin/kin-openapi/openapi3"
 	uuid "github.com/satori/go.uuid"
@@ -41,8 +42,24 @@ type APIDiff struct {
 	Path             string
 	OriginalPathItem *oapi_spec.PathItem
 	ModifiedPathItem *oapi_spec.PathItem
-	InteractionID    uuid.UUID
-	SpecID           uuid.UUID
+	// Changes lists the field-level changes between the original and the modified operations,
+	// set only for general and zombie diffs.
+	Changes []DiffChange
+	// Severity is breaking if any of the changes is breaking for the API consumer or provider,
+	// set only for general and zombie diffs.
+	Severity DiffSeverity
+	// PastSunset is set for zombie diffs of operations that their sunset date has passed,
+	// the severity of these diffs is escalated to breaking.
+	PastSunset bool
+	// ValidationFindings lists the telemetry values that are not valid according to the matched provided spec
+	// operation, set only when diffing the provided spec with WithValueValidation.
+	// The findings do not affect the diff type.
+	ValidationFindings []ValidationFinding
+	// Server is the provided spec server URL (with resolved server variables) whose base path matched the
+	// telemetry path, set only when diffing the provided spec.
+	Server        string
+	InteractionID uuid.UUID
+	SpecID        uuid.UUID
 }
 
 type operationDiff struct {
@@ -56,6 +73,38 @@ type DiffParams struct {
 	path      string
 	requestID string
 	response  *Response
+	mode      DiffMode
+	// validateValues will validate the telemetry values against the provided spec
+	validateValues bool
+	// suppressionRules are applied on the diff
+	suppressionRules []SuppressionRule
+	// time of the telemetry, compared with the operation deprecation and sunset dates
+	time time.Time
+}
+
+type DiffOption func(*DiffParams)
+
+// WithDiffMode sets the diff mode, DiffModeExact is used by default.
+func WithDiffMode(mode DiffMode) DiffOption {
+	return func(params *DiffParams) {
+		params.mode = mode
+	}
+}
+
+// WithValueValidation validates the telemetry request and response values (parameters, bodies, content type and
+// status code) against the provided spec operation, in addition to the shape diff.
+func WithValueValidation() DiffOption {
+	return func(params *DiffParams) {
+		params.validateValues = true
+	}
+}
+
+// WithDiffTime sets the time of the telemetry, which is compared with the operation deprecation and sunset dates.
+// The current time is used by default.
+func WithDiffTime(t time.Time) DiffOption {
+	return func(params *DiffParams) {
+		params.time = t
+	}
 }
 
 func (s *Spec) createDiffParamsFromTelemetry(telemetry *Telemetry) (*DiffParams, error) {
@@ -72,10 +121,12 @@ func (s *Spec) createDiffParamsFromTelemetry(telemetry *Telemetry) (*DiffParams,
 		path:      path,
 		requestID: telemetry.RequestID,
 		response:  telemetry.Response,
+		mode:      DiffModeExact,
+		time:      time.Now(),
 	}, nil
 }
 
-func (s *Spec) DiffTelemetry(telemetry *Telemetry, specSource SpecSource) (*APIDiff, error) {
+func (s *Spec) DiffTelemetry(telemetry *Telemetry, specSource SpecSource, opts ...DiffOption) (*APIDiff, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -85,6 +136,9 @@ func (s *Spec) DiffTelemetry(telemetry *Telemetry, specSource SpecSource) (*APID
 	if err != nil {
 		return nil, fmt.Errorf("failed to create diff params from telemetry. %w", err)
 	}
+	for _, opt := range opts {
+		opt(diffParams)
+	}
 
 	switch specSource {
 	case SpecSourceProvided:
@@ -96,6 +150,15 @@ func (s *Spec) DiffTelemetry(telemetry *Telemetry, specSource SpecSource) (*APID
 		if err != nil {
 			return nil, fmt.Errorf("failed to diff provided spec. %w", err)
 		}
+		if err := s.recordProvidedSpecCoverage(telemetry); err != nil {
+			log.Errorf("Failed to record provided spec coverage. %v", err)
+		}
+		if diffParams.validateValues {
+			apiDiff.ValidationFindings, err = s.validateTelemetry(telemetry)
+			if err != nil {
+				return nil, fmt.Errorf("failed to validate telemetry. %w", err)
+			}
+		}
 	case SpecSourceReconstructed:
 		if !s.HasApprovedSpec() {
 			log.Infof("No approved spec to diff")
@@ -109,7 +172,7 @@ func (s *Spec) DiffTelemetry(telemetry *Telemetry, specSource SpecSource) (*APID
 		return nil, fmt.Errorf("spec source: %v is not valid", specSource)
 	}
 
-	return apiDiff, nil
+	return s.applySuppressionRules(apiDiff, diffParams.method, diffParams.suppressionRules), nil
 }
 
 func (s *Spec) diffApprovedSpec(diffParams *DiffParams) (*APIDiff, error) {
@@ -125,9 +188,12 @@ func (s *Spec) diffApprovedSpec(diffParams *DiffParams) (*APIDiff, error) {
 func (s *Spec) diffProvidedSpec(diffParams *DiffParams) (*APIDiff, error) {
 	var pathItem *oapi_spec.PathItem
 
-	basePath := s.ProvidedSpec.GetBasePath()
+	var serverURL string
+	if server, found := s.ProvidedSpec.GetMatchingServer(diffParams.path); found {
+		serverURL = server.URL
+	}
 
-	pathNoBase := trimBasePathIfNeeded(basePath, diffParams.path)
+	basePath, pathNoBase := s.ProvidedSpec.splitBasePath(diffParams.path)
 
 	pathFromTrie, _, found := s.ProvidedPathTrie.GetPathAndValue(pathNoBase)
 	if found {
@@ -136,7 +202,13 @@ func (s *Spec) diffProvidedSpec(diffParams *DiffParams) (*APIDiff, error) {
 		pathItem = s.ProvidedSpec.GetPathItem(pathFromTrie)
 	}
 
-	return s.diffPathItem(pathItem, diffParams)
+	apiDiff, err := s.diffPathItem(pathItem, diffParams)
+	if err != nil {
+		return nil, err
+	}
+	apiDiff.Server = serverURL
+
+	return apiDiff, nil
 }
 
 // For path /api/foo/bar and base path of /api, the path that will be saved in paths map will be /foo/bar
@@ -188,19 +260,54 @@ func (s *Spec) diffPathItem(pathItem *oapi_spec.PathItem, diffParams *DiffParams
 		return nil, fmt.Errorf("failed to calculate operation diff: %w", err)
 	}
 	if diff != nil {
-		diffType := DiffTypeGeneralDiff
-		if specOp.Deprecated {
-			diffType = DiffTypeZombieDiff
+		changes, err := s.calculateDiffChanges(specOp, telemetryOp, diff, diffParams)
+		if err != nil {
+			return nil, err
+		}
+		// a diff without changes (e.g. in conformance mode, a diff that doesn't violate the spec) is not reported,
+		// unless it is a zombie diff
+		deprecated := isOperationDeprecated(specOp, diffParams.time)
+		if deprecated || len(changes) > 0 {
+			diffType := DiffTypeGeneralDiff
+			if deprecated {
+				diffType = DiffTypeZombieDiff
+			}
+			apiDiff := s.createAPIDiffEvent(diffType, createPathItemFromOperation(method, diff.OriginalOperation),
+				createPathItemFromOperation(method, diff.ModifiedOperation), reqUUID, path)
+			apiDiff.Changes = changes
+			apiDiff.PastSunset = isPastSunset(specOp, diffType, diffParams.time)
+			apiDiff.Severity = getAPIDiffSeverity(apiDiff)
+			return apiDiff, nil
 		}
-		apiDiff := s.createAPIDiffEvent(diffType, createPathItemFromOperation(method, diff.OriginalOperation),
-			createPathItemFromOperation(method, diff.ModifiedOperation), reqUUID, path)
-		return apiDiff, nil
 	}
 
 	// no diff
 	return s.createAPIDiffEvent(DiffTypeNoDiff, nil, nil, reqUUID, path), nil
 }
 
+// calculateDiffChanges returns all the changes between the operations in exact mode,
+// and only the changes that violate the spec operation in conformance mode.
+func (s *Spec) calculateDiffChanges(specOp, telemetryOp *oapi_spec.Operation, diff *operationDiff, diffParams *DiffParams) ([]DiffChange, error) {
+	if diffParams.mode == DiffModeConformance {
+		changes, err := s.calculateOperationViolations(specOp, telemetryOp, diffParams.response.StatusCode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate operation violations: %w", err)
+		}
+		if err := classifyDiffChanges(specOp, telemetryOp, changes); err != nil {
+			return nil, fmt.Errorf("failed to classify operation violations: %w", err)
+		}
+		return changes, nil
+	}
+
+	changes, err := calculateOperationChanges(diff.OriginalOperation, diff.ModifiedOperation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate operation changes: %w", err)
+	}
+
+	// the telemetry deprecation is cleared before the comparison, so the spec deprecation is always "removed"
+	return filterDeprecationChanges(changes), nil
+}
+
 func (s *Spec) createAPIDiffEvent(diffType DiffType, original, modified *oapi_spec.PathItem, interactionID uuid.UUID, path string) *APIDiff {
 	return &APIDiff{
 		Type:             diffType,
@@ -218,6 +325,25 @@ func createPathItemFromOperation(method string, operation *oapi_spec.Operation)
 	return &pathItem
 }
 
+// isPastSunset checks if the zombie diff is of an operation that its sunset date has passed.
+func isPastSunset(specOp *oapi_spec.Operation, diffType DiffType, t time.Time) bool {
+	if diffType != DiffTypeZombieDiff {
+		return false
+	}
+	sunset, ok := GetOperationSunset(specOp)
+
+	return ok && t.After(sunset)
+}
+
+// getAPIDiffSeverity returns breaking for diffs past the operation sunset date, and the changes severity otherwise.
+func getAPIDiffSeverity(apiDiff *APIDiff) DiffSeverity {
+	if apiDiff.PastSunset {
+		return DiffSeverityBreaking
+	}
+
+	return getDiffSeverity(apiDiff.Changes)
+}
+
 func calculateOperationDiff(specOp, telemetryOp *oapi_spec.Operation, telemetryResponse *Response) (*operationDiff, error) {
 	clonedTelemetryOp, err := CloneOperation(telemetryOp)
 	if err != nil {
@@ -229,6 +355,8 @@ func calculateOperationDiff(specOp, telemetryOp *oapi_spec.Operation, telemetryR
 		return nil, fmt.Errorf("failed to clone spec operation: %w", err)
 	}
 
+	clearOperationDeprecation(clonedTelemetryOp)
+	copyOperationInfo(clonedTelemetryOp, clonedSpecOp)
 	clonedTelemetryOp = sortParameters(clonedTelemetryOp)
 	clonedSpecOp = sortParameters(clonedSpecOp)
 
@@ -292,12 +420,16 @@ func sortParameters(operation *oapi_spec.Operation) *oapi_spec.Operation {
 	if operation == nil {
 		return operation
 	}
-	sort.Slice(operation.Parameters, func(i, j int) bool {
-		right := operation.Parameters[i].Value
-		left := operation.Parameters[j].Value
+	sortParameterRefs(operation.Parameters)
+
+	return operation
+}
+
+func sortParameterRefs(parameters oapi_spec.Parameters) {
+	sort.Slice(parameters, func(i, j int) bool {
+		right := parameters[i].Value
+		left := parameters[j].Value
 		// Sibling parameters must have unique name + in values
 		return right.Name+right.In < left.Name+left.In
 	})
-
-	return operation
 }
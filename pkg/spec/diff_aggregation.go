@@ -0,0 +1,248 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxDiffGroupSampleRequestIDs = 10
+	// maxDiffGroups limits the number of diff groups of a spec, the least recently seen group is evicted
+	// for a new group above the limit.
+	maxDiffGroups = 1000
+	// maxShadowDiffPaths limits the number of shadow diff paths that are kept to parameterize the shadow diff groups paths.
+	maxShadowDiffPaths = 1000
+)
+
+type DiffGroupStatus string
+
+const (
+	DiffGroupStatusNew     DiffGroupStatus = "NEW"
+	DiffGroupStatusChanged DiffGroupStatus = "CHANGED"
+)
+
+// DiffGroup aggregates the diffs of a spec with the same source, path, method and change fingerprint.
+// The path of shadow diffs is parameterized, see getDiffGroupPath.
+type DiffGroup struct {
+	Fingerprint string
+	SpecSource  SpecSource
+	Type        DiffType
+	Path        string
+	Method      string
+	// Changes and Severity are taken from the last diff of the group.
+	Changes   []DiffChange
+	Severity  DiffSeverity
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Count     uint64
+	// SampleRequestIDs holds the first request IDs of the group.
+	SampleRequestIDs []string
+	// ChangesHash is the hash of the changes values, the group is changed when the hash is changed.
+	ChangesHash string
+}
+
+// DiffGroupEvent is emitted when a diff group is new or changed.
+type DiffGroupEvent struct {
+	Status DiffGroupStatus
+	Group  DiffGroup
+}
+
+// DiffTelemetryAggregated diffs the telemetry (see DiffTelemetry) and aggregates the diff into the spec diff groups.
+// Returns an event only if the diff group is new or its changes were changed, and nil otherwise (including no diff).
+func (s *Spec) DiffTelemetryAggregated(telemetry *Telemetry, specSource SpecSource, opts ...DiffOption) (*DiffGroupEvent, error) {
+	apiDiff, err := s.DiffTelemetry(telemetry, specSource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.aggregateDiff(apiDiff, specSource, telemetry.Request.Method, telemetry.RequestID, time.Now())
+}
+
+// GetDiffGroups returns the spec diff groups sorted by path, method and first seen time.
+func (s *Spec) GetDiffGroups() []DiffGroup {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ret := make([]DiffGroup, 0, len(s.DiffGroups))
+	for _, group := range s.DiffGroups {
+		ret = append(ret, copyDiffGroup(group))
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Path != ret[j].Path {
+			return ret[i].Path < ret[j].Path
+		}
+		if ret[i].Method != ret[j].Method {
+			return ret[i].Method < ret[j].Method
+		}
+		return ret[i].FirstSeen.Before(ret[j].FirstSeen)
+	})
+
+	return ret
+}
+
+func (s *Spec) aggregateDiff(apiDiff *APIDiff, specSource SpecSource, method, requestID string, now time.Time) (*DiffGroupEvent, error) {
+	if apiDiff == nil || apiDiff.Type == DiffTypeNoDiff {
+		return nil, nil
+	}
+
+	path := s.getDiffGroupPath(apiDiff, method)
+	fingerprint, err := getDiffFingerprint(apiDiff, specSource, path, method)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff fingerprint: %w", err)
+	}
+	changesHash, err := getHash(apiDiff.Changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes hash: %w", err)
+	}
+
+	if s.DiffGroups == nil {
+		s.DiffGroups = make(map[string]*DiffGroup)
+	}
+
+	group, ok := s.DiffGroups[fingerprint]
+	if !ok {
+		if len(s.DiffGroups) >= maxDiffGroups {
+			s.evictLeastRecentlySeenDiffGroup()
+		}
+		group = &DiffGroup{
+			Fingerprint: fingerprint,
+			SpecSource:  specSource,
+			Type:        apiDiff.Type,
+			Path:        path,
+			Method:      method,
+			Changes:     apiDiff.Changes,
+			Severity:    apiDiff.Severity,
+			FirstSeen:   now,
+			LastSeen:    now,
+			Count:       1,
+			ChangesHash: changesHash,
+		}
+		group.addSampleRequestID(requestID)
+		s.DiffGroups[fingerprint] = group
+		return &DiffGroupEvent{Status: DiffGroupStatusNew, Group: copyDiffGroup(group)}, nil
+	}
+
+	group.LastSeen = now
+	group.Count++
+	group.addSampleRequestID(requestID)
+	if group.ChangesHash == changesHash {
+		return nil, nil
+	}
+
+	group.Changes = apiDiff.Changes
+	group.Severity = apiDiff.Severity
+	group.ChangesHash = changesHash
+	return &DiffGroupEvent{Status: DiffGroupStatusChanged, Group: copyDiffGroup(group)}, nil
+}
+
+func (g *DiffGroup) addSampleRequestID(requestID string) {
+	if len(g.SampleRequestIDs) < maxDiffGroupSampleRequestIDs {
+		g.SampleRequestIDs = append(g.SampleRequestIDs, requestID)
+	}
+}
+
+func (s *Spec) evictLeastRecentlySeenDiffGroup() {
+	var evicted *DiffGroup
+	for _, group := range s.DiffGroups {
+		if evicted == nil || group.LastSeen.Before(evicted.LastSeen) {
+			evicted = group
+		}
+	}
+	if evicted == nil {
+		return
+	}
+
+	log.Warnf("Diff groups limit (%v) was reached, evicting the least recently seen group. path=%v, method=%v, last seen=%v",
+		maxDiffGroups, evicted.Path, evicted.Method, evicted.LastSeen)
+	delete(s.DiffGroups, evicted.Fingerprint)
+}
+
+// getDiffGroupPath returns the path of the diff group. Shadow diffs have the telemetry path, so it is parameterized
+// with the observed shadow diff paths the same way as the learned paths on review (e.g. /pets/1 -> /pets/{petId}),
+// to aggregate the calls that differ only by their path params.
+func (s *Spec) getDiffGroupPath(apiDiff *APIDiff, method string) string {
+	if apiDiff.Type != DiffTypeShadowDiff {
+		return apiDiff.Path
+	}
+
+	if s.ShadowDiffPaths == nil {
+		s.ShadowDiffPaths = make(map[string]map[string]bool)
+	}
+	if _, ok := s.ShadowDiffPaths[apiDiff.Path]; !ok && len(s.ShadowDiffPaths) >= maxShadowDiffPaths {
+		log.Debugf("Shadow diff paths limit (%v) was reached, path %v is parameterized by itself", maxShadowDiffPaths, apiDiff.Path)
+		return createParameterizedPath(apiDiff.Path)
+	}
+	if s.ShadowDiffPaths[apiDiff.Path] == nil {
+		s.ShadowDiffPaths[apiDiff.Path] = make(map[string]bool)
+	}
+	s.ShadowDiffPaths[apiDiff.Path][method] = true
+
+	pathItems := make(map[string]*oapi_spec.PathItem, len(s.ShadowDiffPaths))
+	for path, methods := range s.ShadowDiffPaths {
+		pathItem := &oapi_spec.PathItem{}
+		for pathMethod := range methods {
+			pathItem.SetOperation(pathMethod, &oapi_spec.Operation{})
+		}
+		pathItems[path] = pathItem
+	}
+	for parametrizedPath, paths := range s.createParametrizedPaths(pathItems) {
+		if paths[apiDiff.Path] {
+			return parametrizedPath
+		}
+	}
+
+	return createParameterizedPath(apiDiff.Path)
+}
+
+// getDiffFingerprint returns a hash of the diff source, type, group path, method, and the changes locations and kinds.
+func getDiffFingerprint(apiDiff *APIDiff, specSource SpecSource, path, method string) (string, error) {
+	changes := make([]string, 0, len(apiDiff.Changes))
+	for _, change := range apiDiff.Changes {
+		changes = append(changes, string(change.Kind)+":"+change.Location.String())
+	}
+
+	return getHash([]interface{}{specSource, apiDiff.Type, path, method, changes})
+}
+
+func getHash(obj interface{}) (string, error) {
+	objB, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal object: %w", err)
+	}
+
+	hash := sha256.Sum256(objB)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func copyDiffGroup(group *DiffGroup) DiffGroup {
+	ret := *group
+	ret.Changes = append([]DiffChange(nil), group.Changes...)
+	ret.SampleRequestIDs = append([]string(nil), group.SampleRequestIDs...)
+
+	return ret
+}
@@ -0,0 +1,271 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+func TestSpec_aggregateDiff(t *testing.T) {
+	typeChange := DiffChange{
+		Location: Location{"responses", "200", "content", "application/json", "schema", "properties", "age", "type"},
+		Kind:     DiffChangeKindTypeChanged,
+		OldValue: "integer",
+		NewValue: "string",
+	}
+	otherTypeChange := typeChange
+	otherTypeChange.NewValue = "boolean"
+	addedParam := DiffChange{
+		Location: Location{"parameters", "query", "limit"},
+		Kind:     DiffChangeKindAdded,
+	}
+	firstSeen := time.Unix(1000, 0)
+
+	type aggregatedDiff struct {
+		apiDiff   *APIDiff
+		method    string
+		requestID string
+	}
+	tests := []struct {
+		name       string
+		diffs      []aggregatedDiff
+		wantEvents []*DiffGroupEvent
+		wantGroups int
+	}{
+		{
+			name: "no diff is not aggregated",
+			diffs: []aggregatedDiff{
+				{apiDiff: &APIDiff{Type: DiffTypeNoDiff, Path: "/pets"}, method: http.MethodGet, requestID: "1"},
+			},
+			wantEvents: []*DiffGroupEvent{nil},
+			wantGroups: 0,
+		},
+		{
+			name: "same changes are emitted once",
+			diffs: []aggregatedDiff{
+				{apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{typeChange}}, method: http.MethodGet, requestID: "1"},
+				{apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{typeChange}}, method: http.MethodGet, requestID: "2"},
+			},
+			wantEvents: []*DiffGroupEvent{
+				{
+					Status: DiffGroupStatusNew,
+					Group: DiffGroup{
+						SpecSource: SpecSourceProvided, Type: DiffTypeGeneralDiff, Path: "/pets", Method: http.MethodGet,
+						Changes: []DiffChange{typeChange}, FirstSeen: firstSeen, LastSeen: firstSeen, Count: 1,
+						SampleRequestIDs: []string{"1"},
+					},
+				},
+				nil,
+			},
+			wantGroups: 1,
+		},
+		{
+			name: "changed values are emitted as changed",
+			diffs: []aggregatedDiff{
+				{apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{typeChange}}, method: http.MethodGet, requestID: "1"},
+				{apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{otherTypeChange}}, method: http.MethodGet, requestID: "2"},
+			},
+			wantEvents: []*DiffGroupEvent{
+				{
+					Status: DiffGroupStatusNew,
+					Group: DiffGroup{
+						SpecSource: SpecSourceProvided, Type: DiffTypeGeneralDiff, Path: "/pets", Method: http.MethodGet,
+						Changes: []DiffChange{typeChange}, FirstSeen: firstSeen, LastSeen: firstSeen, Count: 1,
+						SampleRequestIDs: []string{"1"},
+					},
+				},
+				{
+					Status: DiffGroupStatusChanged,
+					Group: DiffGroup{
+						SpecSource: SpecSourceProvided, Type: DiffTypeGeneralDiff, Path: "/pets", Method: http.MethodGet,
+						Changes: []DiffChange{otherTypeChange}, FirstSeen: firstSeen, LastSeen: firstSeen.Add(time.Second), Count: 2,
+						SampleRequestIDs: []string{"1", "2"},
+					},
+				},
+			},
+			wantGroups: 1,
+		},
+		{
+			name: "different changes, methods and paths are grouped separately",
+			diffs: []aggregatedDiff{
+				{apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{typeChange}}, method: http.MethodGet, requestID: "1"},
+				{apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{addedParam}}, method: http.MethodGet, requestID: "2"},
+				{apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{typeChange}}, method: http.MethodPost, requestID: "3"},
+				{apiDiff: &APIDiff{Type: DiffTypeShadowDiff, Path: "/owners"}, method: http.MethodGet, requestID: "4"},
+			},
+			wantEvents: []*DiffGroupEvent{
+				{
+					Status: DiffGroupStatusNew,
+					Group: DiffGroup{
+						SpecSource: SpecSourceProvided, Type: DiffTypeGeneralDiff, Path: "/pets", Method: http.MethodGet,
+						Changes: []DiffChange{typeChange}, FirstSeen: firstSeen, LastSeen: firstSeen, Count: 1,
+						SampleRequestIDs: []string{"1"},
+					},
+				},
+				{
+					Status: DiffGroupStatusNew,
+					Group: DiffGroup{
+						SpecSource: SpecSourceProvided, Type: DiffTypeGeneralDiff, Path: "/pets", Method: http.MethodGet,
+						Changes: []DiffChange{addedParam}, FirstSeen: firstSeen.Add(time.Second), LastSeen: firstSeen.Add(time.Second), Count: 1,
+						SampleRequestIDs: []string{"2"},
+					},
+				},
+				{
+					Status: DiffGroupStatusNew,
+					Group: DiffGroup{
+						SpecSource: SpecSourceProvided, Type: DiffTypeGeneralDiff, Path: "/pets", Method: http.MethodPost,
+						Changes: []DiffChange{typeChange}, FirstSeen: firstSeen.Add(2 * time.Second), LastSeen: firstSeen.Add(2 * time.Second), Count: 1,
+						SampleRequestIDs: []string{"3"},
+					},
+				},
+				{
+					Status: DiffGroupStatusNew,
+					Group: DiffGroup{
+						SpecSource: SpecSourceProvided, Type: DiffTypeShadowDiff, Path: "/owners", Method: http.MethodGet,
+						FirstSeen: firstSeen.Add(3 * time.Second), LastSeen: firstSeen.Add(3 * time.Second), Count: 1,
+						SampleRequestIDs: []string{"4"},
+					},
+				},
+			},
+			wantGroups: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+			for i, diff := range tt.diffs {
+				got, err := s.aggregateDiff(diff.apiDiff, SpecSourceProvided, diff.method, diff.requestID, firstSeen.Add(time.Duration(i)*time.Second))
+				assert.NilError(t, err)
+				if got != nil {
+					// fingerprint and hash values are not checked
+					got.Group.Fingerprint = ""
+					got.Group.ChangesHash = ""
+				}
+				assert.DeepEqual(t, got, tt.wantEvents[i])
+			}
+			assert.Equal(t, len(s.GetDiffGroups()), tt.wantGroups)
+		})
+	}
+}
+
+func TestSpec_aggregateDiff_SampleRequestIDs(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	apiDiff := &APIDiff{Type: DiffTypeShadowDiff, Path: "/pets"}
+	for i := 0; i < maxDiffGroupSampleRequestIDs+5; i++ {
+		_, err := s.aggregateDiff(apiDiff, SpecSourceReconstructed, http.MethodGet, fmt.Sprint(i), time.Now())
+		assert.NilError(t, err)
+	}
+
+	groups := s.GetDiffGroups()
+	assert.Equal(t, len(groups), 1)
+	assert.Equal(t, groups[0].Count, uint64(maxDiffGroupSampleRequestIDs+5))
+	assert.Equal(t, len(groups[0].SampleRequestIDs), maxDiffGroupSampleRequestIDs)
+}
+
+func TestSpec_DiffTelemetryAggregated(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{"/pets/{petId}": "1"}))
+
+	event, err := s.DiffTelemetryAggregated(createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"name": "rex", "color": "black"}`), SpecSourceProvided)
+	assert.NilError(t, err)
+	assert.Assert(t, event != nil)
+	assert.Equal(t, event.Status, DiffGroupStatusNew)
+	assert.Equal(t, event.Group.Path, "/api/pets/{petId}")
+
+	event, err = s.DiffTelemetryAggregated(createTelemetry("2", http.MethodGet, "/api/pets/2", "host", "200", "", `{"name": "max", "color": "white"}`), SpecSourceProvided)
+	assert.NilError(t, err)
+	assert.Assert(t, event == nil)
+
+	groups := s.GetDiffGroups()
+	assert.Equal(t, len(groups), 1)
+	assert.Equal(t, groups[0].Count, uint64(2))
+	assert.DeepEqual(t, groups[0].SampleRequestIDs, []string{"1", "2"})
+}
+
+func TestSpec_DiffTelemetryAggregated_ShadowDiffs(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	s.ApprovedSpec = &ApprovedSpec{
+		PathItems: map[string]*spec.PathItem{
+			"/api": &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
+		},
+	}
+	s.ApprovedPathTrie = createPathTrie(map[string]string{"/api": "1"})
+
+	event, err := s.DiffTelemetryAggregated(createTelemetry("1", http.MethodGet, "/pets/1", "host", "200", "", ""), SpecSourceReconstructed)
+	assert.NilError(t, err)
+	assert.Assert(t, event != nil)
+	assert.Equal(t, event.Status, DiffGroupStatusNew)
+	assert.Equal(t, event.Group.Type, DiffTypeShadowDiff)
+	assert.Equal(t, event.Group.Path, "/pets/{petId}")
+
+	// calls that differ only by the id are aggregated into the same group
+	for i, path := range []string{"/pets/2", "/pets/a1b2c3d4-e5f6-47a8-b9c0-d1e2f3a4b5c6"} {
+		event, err = s.DiffTelemetryAggregated(createTelemetry(fmt.Sprint(i+2), http.MethodGet, path, "host", "200", "", ""), SpecSourceReconstructed)
+		assert.NilError(t, err)
+		assert.Assert(t, event == nil)
+	}
+
+	groups := s.GetDiffGroups()
+	assert.Equal(t, len(groups), 1)
+	assert.Equal(t, groups[0].Count, uint64(3))
+
+	// paths that are parameterized only by their cardinality (see parameterizeByCardinality)
+	for i, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		event, err = s.DiffTelemetryAggregated(createTelemetry(fmt.Sprint(i+4), http.MethodGet, "/users/"+name, "host", "200", "", ""), SpecSourceReconstructed)
+		assert.NilError(t, err)
+	}
+	assert.Assert(t, event != nil)
+	assert.Equal(t, event.Group.Path, "/users/{userId}")
+	event, err = s.DiffTelemetryAggregated(createTelemetry("9", http.MethodGet, "/users/frank", "host", "200", "", ""), SpecSourceReconstructed)
+	assert.NilError(t, err)
+	assert.Assert(t, event == nil)
+}
+
+func TestSpec_aggregateDiff_MaxGroups(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	firstSeen := time.Unix(1000, 0)
+	for i := 0; i < maxDiffGroups; i++ {
+		apiDiff := &APIDiff{Type: DiffTypeGeneralDiff, Path: fmt.Sprintf("/pets/%v", i)}
+		event, err := s.aggregateDiff(apiDiff, SpecSourceReconstructed, http.MethodGet, fmt.Sprint(i), firstSeen.Add(time.Duration(i)*time.Second))
+		assert.NilError(t, err)
+		assert.Assert(t, event != nil)
+	}
+	// /pets/0 is seen again, so /pets/1 is the least recently seen group
+	_, err := s.aggregateDiff(&APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets/0"}, SpecSourceReconstructed, http.MethodGet, "0", firstSeen.Add(maxDiffGroups*time.Second))
+	assert.NilError(t, err)
+
+	// a new group above the limit evicts the least recently seen group
+	event, err := s.aggregateDiff(&APIDiff{Type: DiffTypeGeneralDiff, Path: "/owners"}, SpecSourceReconstructed, http.MethodGet, "1", firstSeen.Add((maxDiffGroups+1)*time.Second))
+	assert.NilError(t, err)
+	assert.Assert(t, event != nil)
+	assert.Equal(t, event.Status, DiffGroupStatusNew)
+
+	groups := s.GetDiffGroups()
+	assert.Equal(t, len(groups), maxDiffGroups)
+	paths := make(map[string]bool)
+	for _, group := range groups {
+		paths[group.Path] = true
+	}
+	assert.Assert(t, paths["/pets/0"])
+	assert.Assert(t, !paths["/pets/1"])
+	assert.Assert(t, paths["/owners"])
+}
@@ -0,0 +1,314 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+)
+
+type DiffChangeKind string
+
+const (
+	DiffChangeKindAdded         DiffChangeKind = "ADDED"
+	DiffChangeKindRemoved       DiffChangeKind = "REMOVED"
+	DiffChangeKindChanged       DiffChangeKind = "CHANGED"
+	DiffChangeKindTypeChanged   DiffChangeKind = "TYPE_CHANGED"
+	DiffChangeKindFormatChanged DiffChangeKind = "FORMAT_CHANGED"
+	DiffChangeKindNewStatusCode DiffChangeKind = "NEW_STATUS_CODE"
+	DiffChangeKindNewParam      DiffChangeKind = "NEW_PARAM"
+)
+
+// parameterKeySeparator separates the `in` and name of a parameter key, e.g. header.X-Request-ID.
+// The `in` values have no dots, so the key is split on the first one.
+const parameterKeySeparator = "."
+
+// Location of a field relative to the operation, as the unescaped JSON pointer segments.
+// e.g. requestBody, content, application/json, schema, properties, foo
+// Parameters are located by their `in` and name, e.g. parameters, header, X-Request-ID
+type Location []string
+
+type DiffChange struct {
+	// Location of the changed field relative to the operation
+	Location Location
+	Kind     DiffChangeKind
+	// OldValue is nil for added fields, NewValue is nil for removed fields.
+	// For TYPE_CHANGED and FORMAT_CHANGED these are the old and new schema type (or format).
+	OldValue interface{}
+	NewValue interface{}
+	// ConsumerSeverity and ProviderSeverity classify the change from the API consumer's and provider's perspectives.
+	ConsumerSeverity DiffSeverity
+	ProviderSeverity DiffSeverity
+}
+
+// calculateOperationChanges returns the field-level changes between the original and the modified operations,
+// sorted by location and classified by severity (see classifyDiffChanges).
+func calculateOperationChanges(original, modified *oapi_spec.Operation) ([]DiffChange, error) {
+	originalObj, err := toGenericObject(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert original operation: %w", err)
+	}
+
+	modifiedObj, err := toGenericObject(modified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert modified operation: %w", err)
+	}
+
+	changes := compareValues(nil, originalObj, modifiedObj)
+	classifyObjectChanges(originalObj, modifiedObj, changes)
+
+	sortDiffChanges(changes)
+
+	return changes, nil
+}
+
+// toGenericObject converts obj into its generic JSON representation (maps, slices and primitives).
+func toGenericObject(obj interface{}) (interface{}, error) {
+	objB, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal object: %w", err)
+	}
+
+	var ret interface{}
+	if err := json.Unmarshal(objB, &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal object: %w", err)
+	}
+
+	return ret, nil
+}
+
+func compareValues(location Location, oldValue, newValue interface{}) []DiffChange {
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		return compareMaps(location, oldMap, newMap)
+	}
+
+	if isOperationParameters(location) {
+		oldParams, oldIsSlice := oldValue.([]interface{})
+		newParams, newIsSlice := newValue.([]interface{})
+		if oldIsSlice && newIsSlice {
+			return compareParameters(location, oldParams, newParams)
+		}
+	}
+
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	return []DiffChange{createDiffChange(location, DiffChangeKindChanged, oldValue, newValue)}
+}
+
+func compareMaps(location Location, oldMap, newMap map[string]interface{}) []DiffChange {
+	var changes []DiffChange
+
+	// schema type change makes the rest of the schema irrelevant
+	oldType, oldHasType := oldMap["type"].(string)
+	newType, newHasType := newMap["type"].(string)
+	isSchema := oldHasType && newHasType
+	if isSchema && oldType != newType {
+		return []DiffChange{createDiffChange(location, DiffChangeKindTypeChanged, oldType, newType)}
+	}
+
+	if isSchema && oldMap["format"] != newMap["format"] {
+		changes = append(changes, createDiffChange(location, DiffChangeKindFormatChanged, oldMap["format"], newMap["format"]))
+	}
+
+	for _, key := range sortedUnionKeys(oldMap, newMap) {
+		if isSchema && (key == "type" || key == "format") {
+			continue
+		}
+
+		keyLocation := appendLocation(location, key)
+		oldValue, inOld := oldMap[key]
+		newValue, inNew := newMap[key]
+		// missing parameters are compared as empty, so each added or removed parameter is reported separately
+		if isOperationParameters(keyLocation) {
+			oldValue, inOld, newValue, inNew = getOrEmptySlice(oldValue), true, getOrEmptySlice(newValue), true
+		}
+		switch {
+		case !inOld:
+			kind := DiffChangeKindAdded
+			if isOperationResponses(location) {
+				kind = DiffChangeKindNewStatusCode
+			}
+			changes = append(changes, createDiffChange(keyLocation, kind, nil, newValue))
+		case !inNew:
+			changes = append(changes, createDiffChange(keyLocation, DiffChangeKindRemoved, oldValue, nil))
+		default:
+			changes = append(changes, compareValues(keyLocation, oldValue, newValue)...)
+		}
+	}
+
+	return changes
+}
+
+// compareParameters compares the operation parameters by their `in` and name, since their order is not relevant.
+func compareParameters(location Location, oldParams, newParams []interface{}) []DiffChange {
+	var changes []DiffChange
+
+	oldParamsMap := createParametersMap(oldParams)
+	newParamsMap := createParametersMap(newParams)
+
+	for _, key := range sortedUnionKeys(oldParamsMap, newParamsMap) {
+		keyLocation := appendLocation(location, splitParameterKey(key)...)
+		oldParam, inOld := oldParamsMap[key]
+		newParam, inNew := newParamsMap[key]
+		switch {
+		case !inOld:
+			changes = append(changes, createDiffChange(keyLocation, DiffChangeKindNewParam, nil, newParam))
+		case !inNew:
+			changes = append(changes, createDiffChange(keyLocation, DiffChangeKindRemoved, oldParam, nil))
+		default:
+			changes = append(changes, compareValues(keyLocation, oldParam, newParam)...)
+		}
+	}
+
+	return changes
+}
+
+// createParametersMap maps the parameters by their key (see getParameterKey).
+func createParametersMap(params []interface{}) map[string]interface{} {
+	ret := make(map[string]interface{})
+
+	for i, param := range params {
+		paramMap, ok := param.(map[string]interface{})
+		if !ok {
+			ret[fmt.Sprint(i)] = param
+			continue
+		}
+		ret[getParameterKey(fmt.Sprint(paramMap["in"]), fmt.Sprint(paramMap["name"]))] = param
+	}
+
+	return ret
+}
+
+// getParameterKey returns the `<in>.<name>` key of a parameter.
+func getParameterKey(in, name string) string {
+	return in + parameterKeySeparator + name
+}
+
+// splitParameterKey splits the parameter key into its `in` and name.
+func splitParameterKey(key string) []string {
+	return strings.SplitN(key, parameterKeySeparator, 2)
+}
+
+func getOrEmptySlice(value interface{}) interface{} {
+	if value == nil {
+		return []interface{}{}
+	}
+
+	return value
+}
+
+func isOperationParameters(location Location) bool {
+	return len(location) == 1 && location[0] == locationParameters
+}
+
+func isOperationResponses(location Location) bool {
+	return len(location) == 1 && location[0] == locationResponses
+}
+
+func sortedUnionKeys(map1, map2 map[string]interface{}) []string {
+	keysSet := make(map[string]bool)
+	for key := range map1 {
+		keysSet[key] = true
+	}
+	for key := range map2 {
+		keysSet[key] = true
+	}
+
+	keys := make([]string, 0, len(keysSet))
+	for key := range keysSet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func createDiffChange(location Location, kind DiffChangeKind, oldValue, newValue interface{}) DiffChange {
+	return DiffChange{
+		Location: location,
+		Kind:     kind,
+		OldValue: oldValue,
+		NewValue: newValue,
+	}
+}
+
+func sortDiffChanges(changes []DiffChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Location.less(changes[j].Location)
+	})
+}
+
+// String returns the location as a JSON pointer, e.g. /requestBody/content/application~1json.
+func (l Location) String() string {
+	var sb strings.Builder
+	for _, segment := range l {
+		sb.WriteString("/")
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+
+	return sb.String()
+}
+
+// hasPrefix checks if prefix is the location or one of its ancestors.
+func (l Location) hasPrefix(prefix Location) bool {
+	if len(prefix) > len(l) {
+		return false
+	}
+	for i := range prefix {
+		if l[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (l Location) less(other Location) bool {
+	for i := 0; i < len(l) && i < len(other); i++ {
+		if l[i] != other[i] {
+			return l[i] < other[i]
+		}
+	}
+
+	return len(l) < len(other)
+}
+
+// parseLocation parses a JSON pointer, e.g. /responses/200/content/application~1json.
+func parseLocation(pointer string) Location {
+	if pointer == "" {
+		return nil
+	}
+
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+
+	return segments
+}
+
+func appendLocation(location Location, segments ...string) Location {
+	return append(append(Location{}, location...), segments...)
+}
@@ -0,0 +1,198 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"testing"
+
+	spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+func createTestRequestBody(properties map[string]*spec.Schema) *spec.RequestBody {
+	return spec.NewRequestBody().WithJSONSchema(spec.NewObjectSchema().WithProperties(properties))
+}
+
+func Test_calculateOperationChanges(t *testing.T) {
+	type args struct {
+		original *spec.Operation
+		modified *spec.Operation
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []DiffChange
+		wantErr bool
+	}{
+		{
+			name: "no changes",
+			args: args{
+				original: createTestOperation().WithParameter(spec.NewHeaderParameter("header")).Op,
+				modified: createTestOperation().WithParameter(spec.NewHeaderParameter("header")).Op,
+			},
+			want: nil,
+		},
+		{
+			name: "new param and removed param",
+			args: args{
+				original: createTestOperation().WithParameter(spec.NewHeaderParameter("header")).Op,
+				modified: createTestOperation().WithParameter(spec.NewQueryParameter("query")).Op,
+			},
+			want: []DiffChange{
+				{
+					Location:         Location{"parameters", "header", "header"},
+					Kind:             DiffChangeKindRemoved,
+					OldValue:         map[string]interface{}{"in": "header", "name": "header"},
+					ConsumerSeverity: DiffSeverityNonBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+				{
+					Location:         Location{"parameters", "query", "query"},
+					Kind:             DiffChangeKindNewParam,
+					NewValue:         map[string]interface{}{"in": "query", "name": "query"},
+					ConsumerSeverity: DiffSeverityNonBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+		},
+		{
+			name: "param schema type changed",
+			args: args{
+				original: createTestOperation().WithParameter(spec.NewQueryParameter("query").WithSchema(spec.NewStringSchema())).Op,
+				modified: createTestOperation().WithParameter(spec.NewQueryParameter("query").WithSchema(spec.NewInt64Schema())).Op,
+			},
+			want: []DiffChange{
+				{
+					Location:         Location{"parameters", "query", "query", "schema"},
+					Kind:             DiffChangeKindTypeChanged,
+					OldValue:         "string",
+					NewValue:         "integer",
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityBreaking,
+				},
+			},
+		},
+		{
+			name: "new status code",
+			args: args{
+				original: createTestOperation().WithResponse(200, spec.NewResponse().WithDescription("200")).Op,
+				modified: createTestOperation().
+					WithResponse(200, spec.NewResponse().WithDescription("200")).
+					WithResponse(404, spec.NewResponse().WithDescription("404")).Op,
+			},
+			want: []DiffChange{
+				{
+					Location:         Location{"responses", "404"},
+					Kind:             DiffChangeKindNewStatusCode,
+					NewValue:         map[string]interface{}{"description": "404"},
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+		},
+		{
+			name: "request body property added, removed, format changed and value changed",
+			args: args{
+				original: createTestOperation().WithRequestBody(createTestRequestBody(map[string]*spec.Schema{
+					"id":      spec.NewStringSchema().WithFormat("uuid"),
+					"removed": spec.NewBoolSchema(),
+					"max":     spec.NewStringSchema().WithMaxLength(5),
+				})).Op,
+				modified: createTestOperation().WithRequestBody(createTestRequestBody(map[string]*spec.Schema{
+					"id":  spec.NewStringSchema(),
+					"foo": spec.NewStringSchema(),
+					"max": spec.NewStringSchema().WithMaxLength(10),
+				})).Op,
+			},
+			want: []DiffChange{
+				{
+					Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "foo"},
+					Kind:             DiffChangeKindAdded,
+					NewValue:         map[string]interface{}{"type": "string"},
+					ConsumerSeverity: DiffSeverityNonBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+				{
+					Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "id"},
+					Kind:             DiffChangeKindFormatChanged,
+					OldValue:         "uuid",
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityBreaking,
+				},
+				{
+					Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "max", "maxLength"},
+					Kind:             DiffChangeKindChanged,
+					OldValue:         float64(5),
+					NewValue:         float64(10),
+					ConsumerSeverity: DiffSeverityNonBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+				{
+					Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "removed"},
+					Kind:             DiffChangeKindRemoved,
+					OldValue:         map[string]interface{}{"type": "boolean"},
+					ConsumerSeverity: DiffSeverityNonBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+		},
+		{
+			name: "security added",
+			args: args{
+				original: createTestOperation().Op,
+				modified: createTestOperation().WithSecurityRequirement(spec.SecurityRequirement{"BasicAuth": {}}).Op,
+			},
+			want: []DiffChange{
+				{
+					Location:         Location{"security"},
+					Kind:             DiffChangeKindAdded,
+					NewValue:         []interface{}{map[string]interface{}{"BasicAuth": []interface{}{}}},
+					ConsumerSeverity: DiffSeverityBreaking,
+					ProviderSeverity: DiffSeverityNonBreaking,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateOperationChanges(tt.args.original, tt.args.modified)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("calculateOperationChanges() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestLocation_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		pointer string
+		want    Location
+	}{
+		{name: "empty", pointer: "", want: nil},
+		{name: "media type", pointer: "/responses/200/content/application~1json", want: Location{"responses", "200", "content", "application/json"}},
+		{name: "param with dot", pointer: "/parameters/query/filter.name", want: Location{"parameters", "query", "filter.name"}},
+		{name: "tilde", pointer: "/requestBody/content/application~1json/schema/properties/a~0b", want: Location{"requestBody", "content", "application/json", "schema", "properties", "a~b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, parseLocation(tt.pointer), tt.want)
+			assert.Equal(t, tt.want.String(), tt.pointer)
+		})
+	}
+}
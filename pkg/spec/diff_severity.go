@@ -0,0 +1,247 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+)
+
+type DiffSeverity string
+
+const (
+	DiffSeverityNonBreaking DiffSeverity = "NON_BREAKING"
+	DiffSeverityBreaking    DiffSeverity = "BREAKING"
+)
+
+const (
+	locationParameters  = "parameters"
+	locationRequestBody = "requestBody"
+	locationSecurity    = "security"
+	locationResponses   = "responses"
+	locationProperties  = "properties"
+	locationHeaders     = "headers"
+	locationContent     = "content"
+	locationRequired    = "required"
+	locationEnum        = "enum"
+)
+
+// classifyDiffChanges sets the consumer and provider severity of the changes from the original operation
+// to the modified operation. The changes of calculateOperationChanges are already classified.
+// A change is breaking for the consumer if clients written against the original operation may fail
+// (e.g. a new required request field, a removed response field or a new status code), and breaking for the provider
+// if servers implementing the original operation may fail (e.g. a required request field that is no longer sent).
+func classifyDiffChanges(original, modified *oapi_spec.Operation, changes []DiffChange) error {
+	originalObj, err := toGenericObject(original)
+	if err != nil {
+		return fmt.Errorf("failed to convert original operation: %w", err)
+	}
+
+	modifiedObj, err := toGenericObject(modified)
+	if err != nil {
+		return fmt.Errorf("failed to convert modified operation: %w", err)
+	}
+
+	classifyObjectChanges(originalObj, modifiedObj, changes)
+
+	return nil
+}
+
+// classifyObjectChanges classifies the changes between the generic JSON representations of the operations.
+func classifyObjectChanges(original, modified interface{}, changes []DiffChange) {
+	for i := range changes {
+		changes[i].ConsumerSeverity, changes[i].ProviderSeverity = classifyDiffChange(original, modified, changes[i])
+	}
+}
+
+// getDiffSeverity returns breaking if any of the changes is breaking for the consumer or the provider.
+func getDiffSeverity(changes []DiffChange) DiffSeverity {
+	for _, change := range changes {
+		if change.ConsumerSeverity == DiffSeverityBreaking || change.ProviderSeverity == DiffSeverityBreaking {
+			return DiffSeverityBreaking
+		}
+	}
+
+	return DiffSeverityNonBreaking
+}
+
+func classifyDiffChange(original, modified interface{}, change DiffChange) (consumer, provider DiffSeverity) {
+	consumer, provider = DiffSeverityNonBreaking, DiffSeverityNonBreaking
+
+	location := change.Location
+	if len(location) == 0 {
+		return consumer, provider
+	}
+	isRequest := location[0] == locationParameters || location[0] == locationRequestBody || location[0] == locationSecurity
+
+	if isKeywordLocation(location) {
+		return classifyKeywordChange(isRequest, location, change.OldValue, change.NewValue)
+	}
+
+	switch change.Kind {
+	case DiffChangeKindNewParam, DiffChangeKindAdded:
+		if isRequest && (location[0] == locationSecurity || isRequiredLocation(modified, location)) {
+			consumer = DiffSeverityBreaking
+		}
+	case DiffChangeKindNewStatusCode:
+		consumer = DiffSeverityBreaking
+	case DiffChangeKindRemoved:
+		if isRequest && isRequiredLocation(original, location) {
+			provider = DiffSeverityBreaking
+		}
+		if !isRequest && isResponseElementLocation(location) {
+			consumer = DiffSeverityBreaking
+		}
+	case DiffChangeKindTypeChanged, DiffChangeKindFormatChanged:
+		consumer = DiffSeverityBreaking
+		if isRequest {
+			provider = DiffSeverityBreaking
+		}
+	}
+
+	return consumer, provider
+}
+
+// isKeywordLocation checks if location is a `required` or `enum` keyword (and not a property with that name).
+func isKeywordLocation(location Location) bool {
+	last := location[len(location)-1]
+	if last != locationRequired && last != locationEnum {
+		return false
+	}
+
+	return len(location) < 2 || location[len(location)-2] != locationProperties
+}
+
+// classifyKeywordChange classifies changes of the `required` and `enum` keywords.
+func classifyKeywordChange(isRequest bool, location Location, oldValue, newValue interface{}) (consumer, provider DiffSeverity) {
+	consumer, provider = DiffSeverityNonBreaking, DiffSeverityNonBreaking
+
+	var added, removed bool
+	switch location[len(location)-1] {
+	case locationRequired:
+		// parameter and request body required is a bool, schema required is a list of property names
+		oldRequired, oldIsBool := oldValue.(bool)
+		newRequired, newIsBool := newValue.(bool)
+		if oldIsBool || newIsBool {
+			added, removed = newRequired && !oldRequired, oldRequired && !newRequired
+		} else {
+			added, removed = hasMissingValues(newValue, oldValue), hasMissingValues(oldValue, newValue)
+		}
+	case locationEnum:
+		// a new enum value is like a removed requirement, and a removed enum value is like a new requirement
+		added, removed = hasMissingValues(oldValue, newValue), hasMissingValues(newValue, oldValue)
+	}
+
+	if isRequest {
+		if added {
+			consumer = DiffSeverityBreaking
+		}
+		if removed {
+			provider = DiffSeverityBreaking
+		}
+	} else if removed {
+		consumer = DiffSeverityBreaking
+	}
+
+	return consumer, provider
+}
+
+// isRequiredLocation checks if the parameter, request body or schema property in location is required.
+func isRequiredLocation(root interface{}, location Location) bool {
+	// schema property is required by its parent schema
+	if len(location) > 2 && location[len(location)-2] == locationProperties {
+		parent, ok := getLocationValue(root, location[:len(location)-2]).(map[string]interface{})
+		if !ok {
+			return false
+		}
+		requiredList, _ := parent[locationRequired].([]interface{})
+		for _, required := range requiredList {
+			if required == location[len(location)-1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// parameter or request body
+	if len(location) == 3 && location[0] == locationParameters || len(location) == 1 && location[0] == locationRequestBody {
+		value, ok := getLocationValue(root, location).(map[string]interface{})
+		return ok && value[locationRequired] == true
+	}
+
+	return false
+}
+
+// isResponseElementLocation checks if location is a response, or response properties, headers or media types.
+func isResponseElementLocation(location Location) bool {
+	if len(location) < 2 {
+		return false
+	}
+	if len(location) == 2 && location[0] == locationResponses {
+		return true
+	}
+
+	for _, segment := range location[len(location)-2:] {
+		switch segment {
+		case locationProperties, locationHeaders, locationContent:
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasMissingValues checks if values has elements that are not in otherValues.
+func hasMissingValues(values, otherValues interface{}) bool {
+	valuesList, _ := values.([]interface{})
+	otherValuesList, _ := otherValues.([]interface{})
+
+	otherValuesSet := make(map[string]bool, len(otherValuesList))
+	for _, value := range otherValuesList {
+		otherValuesSet[fmt.Sprint(value)] = true
+	}
+	for _, value := range valuesList {
+		if !otherValuesSet[fmt.Sprint(value)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getLocationValue returns the value at location, operation parameters are located by their `in` and name.
+func getLocationValue(root interface{}, location Location) interface{} {
+	value := root
+	for i := 0; i < len(location); i++ {
+		if params, ok := value.([]interface{}); ok && isOperationParameters(location[:i]) {
+			if i+1 == len(location) {
+				return nil
+			}
+			value = createParametersMap(params)[getParameterKey(location[i], location[i+1])]
+			i++
+			continue
+		}
+
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = valueMap[location[i]]
+	}
+
+	return value
+}
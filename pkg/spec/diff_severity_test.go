@@ -0,0 +1,191 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"testing"
+
+	spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+type changeSeverity struct {
+	Location Location
+	Consumer DiffSeverity
+	Provider DiffSeverity
+}
+
+func Test_classifyDiffChanges(t *testing.T) {
+	type args struct {
+		original *spec.Operation
+		modified *spec.Operation
+	}
+	tests := []struct {
+		name string
+		args args
+		want []changeSeverity
+	}{
+		{
+			name: "new required param is breaking for the consumer, new optional param is not breaking",
+			args: args{
+				original: createTestOperation().Op,
+				modified: createTestOperation().
+					WithParameter(spec.NewHeaderParameter("X-Required").WithRequired(true)).
+					WithParameter(spec.NewQueryParameter("optional")).Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"parameters", "header", "X-Required"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+				{Location: Location{"parameters", "query", "optional"}, Consumer: DiffSeverityNonBreaking, Provider: DiffSeverityNonBreaking},
+			},
+		},
+		{
+			name: "removed required param is breaking for the provider",
+			args: args{
+				original: createTestOperation().WithParameter(spec.NewQueryParameter("limit").WithRequired(true)).Op,
+				modified: createTestOperation().Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"parameters", "query", "limit"}, Consumer: DiffSeverityNonBreaking, Provider: DiffSeverityBreaking},
+			},
+		},
+		{
+			name: "new required request field is breaking for the consumer",
+			args: args{
+				original: createTestOperation().WithRequestBody(createTestRequestBody(map[string]*spec.Schema{
+					"name": spec.NewStringSchema(),
+				})).Op,
+				modified: createTestOperation().WithRequestBody(spec.NewRequestBody().WithJSONSchema(&spec.Schema{
+					Type: spec.TypeObject,
+					Properties: spec.Schemas{
+						"name": spec.NewSchemaRef("", spec.NewStringSchema()),
+						"id":   spec.NewSchemaRef("", spec.NewInt64Schema()),
+					},
+					Required: []string{"id"},
+				})).Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"requestBody", "content", "application/json", "schema", "properties", "id"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+				{Location: Location{"requestBody", "content", "application/json", "schema", "required"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+			},
+		},
+		{
+			name: "request field type change is breaking for both",
+			args: args{
+				original: createTestOperation().WithRequestBody(createTestRequestBody(map[string]*spec.Schema{
+					"id": spec.NewInt64Schema(),
+				})).Op,
+				modified: createTestOperation().WithRequestBody(createTestRequestBody(map[string]*spec.Schema{
+					"id": spec.NewStringSchema(),
+				})).Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"requestBody", "content", "application/json", "schema", "properties", "id"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityBreaking},
+			},
+		},
+		{
+			name: "removed response field is breaking for the consumer, new optional response field is not breaking",
+			args: args{
+				original: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("id", spec.NewInt64Schema()))).Op,
+				modified: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("name", spec.NewStringSchema()))).Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"responses", "200", "content", "application/json", "schema", "properties", "id"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+				{Location: Location{"responses", "200", "content", "application/json", "schema", "properties", "name"}, Consumer: DiffSeverityNonBreaking, Provider: DiffSeverityNonBreaking},
+			},
+		},
+		{
+			name: "response media type with dots",
+			args: args{
+				original: createTestOperation().WithResponse(200, spec.NewResponse().WithDescription("response").
+					WithContent(spec.NewContentWithSchema(spec.NewObjectSchema().WithProperty("id", spec.NewInt64Schema()), []string{"application/vnd.api+json"}))).Op,
+				modified: createTestOperation().WithResponse(200, spec.NewResponse().WithDescription("response").
+					WithContent(spec.NewContentWithSchema(spec.NewObjectSchema().WithProperty("name", spec.NewStringSchema()), []string{"application/vnd.api+json"}))).Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"responses", "200", "content", "application/vnd.api+json", "schema", "properties", "id"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+				{Location: Location{"responses", "200", "content", "application/vnd.api+json", "schema", "properties", "name"}, Consumer: DiffSeverityNonBreaking, Provider: DiffSeverityNonBreaking},
+			},
+		},
+		{
+			name: "new status code and new response enum value are breaking for the consumer",
+			args: args{
+				original: createTestOperation().WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+					WithProperty("status", spec.NewStringSchema().WithEnum("on", "off")))).Op,
+				modified: createTestOperation().
+					WithResponse(200, createTestJSONResponse(spec.NewObjectSchema().
+						WithProperty("status", spec.NewStringSchema().WithEnum("on", "off", "unknown")))).
+					WithResponse(500, spec.NewResponse().WithDescription("error")).Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"responses", "200", "content", "application/json", "schema", "properties", "status", "enum"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+				{Location: Location{"responses", "500"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+			},
+		},
+		{
+			name: "new security requirement is breaking for the consumer",
+			args: args{
+				original: createTestOperation().Op,
+				modified: createTestOperation().WithSecurityRequirement(spec.SecurityRequirement{"BasicAuth": {}}).Op,
+			},
+			want: []changeSeverity{
+				{Location: Location{"security"}, Consumer: DiffSeverityBreaking, Provider: DiffSeverityNonBreaking},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := calculateOperationChanges(tt.args.original, tt.args.modified)
+			assert.NilError(t, err)
+
+			var got []changeSeverity
+			for _, change := range changes {
+				got = append(got, changeSeverity{Location: change.Location, Consumer: change.ConsumerSeverity, Provider: change.ProviderSeverity})
+			}
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func Test_getDiffSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []DiffChange
+		want    DiffSeverity
+	}{
+		{
+			name: "non breaking",
+			changes: []DiffChange{
+				{ConsumerSeverity: DiffSeverityNonBreaking, ProviderSeverity: DiffSeverityNonBreaking},
+			},
+			want: DiffSeverityNonBreaking,
+		},
+		{
+			name: "breaking for the provider",
+			changes: []DiffChange{
+				{ConsumerSeverity: DiffSeverityNonBreaking, ProviderSeverity: DiffSeverityNonBreaking},
+				{ConsumerSeverity: DiffSeverityNonBreaking, ProviderSeverity: DiffSeverityBreaking},
+			},
+			want: DiffSeverityBreaking,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, getDiffSeverity(tt.changes), tt.want)
+		})
+	}
+}
@@ -196,6 +196,8 @@ func TestSpec_DiffTelemetry_Reconstructed(t *testing.T) {
 				Path:             "/api",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, DataWithAuth).Op).PathItem,
+				Changes:          securityDiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -274,6 +276,8 @@ func TestSpec_DiffTelemetry_Reconstructed(t *testing.T) {
 				Path:             "/api",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -300,6 +304,8 @@ func TestSpec_DiffTelemetry_Reconstructed(t *testing.T) {
 				Path:             "/api/{my-param}",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -327,6 +333,8 @@ func TestSpec_DiffTelemetry_Reconstructed(t *testing.T) {
 				Path:             "/api/1",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -426,6 +434,8 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 				Path:             "/api",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, DataWithAuth).Op).PathItem,
+				Changes:          securityDiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -510,6 +520,8 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 				Path:             "/api",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -543,6 +555,9 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 				Path:             "/api/foo/{param}",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Server:           "https://example.com/api",
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -576,6 +591,9 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 				Path:             "/foo/bar",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Server:           "https://example.com/",
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -609,6 +627,9 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 				Path:             "/api/{my-param}",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Server:           "https://example.com/",
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -643,11 +664,85 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 				Path:             "/api/1",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Server:           "https://example.com/",
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
 			wantErr: false,
 		},
+		{
+			name: "multiple servers with different base paths",
+			fields: fields{
+				ID: specUUID,
+				ProvidedSpec: &ProvidedSpec{
+					Doc: &spec.T{
+						Servers: spec.Servers{
+							{
+								URL: "https://example.com/v1",
+							},
+							{
+								URL: "https://example.com/v2",
+							},
+						},
+						Paths: map[string]*spec.PathItem{
+							"/foo/{param}": &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
+						},
+					},
+				},
+				ProvidedPathTrie: createPathTrie(map[string]string{
+					"/foo/{param}": "1",
+				}),
+			},
+			args: args{
+				telemetry: createTelemetry(reqID, http.MethodGet, "/v2/foo/bar", "host", "200", Data.ReqBody, Data.RespBody),
+			},
+			want: &APIDiff{
+				Type:          DiffTypeNoDiff,
+				Path:          "/v2/foo/{param}",
+				Server:        "https://example.com/v2",
+				InteractionID: reqUUID,
+				SpecID:        specUUID,
+			},
+			wantErr: false,
+		},
+		{
+			name: "server variables",
+			fields: fields{
+				ID: specUUID,
+				ProvidedSpec: &ProvidedSpec{
+					Doc: &spec.T{
+						Servers: spec.Servers{
+							{
+								URL: "https://{region}.example.com/{version}",
+								Variables: map[string]*spec.ServerVariable{
+									"region":  {Default: "us"},
+									"version": {Default: "v1", Enum: []string{"v1", "v2"}},
+								},
+							},
+						},
+						Paths: map[string]*spec.PathItem{
+							"/foo/{param}": &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
+						},
+					},
+				},
+				ProvidedPathTrie: createPathTrie(map[string]string{
+					"/foo/{param}": "1",
+				}),
+			},
+			args: args{
+				telemetry: createTelemetry(reqID, http.MethodGet, "/v2/foo/bar", "host", "200", Data.ReqBody, Data.RespBody),
+			},
+			want: &APIDiff{
+				Type:          DiffTypeNoDiff,
+				Path:          "/v2/foo/{param}",
+				Server:        "https://us.example.com/v2",
+				InteractionID: reqUUID,
+				SpecID:        specUUID,
+			},
+			wantErr: false,
+		},
 		{
 			name: "Deprecated API expected Zombie API diff",
 			fields: fields{
@@ -672,6 +767,7 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Deprecated().Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
+				Severity:         DiffSeverityNonBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -700,6 +796,8 @@ func TestSpec_DiffTelemetry_Provided(t *testing.T) {
 				Path:             "/api",
 				OriginalPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Deprecated().Op).PathItem,
 				ModifiedPathItem: &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data2).Op).PathItem,
+				Changes:          data2DiffChanges(),
+				Severity:         DiffSeverityBreaking,
 				InteractionID:    reqUUID,
 				SpecID:           specUUID,
 			},
@@ -1181,3 +1279,71 @@ func Test_trimBasePathIfNeeded(t *testing.T) {
 		})
 	}
 }
+
+// securityDiffChanges are the changes between the Data and the DataWithAuth operations.
+func securityDiffChanges() []DiffChange {
+	return []DiffChange{
+		{
+			Location:         Location{"security"},
+			Kind:             DiffChangeKindAdded,
+			NewValue:         []interface{}{map[string]interface{}{"OAuth2": []interface{}{"superadmin", "write:all_your_base"}}},
+			ConsumerSeverity: DiffSeverityBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+	}
+}
+
+// data2DiffChanges are the changes between the Data and the Data2 operations.
+func data2DiffChanges() []DiffChange {
+	return []DiffChange{
+		{
+			Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "certificateVersion"},
+			Kind:             DiffChangeKindRemoved,
+			OldValue:         map[string]interface{}{"format": "uuid", "type": "string"},
+			ConsumerSeverity: DiffSeverityNonBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+		{
+			Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "controllerInstanceInfo"},
+			Kind:             DiffChangeKindRemoved,
+			OldValue:         map[string]interface{}{"properties": map[string]interface{}{"replicaId": map[string]interface{}{"type": "string"}}, "type": "object"},
+			ConsumerSeverity: DiffSeverityNonBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+		{
+			Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "policyAndAppVersion"},
+			Kind:             DiffChangeKindRemoved,
+			OldValue:         map[string]interface{}{"format": "int64", "type": "integer"},
+			ConsumerSeverity: DiffSeverityNonBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+		{
+			Location:         Location{"requestBody", "content", "application/json", "schema", "properties", "statusCodes"},
+			Kind:             DiffChangeKindAdded,
+			NewValue:         map[string]interface{}{"items": map[string]interface{}{"type": "string"}, "type": "array"},
+			ConsumerSeverity: DiffSeverityNonBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+		{
+			Location:         Location{"responses", "200", "content", "application/json", "schema", "properties", "cvss", "items", "properties", "score"},
+			Kind:             DiffChangeKindRemoved,
+			OldValue:         map[string]interface{}{"type": "number"},
+			ConsumerSeverity: DiffSeverityBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+		{
+			Location:         Location{"responses", "200", "content", "application/json", "schema", "properties", "cvss", "items", "properties", "vector"},
+			Kind:             DiffChangeKindRemoved,
+			OldValue:         map[string]interface{}{"type": "string"},
+			ConsumerSeverity: DiffSeverityBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+		{
+			Location:         Location{"responses", "200", "content", "application/json", "schema", "properties", "cvss", "items", "properties", "version"},
+			Kind:             DiffChangeKindAdded,
+			NewValue:         map[string]interface{}{"type": "string"},
+			ConsumerSeverity: DiffSeverityNonBreaking,
+			ProviderSeverity: DiffSeverityNonBreaking,
+		},
+	}
+}
@@ -42,6 +42,8 @@ func mergeOperation(operation, operation2 *spec.Operation) (*spec.Operation, []c
 		field.NewPath("responses"))
 
 	ret.Security = mergeOperationSecurity(operation.Security, operation2.Security)
+	mergeOperationDeprecation(ret, operation, operation2)
+	mergeOperationInfo(ret, operation, operation2)
 
 	conflicts := append(paramConflicts, resConflicts...)
 	conflicts = append(conflicts, requestBodyConflicts...)
@@ -165,10 +167,11 @@ func mergeParametersByInType(parameters, parameters2 spec.Parameters, path *fiel
 	parametersMapByName := makeParametersMapByName(parameters)
 	parameters2MapByName := makeParametersMapByName(parameters2)
 
-	// go over first parameters list
+	// go over first parameters list (in the list order, for a stable merge result)
 	// 1. merge mutual parameters
 	// 2. add non-mutual parameters
-	for name, param := range parametersMapByName {
+	for _, name := range getParameterNames(parameters) {
+		param := parametersMapByName[name]
 		if param2, ok := parameters2MapByName[name]; ok {
 			mergedParameter, conflicts := mergeParameter(param.Value, param2.Value, path.Child(name))
 			retConflicts = append(retConflicts, conflicts...)
@@ -179,15 +182,31 @@ func mergeParametersByInType(parameters, parameters2 spec.Parameters, path *fiel
 	}
 
 	// add non-mutual parameters from the second list
-	for name, param := range parameters2MapByName {
+	for _, name := range getParameterNames(parameters2) {
 		if _, ok := parametersMapByName[name]; !ok {
-			retParameters = append(retParameters, param)
+			retParameters = append(retParameters, parameters2MapByName[name])
 		}
 	}
 
 	return retParameters, retConflicts
 }
 
+// getParameterNames returns the unique parameter names in the list order.
+func getParameterNames(parameters spec.Parameters) []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for i := range parameters {
+		name := parameters[i].Value.Name
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		ret = append(ret, name)
+	}
+
+	return ret
+}
+
 func makeParametersMapByName(parameters spec.Parameters) map[string]*spec.ParameterRef {
 	ret := make(map[string]*spec.ParameterRef)
 
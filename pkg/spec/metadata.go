@@ -0,0 +1,217 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	"github.com/ghodss/yaml"
+)
+
+// SpecMetadata is the info, servers, tags and external docs of the generated spec.
+// An empty field is taken from the global metadata, and then from the defaults.
+type SpecMetadata struct {
+	Title          string                  `json:"title,omitempty"`
+	Description    string                  `json:"description,omitempty"`
+	Version        string                  `json:"version,omitempty"`
+	TermsOfService string                  `json:"termsOfService,omitempty"`
+	Contact        *oapi_spec.Contact      `json:"contact,omitempty"`
+	License        *oapi_spec.License      `json:"license,omitempty"`
+	ExternalDocs   *oapi_spec.ExternalDocs `json:"externalDocs,omitempty"`
+	// Servers of the generated spec, by default a server for each observed telemetry scheme with the spec host and port.
+	// In OASv2 the first server sets the host and base path, and all the servers set the schemes.
+	Servers oapi_spec.Servers `json:"servers,omitempty"`
+	Tags    oapi_spec.Tags    `json:"tags,omitempty"`
+}
+
+type SpecsMetadata struct {
+	// Global metadata of all the specs.
+	Global *SpecMetadata `json:"global,omitempty"`
+	// Specs metadata by spec key (host:port), overrides the global metadata.
+	Specs map[string]*SpecMetadata `json:"specs,omitempty"`
+}
+
+// WithGlobalMetadata sets the global metadata, used for the fields that are not set by the spec metadata.
+func WithGlobalMetadata(metadata *SpecMetadata) GenerateOption {
+	return func(params *GenerateParams) {
+		params.globalMetadata = metadata
+	}
+}
+
+// LoadSpecsMetadata loads and validates the global and per spec metadata from YAML (or JSON).
+func LoadSpecsMetadata(data []byte) (*SpecsMetadata, error) {
+	specsMetadata := &SpecsMetadata{}
+	if err := yaml.Unmarshal(data, specsMetadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal specs metadata: %w", err)
+	}
+
+	if err := specsMetadata.Global.validate(); err != nil {
+		return nil, fmt.Errorf("invalid global metadata: %w", err)
+	}
+	for specKey, metadata := range specsMetadata.Specs {
+		if err := metadata.validate(); err != nil {
+			return nil, fmt.Errorf("invalid metadata of spec %v: %w", specKey, err)
+		}
+	}
+
+	return specsMetadata, nil
+}
+
+func (m *SpecMetadata) validate() error {
+	if m == nil {
+		return nil
+	}
+
+	for i, server := range m.Servers {
+		if server == nil || server.URL == "" {
+			return fmt.Errorf("server %v has no url", i)
+		}
+	}
+
+	tagNames := make(map[string]bool)
+	for i, tag := range m.Tags {
+		if tag == nil || tag.Name == "" {
+			return fmt.Errorf("tag %v has no name", i)
+		}
+		if tagNames[tag.Name] {
+			return fmt.Errorf("duplicate tag %q", tag.Name)
+		}
+		tagNames[tag.Name] = true
+	}
+
+	return nil
+}
+
+// SetMetadata sets the spec metadata, which is used for the generated spec.
+func (s *Spec) SetMetadata(metadata *SpecMetadata) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.Metadata = metadata
+}
+
+// addObservedScheme records the telemetry scheme for the generated spec default servers.
+func (s *Spec) addObservedScheme(scheme string) {
+	scheme = strings.ToLower(scheme)
+	if scheme == "" {
+		return
+	}
+
+	for _, observedScheme := range s.ObservedSchemes {
+		if observedScheme == scheme {
+			return
+		}
+	}
+
+	s.ObservedSchemes = append(s.ObservedSchemes, scheme)
+	sort.Slice(s.ObservedSchemes, func(i, j int) bool {
+		return schemeLess(s.ObservedSchemes[i], s.ObservedSchemes[j])
+	})
+}
+
+// schemeLess orders https first and then http, since the first server is the preferred one.
+func schemeLess(a, b string) bool {
+	rank := func(scheme string) int {
+		switch scheme {
+		case "https":
+			return 0
+		case "http":
+			return 1
+		default:
+			return 2 // nolint:gomnd
+		}
+	}
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+
+	return a < b
+}
+
+// getGeneratedSpecMetadata returns the spec metadata, with the fields that are not set taken from the global metadata
+// and then from the defaults.
+func (s *Spec) getGeneratedSpecMetadata(globalMetadata *SpecMetadata) *SpecMetadata {
+	return mergeSpecMetadata(mergeSpecMetadata(s.Metadata, globalMetadata), s.createDefaultMetadata())
+}
+
+func (s *Spec) createDefaultMetadata() *SpecMetadata {
+	info := createDefaultSwaggerInfo()
+
+	return &SpecMetadata{
+		Title:          info.Title,
+		Description:    info.Description,
+		Version:        info.Version,
+		TermsOfService: info.TermsOfService,
+		Contact:        info.Contact,
+		License:        info.License,
+		Servers:        createDefaultServers(s.Host, s.Port, s.ObservedSchemes),
+	}
+}
+
+// mergeSpecMetadata returns the metadata with the fields that are not set taken from the fallback metadata.
+func mergeSpecMetadata(metadata, fallback *SpecMetadata) *SpecMetadata {
+	if metadata == nil {
+		return fallback
+	}
+	if fallback == nil {
+		return metadata
+	}
+
+	ret := *metadata
+	if ret.Title == "" {
+		ret.Title = fallback.Title
+	}
+	if ret.Description == "" {
+		ret.Description = fallback.Description
+	}
+	if ret.Version == "" {
+		ret.Version = fallback.Version
+	}
+	if ret.TermsOfService == "" {
+		ret.TermsOfService = fallback.TermsOfService
+	}
+	if ret.Contact == nil {
+		ret.Contact = fallback.Contact
+	}
+	if ret.License == nil {
+		ret.License = fallback.License
+	}
+	if ret.ExternalDocs == nil {
+		ret.ExternalDocs = fallback.ExternalDocs
+	}
+	if len(ret.Servers) == 0 {
+		ret.Servers = fallback.Servers
+	}
+	if len(ret.Tags) == 0 {
+		ret.Tags = fallback.Tags
+	}
+
+	return &ret
+}
+
+func (m *SpecMetadata) createInfo() *oapi_spec.Info {
+	return &oapi_spec.Info{
+		Title:          m.Title,
+		Description:    m.Description,
+		TermsOfService: m.TermsOfService,
+		Contact:        m.Contact,
+		License:        m.License,
+		Version:        m.Version,
+	}
+}
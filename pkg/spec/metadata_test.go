@@ -0,0 +1,200 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"gotest.tools/assert"
+)
+
+func TestLoadSpecsMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *SpecsMetadata
+		wantErr bool
+	}{
+		{
+			name: "global and spec metadata",
+			data: `global:
+  title: Pets
+  contact:
+    email: pets@example.com
+  tags:
+    - name: pets
+specs:
+  "example.com:443":
+    version: 2.0.0
+    servers:
+      - url: https://example.com/api
+`,
+			want: &SpecsMetadata{
+				Global: &SpecMetadata{
+					Title:   "Pets",
+					Contact: &oapi_spec.Contact{Email: "pets@example.com"},
+					Tags:    oapi_spec.Tags{{Name: "pets"}},
+				},
+				Specs: map[string]*SpecMetadata{
+					"example.com:443": {
+						Version: "2.0.0",
+						Servers: oapi_spec.Servers{{URL: "https://example.com/api"}},
+					},
+				},
+			},
+		},
+		{
+			name: "server without url",
+			data: `global:
+  servers:
+    - description: no url
+`,
+			wantErr: true,
+		},
+		{
+			name: "tag without name",
+			data: `specs:
+  "example.com:443":
+    tags:
+      - description: no name
+`,
+			wantErr: true,
+		},
+		{
+			name: "duplicate tag",
+			data: `global:
+  tags:
+    - name: pets
+    - name: pets
+`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			data:    "global: [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadSpecsMetadata([]byte(tt.data))
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want, cmpopts.IgnoreTypes(oapi_spec.ExtensionProps{}))
+		})
+	}
+}
+
+func TestSpec_GenerateOASJson_Metadata(t *testing.T) {
+	globalMetadata := &SpecMetadata{
+		Title:        "Global",
+		Description:  "Global description",
+		ExternalDocs: &oapi_spec.ExternalDocs{URL: "https://example.com/docs"},
+		Tags:         oapi_spec.Tags{{Name: "global"}},
+	}
+	specMetadata := &SpecMetadata{
+		Title:   "Pets",
+		License: &oapi_spec.License{Name: "MIT"},
+		Servers: oapi_spec.Servers{{URL: "https://pets.example.com/api"}},
+	}
+	tests := []struct {
+		name             string
+		specMetadata     *SpecMetadata
+		globalMetadata   *SpecMetadata
+		schemes          []string
+		wantInfo         *oapi_spec.Info
+		wantServers      []string
+		wantTags         []string
+		wantExternalDocs bool
+	}{
+		{
+			name:        "defaults",
+			schemes:     []string{""},
+			wantInfo:    createDefaultSwaggerInfo(),
+			wantServers: []string{"http://example.com:443"},
+		},
+		{
+			name:        "default servers by observed schemes",
+			schemes:     []string{"http", "HTTPS"},
+			wantInfo:    createDefaultSwaggerInfo(),
+			wantServers: []string{"https://example.com:443", "http://example.com:443"},
+		},
+		{
+			name:           "spec metadata overrides global metadata",
+			specMetadata:   specMetadata,
+			globalMetadata: globalMetadata,
+			schemes:        []string{"https"},
+			wantInfo: &oapi_spec.Info{
+				Title:          "Pets",
+				Description:    "Global description",
+				TermsOfService: createDefaultSwaggerInfo().TermsOfService,
+				Contact:        createDefaultSwaggerInfo().Contact,
+				License:        &oapi_spec.License{Name: "MIT"},
+				Version:        createDefaultSwaggerInfo().Version,
+			},
+			wantServers:      []string{"https://pets.example.com/api"},
+			wantTags:         []string{"global"},
+			wantExternalDocs: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+			for i, scheme := range tt.schemes {
+				telemetry := createTelemetry("1", http.MethodGet, "/pets", "example.com", "200", "", "")
+				telemetry.Scheme = scheme
+				telemetry.RequestID = string(rune('a' + i))
+				assert.NilError(t, s.LearnTelemetry(telemetry))
+			}
+			approveSuggestedReview(t, s)
+			s.SetMetadata(tt.specMetadata)
+
+			ret, err := s.GenerateOASJson(OASv3, WithGlobalMetadata(tt.globalMetadata))
+			assert.NilError(t, err)
+			doc := &oapi_spec.T{}
+			assert.NilError(t, json.Unmarshal(ret, doc))
+			assert.DeepEqual(t, doc.Info, tt.wantInfo, cmpopts.IgnoreTypes(oapi_spec.ExtensionProps{}))
+			var servers []string
+			for _, server := range doc.Servers {
+				servers = append(servers, server.URL)
+			}
+			assert.DeepEqual(t, servers, tt.wantServers)
+			var tags []string
+			for _, tag := range doc.Tags {
+				tags = append(tags, tag.Name)
+			}
+			assert.DeepEqual(t, tags, tt.wantTags)
+			assert.Equal(t, doc.ExternalDocs != nil, tt.wantExternalDocs)
+
+			// OASv2 honors the metadata as well
+			ret, err = s.GenerateOASJson(OASv2, WithGlobalMetadata(tt.globalMetadata))
+			assert.NilError(t, err)
+			docV2 := &openapi2.T{}
+			assert.NilError(t, json.Unmarshal(ret, docV2))
+			assert.Equal(t, docV2.Info.Title, tt.wantInfo.Title)
+			assert.Equal(t, len(docV2.Tags), len(tt.wantTags))
+			assert.Equal(t, len(docV2.Schemes), len(tt.wantServers))
+		})
+	}
+}
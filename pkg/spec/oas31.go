@@ -0,0 +1,379 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	log "github.com/sirupsen/logrus"
+)
+
+// OpenAPI 3.1 documents are converted to (and generated from) OpenAPI 3.0 documents, since the schemas are represented
+// by the OpenAPI 3.0 model. The differences are mainly in the schema object, which is a JSON Schema 2020-12 schema
+// in OpenAPI 3.1: https://github.com/OAI/OpenAPI-Specification/blob/main/versions/3.1.0.md#schema-object
+const (
+	openAPIVersion30 = "3.0.3"
+	openAPIVersion31 = "3.1.0"
+
+	schemaTypeNull = "null"
+)
+
+var pathItemMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// unsupportedSchemaKeywordsV31 are the JSON Schema 2020-12 keywords that have no OpenAPI 3.0 equivalent.
+var unsupportedSchemaKeywordsV31 = []string{"$defs", "prefixItems", "patternProperties"}
+
+func isOpenAPIVersion31(version string) bool {
+	return strings.HasPrefix(version, "3.1")
+}
+
+// LoadAndValidateRawJSONSpecV3FromV31 converts an OpenAPI 3.1 json spec to OpenAPI 3.0 and loads it:
+//   - `type` arrays are converted to `type` (or `oneOf` for multiple types) and `nullable` for the `null` type.
+//   - `const` is converted to a single value `enum`.
+//   - `examples` is converted to `example` with the first example.
+//   - numeric `exclusiveMinimum` and `exclusiveMaximum` are converted to `minimum` and `maximum`.
+//   - `webhooks` and `components.pathItems` are dropped, since they are not part of the API paths.
+//   - `$defs`, `prefixItems` and `patternProperties` are dropped, since they have no OpenAPI 3.0 equivalent.
+//
+// A warning is logged for every dropped field.
+func LoadAndValidateRawJSONSpecV3FromV31(spec []byte) (*oapi_spec.T, error) {
+	v30Spec, err := convertSpecV31ToV30(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert spec from v3.1: %w", err)
+	}
+
+	return LoadAndValidateRawJSONSpecV3(v30Spec)
+}
+
+func convertSpecV31ToV30(jsonSpec []byte) ([]byte, error) {
+	doc, err := unmarshalGenericDoc(jsonSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	doc["openapi"] = openAPIVersion30
+	if _, ok := doc["webhooks"]; ok {
+		log.Warnf("Ignoring OASv3.1 webhooks")
+		delete(doc, "webhooks")
+	}
+	if components := getMap(doc, "components"); components != nil {
+		if _, ok := components["pathItems"]; ok {
+			log.Warnf("Ignoring OASv3.1 components path items")
+			delete(components, "pathItems")
+		}
+	}
+	delete(doc, "jsonSchemaDialect")
+	// paths are optional in OpenAPI 3.1
+	if _, ok := doc["paths"]; !ok {
+		doc["paths"] = map[string]interface{}{}
+	}
+	walkDocSchemas(doc, convertSchemaV31ToV30)
+
+	return json.Marshal(doc)
+}
+
+func convertSpecV30ToV31(jsonSpec []byte) ([]byte, error) {
+	doc, err := unmarshalGenericDoc(jsonSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	doc["openapi"] = openAPIVersion31
+	walkDocSchemas(doc, convertSchemaV30ToV31)
+
+	return json.Marshal(doc)
+}
+
+func unmarshalGenericDoc(jsonSpec []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonSpec))
+	// keep the numbers as is (e.g. large integers in enum values)
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+
+	return doc, nil
+}
+
+func convertSchemaV31ToV30(schema map[string]interface{}) {
+	switch schemaType := schema["type"].(type) {
+	case []interface{}:
+		var types []interface{}
+		for _, t := range schemaType {
+			if t == schemaTypeNull {
+				schema["nullable"] = true
+				continue
+			}
+			types = append(types, t)
+		}
+		delete(schema, "type")
+		switch len(types) {
+		case 0:
+		case 1:
+			schema["type"] = types[0]
+		default:
+			if _, ok := schema["oneOf"]; !ok {
+				oneOf := make([]interface{}, 0, len(types))
+				for _, t := range types {
+					oneOf = append(oneOf, map[string]interface{}{"type": t})
+				}
+				schema["oneOf"] = oneOf
+			}
+		}
+	case string:
+		if schemaType == schemaTypeNull {
+			delete(schema, "type")
+			schema["nullable"] = true
+		}
+	}
+
+	if value, ok := schema["const"]; ok {
+		if _, ok := schema["enum"]; !ok {
+			schema["enum"] = []interface{}{value}
+		}
+		delete(schema, "const")
+	}
+
+	if examples, ok := schema["examples"].([]interface{}); ok {
+		if _, ok := schema["example"]; !ok && len(examples) > 0 {
+			schema["example"] = examples[0]
+		}
+		delete(schema, "examples")
+	}
+
+	convertExclusiveBoundV31ToV30(schema, "exclusiveMinimum", "minimum")
+	convertExclusiveBoundV31ToV30(schema, "exclusiveMaximum", "maximum")
+
+	for _, keyword := range unsupportedSchemaKeywordsV31 {
+		if _, ok := schema[keyword]; ok {
+			log.Warnf("Ignoring OASv3.1 schema keyword %v", keyword)
+			delete(schema, keyword)
+		}
+	}
+}
+
+// convertExclusiveBoundV31ToV30 converts a numeric exclusive bound (e.g. exclusiveMinimum: 5) to a boolean exclusive
+// bound (e.g. minimum: 5, exclusiveMinimum: true).
+func convertExclusiveBoundV31ToV30(schema map[string]interface{}, exclusiveKey, boundKey string) {
+	bound, ok := schema[exclusiveKey].(json.Number)
+	if !ok {
+		return
+	}
+
+	schema[boundKey] = bound
+	schema[exclusiveKey] = true
+}
+
+func convertSchemaV30ToV31(schema map[string]interface{}) {
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if types, format, ok := getPrimitiveOneOfTypes(oneOf); ok {
+			delete(schema, "oneOf")
+			schema["type"] = types
+			if format != nil {
+				schema["format"] = format
+			}
+		}
+	}
+
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		switch schemaType := schema["type"].(type) {
+		case string:
+			schema["type"] = []interface{}{schemaType, schemaTypeNull}
+		case []interface{}:
+			schema["type"] = append(schemaType, schemaTypeNull)
+		default:
+			if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+				schema["oneOf"] = append(oneOf, map[string]interface{}{"type": schemaTypeNull})
+			}
+		}
+	}
+	delete(schema, "nullable")
+
+	if example, ok := schema["example"]; ok {
+		schema["examples"] = []interface{}{example}
+		delete(schema, "example")
+	}
+
+	convertExclusiveBoundV30ToV31(schema, "exclusiveMinimum", "minimum")
+	convertExclusiveBoundV30ToV31(schema, "exclusiveMaximum", "maximum")
+}
+
+// getPrimitiveOneOfTypes returns the types of oneOf schemas that have only a type (and at most one of them has a
+// format), so they can be represented by a `type` array.
+func getPrimitiveOneOfTypes(oneOf []interface{}) (types []interface{}, format interface{}, ok bool) {
+	for _, item := range oneOf {
+		itemSchema, isMap := item.(map[string]interface{})
+		if !isMap {
+			return nil, nil, false
+		}
+		itemType, isString := itemSchema["type"].(string)
+		if !isString {
+			return nil, nil, false
+		}
+		for key, value := range itemSchema {
+			switch key {
+			case "type":
+			case "format":
+				if format != nil {
+					return nil, nil, false
+				}
+				format = value
+			default:
+				return nil, nil, false
+			}
+		}
+		types = append(types, itemType)
+	}
+
+	return types, format, len(types) > 0
+}
+
+// convertExclusiveBoundV30ToV31 converts a boolean exclusive bound (e.g. minimum: 5, exclusiveMinimum: true) to a
+// numeric exclusive bound (e.g. exclusiveMinimum: 5).
+func convertExclusiveBoundV30ToV31(schema map[string]interface{}, exclusiveKey, boundKey string) {
+	exclusive, ok := schema[exclusiveKey].(bool)
+	if !ok {
+		return
+	}
+
+	delete(schema, exclusiveKey)
+	if bound, ok := schema[boundKey]; ok && exclusive {
+		schema[exclusiveKey] = bound
+		delete(schema, boundKey)
+	}
+}
+
+// walkDocSchemas calls convert on every schema of the generic document (paths and components),
+// before walking the schema sub schemas.
+func walkDocSchemas(doc map[string]interface{}, convert func(map[string]interface{})) {
+	for _, pathItem := range getMap(doc, "paths") {
+		walkPathItemSchemas(pathItem, convert)
+	}
+
+	components := getMap(doc, "components")
+	for _, schema := range getMap(components, "schemas") {
+		walkSchema(schema, convert)
+	}
+	for _, param := range getMap(components, "parameters") {
+		walkParameterSchemas(param, convert)
+	}
+	for _, header := range getMap(components, "headers") {
+		walkParameterSchemas(header, convert)
+	}
+	for _, requestBody := range getMap(components, "requestBodies") {
+		walkContentSchemas(requestBody, convert)
+	}
+	for _, response := range getMap(components, "responses") {
+		walkResponseSchemas(response, convert)
+	}
+}
+
+func walkPathItemSchemas(pathItem interface{}, convert func(map[string]interface{})) {
+	pathItemMap, ok := pathItem.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, param := range getSlice(pathItemMap, "parameters") {
+		walkParameterSchemas(param, convert)
+	}
+	for _, method := range pathItemMethods {
+		operation, ok := pathItemMap[method].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, param := range getSlice(operation, "parameters") {
+			walkParameterSchemas(param, convert)
+		}
+		walkContentSchemas(operation["requestBody"], convert)
+		for _, response := range getMap(operation, "responses") {
+			walkResponseSchemas(response, convert)
+		}
+	}
+}
+
+func walkParameterSchemas(param interface{}, convert func(map[string]interface{})) {
+	paramMap, ok := param.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	walkSchema(paramMap["schema"], convert)
+	walkContentSchemas(paramMap, convert)
+}
+
+func walkResponseSchemas(response interface{}, convert func(map[string]interface{})) {
+	responseMap, ok := response.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, header := range getMap(responseMap, "headers") {
+		walkParameterSchemas(header, convert)
+	}
+	walkContentSchemas(responseMap, convert)
+}
+
+// walkContentSchemas walks the schemas of the object (request body, response or parameter) content.
+func walkContentSchemas(obj interface{}, convert func(map[string]interface{})) {
+	objMap, ok := obj.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, mediaType := range getMap(objMap, "content") {
+		if mediaTypeMap, ok := mediaType.(map[string]interface{}); ok {
+			walkSchema(mediaTypeMap["schema"], convert)
+		}
+	}
+}
+
+func walkSchema(schema interface{}, convert func(map[string]interface{})) {
+	schemaMap, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	convert(schemaMap)
+
+	for _, property := range getMap(schemaMap, "properties") {
+		walkSchema(property, convert)
+	}
+	walkSchema(schemaMap["items"], convert)
+	walkSchema(schemaMap["additionalProperties"], convert)
+	walkSchema(schemaMap["not"], convert)
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		for _, item := range getSlice(schemaMap, key) {
+			walkSchema(item, convert)
+		}
+	}
+}
+
+func getMap(obj map[string]interface{}, key string) map[string]interface{} {
+	ret, _ := obj[key].(map[string]interface{})
+	return ret
+}
+
+func getSlice(obj map[string]interface{}, key string) []interface{} {
+	ret, _ := obj[key].([]interface{})
+	return ret
+}
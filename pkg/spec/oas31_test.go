@@ -0,0 +1,250 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+var testProvidedSpecV31 = []byte(`openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: https://example.com/api
+paths:
+  /pets/{petId}:
+    get:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+            exclusiveMinimum: 0
+      responses:
+        '200':
+          description: pet
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+                    examples: [rex]
+                  kind:
+                    const: dog
+                  owner:
+                    type: [string, 'null']
+                  tag:
+                    type: [string, integer]
+components:
+  pathItems:
+    pet:
+      get:
+        responses:
+          '200':
+            description: ok
+webhooks:
+  newPet:
+    post:
+      responses:
+        '200':
+          description: ok
+`)
+
+func TestLoadAndValidateRawJSONSpec_V31(t *testing.T) {
+	doc, version, err := LoadAndValidateRawJSONSpec(testProvidedSpecV31)
+	assert.NilError(t, err)
+	assert.Equal(t, version, OASv31)
+
+	petID := doc.Paths["/pets/{petId}"].Get.Parameters[0].Value.Schema.Value
+	assert.Equal(t, petID.Type, "integer")
+	assert.Equal(t, *petID.Min, float64(0))
+	assert.Equal(t, petID.ExclusiveMin, true)
+
+	properties := doc.Paths["/pets/{petId}"].Get.Responses["200"].Value.Content["application/json"].Schema.Value.Properties
+	assert.Equal(t, properties["name"].Value.Example, "rex")
+	assert.DeepEqual(t, properties["kind"].Value.Enum, []interface{}{"dog"})
+	assert.Equal(t, properties["owner"].Value.Type, "string")
+	assert.Equal(t, properties["owner"].Value.Nullable, true)
+	assert.Equal(t, len(properties["tag"].Value.OneOf), 2)
+}
+
+func TestSpec_DiffTelemetry_ProvidedV31(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(testProvidedSpecV31, map[string]string{"/pets/{petId}": "1"}))
+	assert.Equal(t, s.ProvidedSpec.GetSpecVersion(), OASv31)
+
+	apiDiff, err := s.DiffTelemetry(createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"name": 5}`),
+		SpecSourceProvided, WithDiffMode(DiffModeConformance))
+	assert.NilError(t, err)
+	assert.Equal(t, apiDiff.Type, DiffTypeGeneralDiff)
+	assert.Equal(t, apiDiff.Path, "/api/pets/{petId}")
+}
+
+func Test_convertSchemaV31ToV30(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "nullable type",
+			schema: `{"type": ["string", "null"]}`,
+			want:   `{"type": "string", "nullable": true}`,
+		},
+		{
+			name:   "null type",
+			schema: `{"type": "null"}`,
+			want:   `{"nullable": true}`,
+		},
+		{
+			name:   "multiple types",
+			schema: `{"type": ["string", "integer", "null"]}`,
+			want:   `{"oneOf": [{"type": "string"}, {"type": "integer"}], "nullable": true}`,
+		},
+		{
+			name:   "const",
+			schema: `{"const": 5}`,
+			want:   `{"enum": [5]}`,
+		},
+		{
+			name:   "examples",
+			schema: `{"type": "string", "examples": ["a", "b"]}`,
+			want:   `{"type": "string", "example": "a"}`,
+		},
+		{
+			name:   "exclusive bounds",
+			schema: `{"type": "number", "exclusiveMinimum": 1, "exclusiveMaximum": 10.5}`,
+			want:   `{"type": "number", "minimum": 1, "exclusiveMinimum": true, "maximum": 10.5, "exclusiveMaximum": true}`,
+		},
+		{
+			name:   "unsupported keywords",
+			schema: `{"type": "array", "prefixItems": [{"type": "string"}], "$defs": {"a": {"type": "string"}}, "patternProperties": {"^x-": {}}}`,
+			want:   `{"type": "array"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := unmarshalTestSchema(t, tt.schema)
+			convertSchemaV31ToV30(schema)
+			assert.DeepEqual(t, schema, unmarshalTestSchema(t, tt.want))
+		})
+	}
+}
+
+func Test_convertSchemaV30ToV31(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "nullable type",
+			schema: `{"type": "string", "nullable": true}`,
+			want:   `{"type": ["string", "null"]}`,
+		},
+		{
+			name:   "not nullable",
+			schema: `{"type": "string", "nullable": false}`,
+			want:   `{"type": "string"}`,
+		},
+		{
+			name:   "primitive one of",
+			schema: `{"oneOf": [{"type": "string"}, {"type": "integer", "format": "int64"}, {"type": "boolean"}]}`,
+			want:   `{"type": ["string", "integer", "boolean"], "format": "int64"}`,
+		},
+		{
+			name:   "nullable primitive one of",
+			schema: `{"oneOf": [{"type": "string"}, {"type": "boolean"}], "nullable": true}`,
+			want:   `{"type": ["string", "boolean", "null"]}`,
+		},
+		{
+			name:   "one of with multiple formats",
+			schema: `{"oneOf": [{"type": "string", "format": "uuid"}, {"type": "integer", "format": "int64"}]}`,
+			want:   `{"oneOf": [{"type": "string", "format": "uuid"}, {"type": "integer", "format": "int64"}]}`,
+		},
+		{
+			name:   "one of objects",
+			schema: `{"oneOf": [{"type": "string"}, {"type": "object", "properties": {"a": {"type": "string"}}}]}`,
+			want:   `{"oneOf": [{"type": "string"}, {"type": "object", "properties": {"a": {"type": "string"}}}]}`,
+		},
+		{
+			name:   "example",
+			schema: `{"type": "string", "example": "a"}`,
+			want:   `{"type": "string", "examples": ["a"]}`,
+		},
+		{
+			name:   "exclusive bounds",
+			schema: `{"type": "number", "minimum": 1, "exclusiveMinimum": true, "maximum": 10, "exclusiveMaximum": false}`,
+			want:   `{"type": "number", "exclusiveMinimum": 1, "maximum": 10}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := unmarshalTestSchema(t, tt.schema)
+			convertSchemaV30ToV31(schema)
+			assert.DeepEqual(t, schema, unmarshalTestSchema(t, tt.want))
+		})
+	}
+}
+
+func TestSpec_GenerateOASJson_V31(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LearnTelemetry(createTelemetry("1", http.MethodGet, "/pets", "host", "200", "", `{"tags": ["a", true]}`)))
+	approveSuggestedReview(t, s)
+
+	generated, err := s.GenerateOASJson(OASv31)
+	assert.NilError(t, err)
+
+	doc, err := unmarshalGenericDoc(generated)
+	assert.NilError(t, err)
+	assert.Equal(t, doc["openapi"], openAPIVersion31)
+
+	var schema map[string]interface{}
+	walkDocSchemas(doc, func(s map[string]interface{}) {
+		if getMap(s, "properties")["tags"] != nil {
+			schema = s
+		}
+	})
+	assert.Assert(t, schema != nil)
+	// mixed type arrays are learned as oneOf
+	tags := getMap(getMap(schema, "properties"), "tags")
+	assert.Equal(t, len(tags["type"].([]interface{})), 2)
+	assert.Assert(t, tags["oneOf"] == nil)
+
+	_, version, err := LoadAndValidateRawJSONSpec(generated)
+	assert.NilError(t, err)
+	assert.Equal(t, version, OASv31)
+}
+
+func unmarshalTestSchema(t *testing.T, schema string) map[string]interface{} {
+	t.Helper()
+
+	var ret map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(schema)))
+	decoder.UseNumber()
+	assert.NilError(t, decoder.Decode(&ret))
+
+	return ret
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"mime"
 	"net/url"
+	"sort"
 	"strings"
 
 	spec "github.com/getkin/kin-openapi/openapi3"
@@ -144,11 +145,16 @@ func getArraySchema(value interface{}) (schema *spec.Schema, err error) {
 			break
 		}
 	default:
-		// oneOf
+		// oneOf, sorted by the schema type for a stable order
 		// https://swagger.io/docs/specification/data-models/oneof-anyof-allof-not/
+		schemaTypes := make([]string, 0, len(schemaTypeToSchema))
+		for schemaType := range schemaTypeToSchema {
+			schemaTypes = append(schemaTypes, schemaType)
+		}
+		sort.Strings(schemaTypes)
 		var schemas []*spec.Schema
-		for _, s := range schemaTypeToSchema {
-			schemas = append(schemas, s)
+		for _, schemaType := range schemaTypes {
+			schemas = append(schemas, schemaTypeToSchema[schemaType])
 		}
 		schema = spec.NewOneOfSchema(schemas...)
 	}
@@ -235,7 +241,8 @@ func (o *OperationGenerator) GenerateSpecOperation(data *HTTPInteractionData, se
 		}
 	}
 
-	for key, value := range data.ReqHeaders {
+	for _, key := range sortedHeaderNames(data.ReqHeaders) {
+		value := data.ReqHeaders[key]
 		lowerKey := strings.ToLower(key)
 		if lowerKey == authorizationTypeHeaderName {
 			// https://datatracker.ietf.org/doc/html/rfc6750#section-2.1
@@ -251,7 +258,8 @@ func (o *OperationGenerator) GenerateSpecOperation(data *HTTPInteractionData, se
 		}
 	}
 
-	for key, values := range data.QueryParams {
+	for _, key := range sortedQueryParamNames(data.QueryParams) {
+		values := data.QueryParams[key]
 		lowerKey := strings.ToLower(key)
 		if lowerKey == AccessTokenParamKey {
 			// https://datatracker.ietf.org/doc/html/rfc6750#section-2.3
@@ -296,9 +304,10 @@ func (o *OperationGenerator) GenerateSpecOperation(data *HTTPInteractionData, se
 		}
 	}
 
-	for key, value := range data.RespHeaders {
-		response = o.addResponseHeader(response, key, value)
+	for _, key := range sortedHeaderNames(data.RespHeaders) {
+		response = o.addResponseHeader(response, key, data.RespHeaders[key])
 	}
+	setOperationDeprecation(operation, data.RespHeaders)
 
 	operation.AddResponse(data.statusCode, response)
 	operation.AddResponse(0 /*"default"*/, spec.NewResponse().WithDescription("default"))
@@ -306,6 +315,27 @@ func (o *OperationGenerator) GenerateSpecOperation(data *HTTPInteractionData, se
 	return operation, nil
 }
 
+// sortedHeaderNames returns the header names sorted, so the learned parameters and security are in a stable order.
+func sortedHeaderNames(headers map[string]string) []string {
+	ret := make([]string, 0, len(headers))
+	for name := range headers {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func sortedQueryParamNames(queryParams url.Values) []string {
+	ret := make([]string, 0, len(queryParams))
+	for name := range queryParams {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
 func operationSetRequestBody(operation *spec.Operation, reqBody *spec.RequestBody) {
 	operation.RequestBody = &spec.RequestBodyRef{Value: reqBody}
 }
@@ -0,0 +1,288 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+// OperationOverride overrides the inferred operation id, summary and tags of the operations of an approved path.
+type OperationOverride struct {
+	// OperationIDs by method (e.g. GET)
+	OperationIDs map[string]string `json:"operationIds,omitempty"`
+	// Summaries by method (e.g. GET)
+	Summaries map[string]string `json:"summaries,omitempty"`
+	// Tags of all the path operations
+	Tags []string `json:"tags,omitempty"`
+}
+
+// SetOperationOverride sets the operation id, summary and tags override of the approved path operations.
+// The override is applied on the approved spec, and on the path operations approved later on.
+func (s *Spec) SetOperationOverride(path string, override *OperationOverride) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.HasApprovedSpec() || s.ApprovedSpec.GetPathItem(path) == nil {
+		return fmt.Errorf("path %v was not found in the approved spec", path)
+	}
+
+	if override != nil {
+		normalized := &OperationOverride{
+			OperationIDs: make(map[string]string, len(override.OperationIDs)),
+			Summaries:    make(map[string]string, len(override.Summaries)),
+			Tags:         override.Tags,
+		}
+		for method, operationID := range override.OperationIDs {
+			if operationID != "" && s.ApprovedSpec.isOperationIDUsed(operationID, path, strings.ToUpper(method)) {
+				return fmt.Errorf("operation id %q is already used by another operation", operationID)
+			}
+			normalized.OperationIDs[strings.ToUpper(method)] = operationID
+		}
+		for method, summary := range override.Summaries {
+			normalized.Summaries[strings.ToUpper(method)] = summary
+		}
+		override = normalized
+	}
+
+	if s.OperationOverrides == nil {
+		s.OperationOverrides = make(map[string]*OperationOverride)
+	}
+	s.OperationOverrides[path] = override
+	setOperationsInfo(s.ApprovedSpec.PathItems, s.OperationOverrides)
+
+	return nil
+}
+
+// isOperationIDUsed checks if the operation id is used by an approved operation other than the given one.
+func (a *ApprovedSpec) isOperationIDUsed(operationID, path, method string) bool {
+	for approvedPath, pathItem := range a.PathItems {
+		for approvedMethod, operation := range pathItem.Operations() {
+			if approvedPath == path && approvedMethod == method {
+				continue
+			}
+			if operation.OperationID == operationID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// setOperationsInfo applies the overrides, and sets the inferred operation id, summary and tags of the operations
+// that do not have them, so existing values are preserved. Inferred operation ids are unique.
+func setOperationsInfo(pathItems map[string]*oapi_spec.PathItem, overrides map[string]*OperationOverride) {
+	paths := sortedPaths(pathItems)
+
+	operationIDs := make(map[string]bool)
+	for _, path := range paths {
+		operations := pathItems[path].Operations()
+		for _, method := range sortedUnionMethods(operations, nil) {
+			operation := operations[method]
+			applyOperationOverride(operation, method, overrides[path])
+			if operation.OperationID != "" {
+				operationIDs[operation.OperationID] = true
+			}
+		}
+	}
+
+	for _, path := range paths {
+		operations := pathItems[path].Operations()
+		for _, method := range sortedUnionMethods(operations, nil) {
+			operation := operations[method]
+			if operation.OperationID == "" {
+				operation.OperationID = getUniqueOperationID(operationIDs, inferOperationID(method, path))
+				operationIDs[operation.OperationID] = true
+			}
+			if operation.Summary == "" {
+				operation.Summary = inferOperationSummary(method, path)
+			}
+			if len(operation.Tags) == 0 {
+				operation.Tags = inferOperationTags(path)
+			}
+		}
+	}
+}
+
+func applyOperationOverride(operation *oapi_spec.Operation, method string, override *OperationOverride) {
+	if override == nil {
+		return
+	}
+
+	if operationID := override.OperationIDs[method]; operationID != "" {
+		operation.OperationID = operationID
+	}
+	if summary := override.Summaries[method]; summary != "" {
+		operation.Summary = summary
+	}
+	if len(override.Tags) > 0 {
+		operation.Tags = override.Tags
+	}
+}
+
+func getUniqueOperationID(operationIDs map[string]bool, operationID string) string {
+	if !operationIDs[operationID] {
+		return operationID
+	}
+
+	for counter := 2; ; counter++ {
+		suggestedOperationID := fmt.Sprintf("%s%d", operationID, counter)
+		if !operationIDs[suggestedOperationID] {
+			return suggestedOperationID
+		}
+	}
+}
+
+// inferOperationID infers the operation id from the method and the path template,
+// e.g. GET /users/{userId} -> getUser, GET /users -> listUsers, POST /users/{userId}/orders -> createUserOrder.
+func inferOperationID(method, path string) string {
+	verb, words := getOperationNameWords(method, path)
+
+	var sb strings.Builder
+	sb.WriteString(verb)
+	for _, word := range words {
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return sb.String()
+}
+
+// inferOperationSummary infers the operation summary from the method and the path template,
+// e.g. GET /users/{userId} -> Get user.
+func inferOperationSummary(method, path string) string {
+	verb, words := getOperationNameWords(method, path)
+	for i, word := range words {
+		words[i] = strings.ToLower(word[:1]) + word[1:]
+	}
+
+	return strings.ToUpper(verb[:1]) + verb[1:] + " " + strings.Join(words, " ")
+}
+
+// inferOperationTags returns the first static segment of the path template as the operation tag.
+func inferOperationTags(path string) []string {
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !utils.HasPathParam(segment) {
+			return []string{segment}
+		}
+	}
+
+	return nil
+}
+
+// getOperationNameWords returns the verb and the resource words of the operation. The static segments that are
+// followed by a path param are singular (e.g. users/{userId} -> user), and the last static segment is plural
+// only for collection operations.
+func getOperationNameWords(method, path string) (verb string, words []string) {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	isItem := len(segments) > 0 && utils.HasPathParam(segments[len(segments)-1])
+
+	var staticSegments []string
+	for _, segment := range segments {
+		if !utils.HasPathParam(segment) {
+			staticSegments = append(staticSegments, segment)
+		}
+	}
+
+	isCollection := false
+	for i, segment := range staticSegments {
+		segmentWords := strings.Fields(nonAlphanumericChar.ReplaceAllString(segment, " "))
+		if len(segmentWords) == 0 {
+			continue
+		}
+
+		lastWord := segmentWords[len(segmentWords)-1]
+		isLast := i == len(staticSegments)-1
+		switch {
+		case !isLast || isItem:
+			segmentWords[len(segmentWords)-1] = singularize(lastWord)
+		case singularize(lastWord) != lastWord:
+			isCollection = true
+			if method == http.MethodPost {
+				segmentWords[len(segmentWords)-1] = singularize(lastWord)
+			}
+		}
+		words = append(words, segmentWords...)
+	}
+	if len(words) == 0 {
+		words = []string{"root"}
+	}
+
+	return getOperationVerb(method, isCollection), words
+}
+
+func getOperationVerb(method string, isCollection bool) string {
+	switch method {
+	case http.MethodGet:
+		if isCollection {
+			return "list"
+		}
+		return "get"
+	case http.MethodPost:
+		if isCollection {
+			return "create"
+		}
+		return "post"
+	case http.MethodPut:
+		return "update"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// mergeOperationInfo keeps the operation id, summary and description of the first operation that has them,
+// and the tags of both operations, so they are preserved when the approved operation evolves.
+func mergeOperationInfo(merged, operation, operation2 *oapi_spec.Operation) {
+	merged.OperationID = operation.OperationID
+	if merged.OperationID == "" {
+		merged.OperationID = operation2.OperationID
+	}
+	merged.Summary = operation.Summary
+	if merged.Summary == "" {
+		merged.Summary = operation2.Summary
+	}
+	merged.Description = operation.Description
+	if merged.Description == "" {
+		merged.Description = operation2.Description
+	}
+
+	tags := make(map[string]bool)
+	for _, tag := range append(append([]string{}, operation.Tags...), operation2.Tags...) {
+		if !tags[tag] {
+			merged.Tags = append(merged.Tags, tag)
+			tags[tag] = true
+		}
+	}
+}
+
+// copyOperationInfo copies the spec operation id, summary, description and tags to the telemetry operation,
+// since they can not be observed from the telemetry.
+func copyOperationInfo(telemetryOp, specOp *oapi_spec.Operation) {
+	telemetryOp.OperationID = specOp.OperationID
+	telemetryOp.Summary = specOp.Summary
+	telemetryOp.Description = specOp.Description
+	telemetryOp.Tags = specOp.Tags
+}
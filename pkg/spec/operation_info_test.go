@@ -0,0 +1,200 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"net/http"
+	"testing"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+func Test_inferOperationInfo(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		wantOperationID string
+		wantSummary     string
+		wantTags        []string
+	}{
+		{
+			name:            "list collection",
+			method:          http.MethodGet,
+			path:            "/users",
+			wantOperationID: "listUsers",
+			wantSummary:     "List users",
+			wantTags:        []string{"users"},
+		},
+		{
+			name:            "get item",
+			method:          http.MethodGet,
+			path:            "/users/{userId}",
+			wantOperationID: "getUser",
+			wantSummary:     "Get user",
+			wantTags:        []string{"users"},
+		},
+		{
+			name:            "create item",
+			method:          http.MethodPost,
+			path:            "/users",
+			wantOperationID: "createUser",
+			wantSummary:     "Create user",
+			wantTags:        []string{"users"},
+		},
+		{
+			name:            "update item",
+			method:          http.MethodPut,
+			path:            "/users/{userId}",
+			wantOperationID: "updateUser",
+			wantSummary:     "Update user",
+			wantTags:        []string{"users"},
+		},
+		{
+			name:            "delete item",
+			method:          http.MethodDelete,
+			path:            "/users/{userId}",
+			wantOperationID: "deleteUser",
+			wantSummary:     "Delete user",
+			wantTags:        []string{"users"},
+		},
+		{
+			name:            "nested collection",
+			method:          http.MethodGet,
+			path:            "/users/{userId}/orders",
+			wantOperationID: "listUserOrders",
+			wantSummary:     "List user orders",
+			wantTags:        []string{"users"},
+		},
+		{
+			name:            "nested create with multi word segment",
+			method:          http.MethodPost,
+			path:            "/users/{userId}/order-items",
+			wantOperationID: "createUserOrderItem",
+			wantSummary:     "Create user order item",
+			wantTags:        []string{"users"},
+		},
+		{
+			name:            "singular resource",
+			method:          http.MethodGet,
+			path:            "/api/status",
+			wantOperationID: "getApiStatus",
+			wantSummary:     "Get api status",
+			wantTags:        []string{"api"},
+		},
+		{
+			name:            "root",
+			method:          http.MethodGet,
+			path:            "/",
+			wantOperationID: "getRoot",
+			wantSummary:     "Get root",
+		},
+		{
+			name:            "only path param",
+			method:          http.MethodOptions,
+			path:            "/{id}",
+			wantOperationID: "optionsRoot",
+			wantSummary:     "Options root",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, inferOperationID(tt.method, tt.path), tt.wantOperationID)
+			assert.Equal(t, inferOperationSummary(tt.method, tt.path), tt.wantSummary)
+			assert.DeepEqual(t, inferOperationTags(tt.path), tt.wantTags)
+		})
+	}
+}
+
+func Test_setOperationsInfo(t *testing.T) {
+	pathItems := map[string]*oapi_spec.PathItem{
+		"/user/{id}":  {Get: oapi_spec.NewOperation()},
+		"/users/{id}": {Get: oapi_spec.NewOperation(), Put: oapi_spec.NewOperation()},
+		"/users":      {Get: &oapi_spec.Operation{OperationID: "getUser", Summary: "Existing summary"}},
+		"/pets":       {Get: oapi_spec.NewOperation(), Post: oapi_spec.NewOperation()},
+	}
+	overrides := map[string]*OperationOverride{
+		"/pets": {
+			OperationIDs: map[string]string{http.MethodPost: "addPet"},
+			Tags:         []string{"animals"},
+		},
+	}
+
+	setOperationsInfo(pathItems, overrides)
+
+	// existing operation ids are kept, and inferred operation ids are unique
+	assert.Equal(t, pathItems["/users"].Get.OperationID, "getUser")
+	assert.Equal(t, pathItems["/users"].Get.Summary, "Existing summary")
+	assert.Equal(t, pathItems["/user/{id}"].Get.OperationID, "getUser2")
+	assert.Equal(t, pathItems["/users/{id}"].Get.OperationID, "getUser3")
+	assert.Equal(t, pathItems["/users/{id}"].Put.OperationID, "updateUser")
+	// overrides win over the inferred values
+	assert.Equal(t, pathItems["/pets"].Post.OperationID, "addPet")
+	assert.Equal(t, pathItems["/pets"].Post.Summary, "Create pet")
+	assert.DeepEqual(t, pathItems["/pets"].Get.Tags, []string{"animals"})
+	assert.Equal(t, pathItems["/pets"].Get.OperationID, "listPets")
+}
+
+func TestSpec_SetOperationOverride(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("1", http.MethodGet, "/users", "example.com", "200", "", ""),
+		createTelemetry("2", http.MethodPost, "/users", "example.com", "201", "", ""),
+		createTelemetry("3", http.MethodGet, "/pets", "example.com", "200", "", ""),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+	approveSuggestedReview(t, s)
+	assert.Equal(t, s.ApprovedSpec.GetPathItem("/users").Get.OperationID, "listUsers")
+	assert.Equal(t, s.ApprovedSpec.GetPathItem("/users").Post.OperationID, "createUser")
+
+	assert.Assert(t, s.SetOperationOverride("/unknown", &OperationOverride{Tags: []string{"a"}}) != nil)
+	// the operation id of another operation can't be used
+	assert.Assert(t, s.SetOperationOverride("/users", &OperationOverride{
+		OperationIDs: map[string]string{"get": "listPets"},
+	}) != nil)
+
+	assert.NilError(t, s.SetOperationOverride("/users", &OperationOverride{
+		OperationIDs: map[string]string{"get": "findUsers"},
+		Summaries:    map[string]string{"post": "Register a user"},
+		Tags:         []string{"accounts"},
+	}))
+	assert.Equal(t, s.ApprovedSpec.GetPathItem("/users").Get.OperationID, "findUsers")
+	assert.Equal(t, s.ApprovedSpec.GetPathItem("/users").Post.Summary, "Register a user")
+	assert.DeepEqual(t, s.ApprovedSpec.GetPathItem("/users").Post.Tags, []string{"accounts"})
+
+	// the values are preserved across re-approvals, and a new operation of the path gets the override
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("4", http.MethodGet, "/users", "example.com", "200", "", `{"name": "a"}`),
+		createTelemetry("5", http.MethodDelete, "/users", "example.com", "204", "", ""),
+		createTelemetry("6", http.MethodPost, "/users", "example.com", "201", "", ""),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+	approveSuggestedReview(t, s)
+	pathItem := s.ApprovedSpec.GetPathItem("/users")
+	assert.Equal(t, pathItem.Get.OperationID, "findUsers")
+	assert.Equal(t, pathItem.Post.Summary, "Register a user")
+	assert.Equal(t, pathItem.Delete.OperationID, "deleteUsers")
+	assert.DeepEqual(t, pathItem.Delete.Tags, []string{"accounts"})
+	assert.Equal(t, s.ApprovedSpec.GetPathItem("/pets").Get.OperationID, "listPets")
+
+	// the approved operation info is not reported as a diff
+	diff, err := s.DiffTelemetry(createTelemetry("6", http.MethodGet, "/pets", "example.com", "200", "", ""), SpecSourceReconstructed)
+	assert.NilError(t, err)
+	assert.Equal(t, diff.Type, DiffTypeNoDiff)
+}
@@ -426,6 +426,55 @@ func Test_getNumberSchema(t *testing.T) {
 	}
 }
 
+func Test_getArraySchema(t *testing.T) {
+	type args struct {
+		value interface{}
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantSchema *spec.Schema
+	}{
+		{
+			name: "empty",
+			args: args{
+				value: []interface{}{},
+			},
+			wantSchema: spec.NewArraySchema().WithItems(spec.NewStringSchema()),
+		},
+		{
+			name: "single type",
+			args: args{
+				value: []interface{}{true, false},
+			},
+			wantSchema: spec.NewArraySchema().WithItems(spec.NewBoolSchema()),
+		},
+		{
+			name: "mixed types are sorted by type",
+			args: args{
+				value: []interface{}{"a", json.Number("1"), true},
+			},
+			wantSchema: spec.NewOneOfSchema(spec.NewBoolSchema(), spec.NewInt64Schema(), spec.NewStringSchema()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// map iteration order is random, so repeat to verify a stable order
+			for i := 0; i < 10; i++ {
+				gotSchema, err := getArraySchema(tt.args.value)
+				if err != nil {
+					t.Errorf("getArraySchema() error = %v", err)
+					return
+				}
+				if !reflect.DeepEqual(gotSchema, tt.wantSchema) {
+					t.Errorf("getArraySchema() = %v, want %v", gotSchema, tt.wantSchema)
+					return
+				}
+			}
+		})
+	}
+}
+
 func Test_escapeString(t *testing.T) {
 	type args struct {
 		key string
@@ -0,0 +1,96 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"net"
+
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openclarity/speculator/pkg/pathtrie"
+)
+
+type PathIDParams struct {
+	callerPathIDsOnly bool
+}
+
+type PathIDOption func(*PathIDParams)
+
+// WithCallerPathIDsOnly keeps only the caller-supplied path IDs, the paths without a path ID are not added to the
+// provided spec path trie (and so are not matched by GetPathID and DiffTelemetry).
+func WithCallerPathIDsOnly() PathIDOption {
+	return func(params *PathIDParams) {
+		params.callerPathIDsOnly = true
+	}
+}
+
+func createPathIDParams(opts []PathIDOption) *PathIDParams {
+	params := &PathIDParams{}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	return params
+}
+
+// GeneratePathID returns a deterministic path ID (UUIDv5) of the path template in the spec.
+// It is used for the provided spec paths that have no path ID in pathToPathID (see WithCallerPathIDsOnly), and for
+// the approved review paths that have no PathUUID.
+func (s *Spec) GeneratePathID(pathTemplate string) string {
+	return uuid.NewV5(s.getPathIDNamespace(), pathTemplate).String()
+}
+
+// getPathIDNamespace returns the spec ID, or a UUIDv5 of the spec host and port if the spec ID is not set,
+// so the same path template gets a different ID in different specs.
+func (s *Spec) getPathIDNamespace() uuid.UUID {
+	if s.ID != uuid.Nil {
+		return s.ID
+	}
+
+	return uuid.NewV5(uuid.Nil, net.JoinHostPort(s.Host, s.Port))
+}
+
+// GetPathIDs returns the path template to path ID mapping of the spec source.
+// The provided spec path templates are without the spec base path.
+func (s *Spec) GetPathIDs(specSource SpecSource) (map[string]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var pathTrie *pathtrie.PathTrie
+	switch specSource {
+	case SpecSourceProvided:
+		pathTrie = &s.ProvidedPathTrie
+	case SpecSourceReconstructed:
+		pathTrie = &s.ApprovedPathTrie
+	default:
+		return nil, fmt.Errorf("spec source: %v is not valid", specSource)
+	}
+
+	ret := make(map[string]string)
+	pathTrie.Walk(func(path string, value interface{}) bool {
+		pathID, ok := value.(string)
+		if !ok {
+			log.Warnf("value is not a string. %v", value)
+			return true
+		}
+		ret[path] = pathID
+		return true
+	})
+
+	return ret, nil
+}
@@ -0,0 +1,143 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"gotest.tools/assert"
+)
+
+func TestSpec_GeneratePathID(t *testing.T) {
+	specID := uuid.NewV4()
+	tests := []struct {
+		name      string
+		spec      *Spec
+		other     *Spec
+		path      string
+		otherPath string
+		wantSame  bool
+	}{
+		{
+			name:      "same spec and path",
+			spec:      CreateDefaultSpec("host", "80", testOperationGeneratorConfig),
+			other:     CreateDefaultSpec("host", "80", testOperationGeneratorConfig),
+			path:      "/users/{id}",
+			otherPath: "/users/{id}",
+			wantSame:  true,
+		},
+		{
+			name:      "same spec and different path",
+			spec:      CreateDefaultSpec("host", "80", testOperationGeneratorConfig),
+			other:     CreateDefaultSpec("host", "80", testOperationGeneratorConfig),
+			path:      "/users/{id}",
+			otherPath: "/users",
+			wantSame:  false,
+		},
+		{
+			name:      "different spec host and port",
+			spec:      CreateDefaultSpec("host", "80", testOperationGeneratorConfig),
+			other:     CreateDefaultSpec("host", "8080", testOperationGeneratorConfig),
+			path:      "/users/{id}",
+			otherPath: "/users/{id}",
+			wantSame:  false,
+		},
+		{
+			name:      "same spec ID and different host",
+			spec:      &Spec{SpecInfo: SpecInfo{ID: specID, Host: "host"}},
+			other:     &Spec{SpecInfo: SpecInfo{ID: specID, Host: "other"}},
+			path:      "/users/{id}",
+			otherPath: "/users/{id}",
+			wantSame:  true,
+		},
+		{
+			name:      "different spec ID",
+			spec:      &Spec{SpecInfo: SpecInfo{ID: specID}},
+			other:     &Spec{SpecInfo: SpecInfo{ID: uuid.NewV4()}},
+			path:      "/users/{id}",
+			otherPath: "/users/{id}",
+			wantSame:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pathID := tt.spec.GeneratePathID(tt.path)
+			_, err := uuid.FromString(pathID)
+			assert.NilError(t, err)
+			assert.Equal(t, pathID == tt.other.GeneratePathID(tt.otherPath), tt.wantSame)
+		})
+	}
+}
+
+func TestSpec_GetPathIDs(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{}))
+	assert.NilError(t, s.LearnTelemetry(createTelemetry("1", "GET", "/users/1", "example.com", "200", "", "")))
+	assert.NilError(t, s.LearnTelemetry(createTelemetry("2", "GET", "/users/2", "example.com", "200", "", "")))
+	// approve without path UUIDs
+	approvedReview := &ApprovedSpecReview{PathToPathItem: s.LearningSpec.PathItems}
+	for _, item := range s.CreateSuggestedReview().PathItemsReview {
+		approvedReview.PathItemsReview = append(approvedReview.PathItemsReview, &ApprovedSpecReviewPathItem{
+			ReviewPathItem: item.ReviewPathItem,
+		})
+	}
+	assert.NilError(t, s.ApplyApprovedReview(approvedReview, OASv3))
+
+	tests := []struct {
+		name       string
+		specSource SpecSource
+		want       map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "provided",
+			specSource: SpecSourceProvided,
+			want: map[string]string{
+				"/pets/{petId}": s.GeneratePathID("/pets/{petId}"),
+			},
+		},
+		{
+			name:       "reconstructed",
+			specSource: SpecSourceReconstructed,
+			want: map[string]string{
+				"/users/{userId}": s.GeneratePathID("/users/{userId}"),
+			},
+		},
+		{
+			name:       "invalid spec source",
+			specSource: "invalid",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetPathIDs(tt.specSource)
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+
+			for path, pathID := range got {
+				gotPathID, err := s.GetPathID(path, tt.specSource)
+				assert.NilError(t, err)
+				assert.Equal(t, gotPathID, pathID)
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ import (
 
 	spec "github.com/getkin/kin-openapi/openapi3"
 	uuid "github.com/satori/go.uuid"
+
+	"github.com/openclarity/speculator/pkg/utils"
 )
 
 type PathParam struct {
@@ -33,29 +35,173 @@ func generateParamName(i int) string {
 	return fmt.Sprintf("param%v", i)
 }
 
-var digitCheck = regexp.MustCompile(`^[0-9]+$`)
+var (
+	digitCheck          = regexp.MustCompile(`^[0-9]+$`)
+	lettersCheck        = regexp.MustCompile(`^[a-zA-Z]+$`)
+	nonAlphanumericChar = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+	// splits a segment by common delimiters, uuids are kept as a single token
+	segmentTokens = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[^-_.]+|[-_.]`)
+)
+
+// paramSegment is a placeholder for a parameter segment that wasn't named yet.
+const paramSegment = utils.ParamPrefix + utils.ParamSuffix
 
 func createParameterizedPath(path string) string {
 	var ParameterizedPathParts []string
-	paramCount := 0
 	pathParts := strings.Split(path, "/")
 
 	for _, part := range pathParts {
-		// if part is a suspect param, replace it with a param name, otherwise do nothing
-		if isSuspectPathParam(part) {
-			paramCount++
-			paramName := generateParamName(paramCount)
-			ParameterizedPathParts = append(ParameterizedPathParts, "{"+paramName+"}")
+		// if part has a suspect param embedded in it, replace only the param (e.g. report-123 -> report-{}),
+		// if part is a suspect param, replace it with a param placeholder, otherwise do nothing
+		if partialParameterizedPart, ok := createPartialParameterizedSegment(part); ok {
+			ParameterizedPathParts = append(ParameterizedPathParts, partialParameterizedPart)
+		} else if isSuspectPathParam(part) {
+			ParameterizedPathParts = append(ParameterizedPathParts, paramSegment)
 		} else {
 			ParameterizedPathParts = append(ParameterizedPathParts, part)
 		}
 	}
 
-	parameterizedPath := strings.Join(ParameterizedPathParts, "/")
+	parameterizedPath := strings.Join(nameParamSegments(ParameterizedPathParts), "/")
 
 	return parameterizedPath
 }
 
+// createPartialParameterizedSegment replaces suspect params that are embedded in a segment with a param placeholder,
+// e.g. report-123 -> report-{}, 1234.json -> {}.json.
+// A segment will be parameterized only if it also includes a word, so values like 1.2.3 will be kept as is.
+func createPartialParameterizedSegment(segment string) (string, bool) {
+	tokens := segmentTokens.FindAllString(segment, -1)
+	hasParam, hasWord := false, false
+
+	for i, token := range tokens {
+		switch {
+		case isSuspectPathParam(token):
+			tokens[i] = paramSegment
+			hasParam = true
+		case lettersCheck.MatchString(token):
+			hasWord = true
+		}
+	}
+
+	if !hasParam || !hasWord {
+		return "", false
+	}
+
+	return strings.Join(tokens, ""), true
+}
+
+// nameParamSegments names every path param placeholder after the preceding static segment,
+// e.g. /users/{}/orders/{} will be named /users/{userId}/orders/{orderId}.
+// Params that are embedded in a segment are named after the preceding static part of the segment,
+// e.g. /reports/report-{} will be named /reports/report-{reportId}.
+// In case a name can't be derived, param<N> will be used instead (N is the param position in the path).
+func nameParamSegments(segments []string) []string {
+	ret := make([]string, len(segments))
+	usedNames := make(map[string]bool)
+	paramCount := 0
+
+	for i, segment := range segments {
+		if !utils.HasPathParam(segment) {
+			ret[i] = segment
+			continue
+		}
+
+		var prevStaticSegment string
+		if i > 0 && !utils.HasPathParam(segments[i-1]) {
+			prevStaticSegment = segments[i-1]
+		}
+
+		tokens := utils.SplitPathSegmentTemplate(segment)
+		for j, token := range tokens {
+			if !utils.IsPathParam(token) {
+				continue
+			}
+			paramCount++
+
+			var name string
+			if j == 0 {
+				name = createSemanticParamName(prevStaticSegment)
+			} else if !utils.IsPathParam(tokens[j-1]) {
+				name = createSemanticParamName(tokens[j-1])
+			}
+			if name == "" {
+				name = generateParamName(paramCount)
+			}
+			name = getUniqueParamName(usedNames, name)
+			usedNames[name] = true
+
+			tokens[j] = utils.ParamPrefix + name + utils.ParamSuffix
+		}
+		ret[i] = strings.Join(tokens, "")
+	}
+
+	return ret
+}
+
+// createSemanticParamName returns a param name from a static path segment (singular + `Id`),
+// e.g. `users` -> `userId`, `order-items` -> `orderItemId`, or an empty string if a name can't be created.
+func createSemanticParamName(segment string) string {
+	words := strings.Fields(nonAlphanumericChar.ReplaceAllString(segment, " "))
+	if len(words) == 0 {
+		return ""
+	}
+
+	// a valid name should not start with a digit
+	if unicode.IsDigit(rune(words[0][0])) {
+		return ""
+	}
+
+	words[len(words)-1] = singularize(words[len(words)-1])
+
+	var name strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			name.WriteString(strings.ToLower(word[:1]) + word[1:])
+		} else {
+			name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	name.WriteString("Id")
+
+	return name.String()
+}
+
+func singularize(word string) string {
+	lowerWord := strings.ToLower(word)
+
+	switch {
+	case strings.HasSuffix(lowerWord, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(lowerWord, "sses"),
+		strings.HasSuffix(lowerWord, "xes"),
+		strings.HasSuffix(lowerWord, "ches"),
+		strings.HasSuffix(lowerWord, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lowerWord, "ss"), strings.HasSuffix(lowerWord, "us"):
+		return word
+	case strings.HasSuffix(lowerWord, "s") && len(word) > 1:
+		return word[:len(word)-1]
+	}
+
+	return word
+}
+
+func getUniqueParamName(usedNames map[string]bool, name string) string {
+	if !usedNames[name] {
+		return name
+	}
+
+	counter := 2
+	for {
+		suggestedName := fmt.Sprintf("%s%d", name, counter)
+		if !usedNames[suggestedName] {
+			return suggestedName
+		}
+		counter++
+	}
+}
+
 type paramFormat string
 
 const (
@@ -0,0 +1,253 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+const (
+	// Minimum number of distinct sibling values (sharing the same operations) needed
+	// in order to treat a path segment as a parameter.
+	cardinalityMinDistinctValues = 5
+	// Minimum ratio between the number of clustered sibling values and the number of all
+	// siblings under the same prefix. Resource names (e.g. /api/users, /api/orders) tend to
+	// behave differently from each other while identifiers (e.g. /users/alice, /users/bob)
+	// behave the same, so a low ratio means the siblings are most likely static segments.
+	cardinalityMinVarietyRatio = 0.8
+	// Maximum ratio of resource like values (plural words, e.g. users, orders) in a cluster, unless most of the
+	// values are identifier like (e.g. 123, r1, a1b2c3). Collection names behave the same when they only serve
+	// the same methods, but they are not identifiers.
+	cardinalityMaxResourceNameRatio = 0.5
+)
+
+type pathSegmentNode struct {
+	children map[string]*pathSegmentNode
+	// learned paths that are represented by this node
+	paths map[string]bool
+	// methods of the learned path items represented by this node
+	methods map[string]bool
+}
+
+func newPathSegmentNode() *pathSegmentNode {
+	return &pathSegmentNode{
+		children: make(map[string]*pathSegmentNode),
+		paths:    make(map[string]bool),
+		methods:  make(map[string]bool),
+	}
+}
+
+// parameterizeByCardinality clusters sibling segments under a common prefix into a single path parameter.
+// e.g. /users/alice, /users/bob, ... will be grouped into /users/{userId}.
+// A group of siblings is clustered when the number of distinct values and their variety are above thresholds,
+// and their subtrees share the same operations.
+// parameterizedPaths maps a parameterized path into the learned paths it represents.
+func parameterizeByCardinality(parameterizedPaths map[string]map[string]bool, pathItems map[string]*oapi_spec.PathItem) map[string]map[string]bool {
+	root := newPathSegmentNode()
+
+	for parameterizedPath, paths := range parameterizedPaths {
+		node := root
+		for _, segment := range strings.Split(parameterizedPath, "/") {
+			// params are named on output
+			segment = utils.ClearPathParamNames(segment)
+			child, ok := node.children[segment]
+			if !ok {
+				child = newPathSegmentNode()
+				node.children[segment] = child
+			}
+			node = child
+		}
+		for path := range paths {
+			node.paths[path] = true
+			if pathItem, ok := pathItems[path]; ok && pathItem != nil {
+				for method := range pathItem.Operations() {
+					node.methods[method] = true
+				}
+			}
+		}
+	}
+
+	root.clusterChildren()
+
+	ret := make(map[string]map[string]bool)
+	for _, child := range root.sortedSegments() {
+		root.children[child].collectParameterizedPaths([]string{child}, ret)
+	}
+
+	return ret
+}
+
+func (n *pathSegmentNode) clusterChildren() {
+	if cluster := n.findCardinalityCluster(); len(cluster) > 0 {
+		target, ok := n.children[paramSegment]
+		if !ok {
+			target = newPathSegmentNode()
+			n.children[paramSegment] = target
+		}
+		for _, segment := range cluster {
+			target.merge(n.children[segment])
+			delete(n.children, segment)
+		}
+	}
+
+	for _, child := range n.children {
+		child.clusterChildren()
+	}
+}
+
+// findCardinalityCluster returns the largest group of static sibling segments that share the same operations,
+// if this group passes the cardinality and variety thresholds.
+func (n *pathSegmentNode) findCardinalityCluster() []string {
+	signatureToSegments := make(map[string][]string)
+	for segment, child := range n.children {
+		if utils.HasPathParam(segment) {
+			continue
+		}
+		signature := child.operationsSignature()
+		signatureToSegments[signature] = append(signatureToSegments[signature], segment)
+	}
+
+	var cluster []string
+	var clusterSignature string
+	for signature, segments := range signatureToSegments {
+		// prefer larger clusters, break ties by signature for a stable result
+		if len(segments) > len(cluster) || (len(segments) == len(cluster) && signature < clusterSignature) {
+			cluster = segments
+			clusterSignature = signature
+		}
+	}
+
+	if len(cluster) < cardinalityMinDistinctValues {
+		return nil
+	}
+
+	if float64(len(cluster))/float64(len(n.children)) < cardinalityMinVarietyRatio {
+		return nil
+	}
+
+	if !hasIdentifierValues(cluster) {
+		return nil
+	}
+
+	sort.Strings(cluster)
+
+	return cluster
+}
+
+// hasIdentifierValues checks the shape of the sibling values, identifier like values (with digits) are identifiers,
+// while a majority of resource like values (plural words) means the siblings are static collection names.
+func hasIdentifierValues(segments []string) bool {
+	identifiers, resourceNames := 0, 0
+	for _, segment := range segments {
+		switch {
+		case strings.IndexFunc(segment, unicode.IsDigit) >= 0:
+			identifiers++
+		case isResourceName(segment):
+			resourceNames++
+		}
+	}
+
+	if float64(identifiers)/float64(len(segments)) >= cardinalityMaxResourceNameRatio {
+		return true
+	}
+
+	return float64(resourceNames)/float64(len(segments)) < cardinalityMaxResourceNameRatio
+}
+
+// isResourceName checks if the segment is a plural word, e.g. users, order-items.
+func isResourceName(segment string) bool {
+	words := strings.Fields(nonAlphanumericChar.ReplaceAllString(segment, " "))
+	if len(words) == 0 {
+		return false
+	}
+	for _, word := range words {
+		if !lettersCheck.MatchString(word) {
+			return false
+		}
+	}
+	lastWord := words[len(words)-1]
+
+	return singularize(lastWord) != lastWord
+}
+
+// operationsSignature describes the operations found in the node subtree by their relative depth.
+// e.g. /alice (GET) and /alice/repos/x (GET, POST) will have the signature "0:GET,2:GET,2:POST".
+func (n *pathSegmentNode) operationsSignature() string {
+	signatureSet := make(map[string]bool)
+	n.collectOperationsSignature(0, signatureSet)
+
+	signature := make([]string, 0, len(signatureSet))
+	for item := range signatureSet {
+		signature = append(signature, item)
+	}
+	sort.Strings(signature)
+
+	return strings.Join(signature, ",")
+}
+
+func (n *pathSegmentNode) collectOperationsSignature(depth int, signature map[string]bool) {
+	for method := range n.methods {
+		signature[fmt.Sprintf("%d:%s", depth, method)] = true
+	}
+	for _, child := range n.children {
+		child.collectOperationsSignature(depth+1, signature)
+	}
+}
+
+func (n *pathSegmentNode) merge(other *pathSegmentNode) {
+	for path := range other.paths {
+		n.paths[path] = true
+	}
+	for method := range other.methods {
+		n.methods[method] = true
+	}
+	for segment, otherChild := range other.children {
+		child, ok := n.children[segment]
+		if !ok {
+			n.children[segment] = otherChild
+			continue
+		}
+		child.merge(otherChild)
+	}
+}
+
+func (n *pathSegmentNode) collectParameterizedPaths(segments []string, ret map[string]map[string]bool) {
+	if len(n.paths) > 0 {
+		ret[strings.Join(nameParamSegments(segments), "/")] = n.paths
+	}
+
+	for _, child := range n.sortedSegments() {
+		childSegments := append(append([]string{}, segments...), child)
+		n.children[child].collectParameterizedPaths(childSegments, ret)
+	}
+}
+
+func (n *pathSegmentNode) sortedSegments() []string {
+	segments := make([]string, 0, len(n.children))
+	for segment := range n.children {
+		segments = append(segments, segment)
+	}
+	sort.Strings(segments)
+
+	return segments
+}
@@ -0,0 +1,246 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+)
+
+func createPathItemsWithMethods(pathToMethods map[string][]string) map[string]*oapi_spec.PathItem {
+	ret := make(map[string]*oapi_spec.PathItem)
+	for path, methods := range pathToMethods {
+		pathItem := NewTestPathItem()
+		for _, method := range methods {
+			pathItem = pathItem.WithOperation(method, oapi_spec.NewOperation())
+		}
+		ret[path] = &pathItem.PathItem
+	}
+	return ret
+}
+
+func Test_parameterizeByCardinality(t *testing.T) {
+	type args struct {
+		parameterizedPaths map[string]map[string]bool
+		pathItems          map[string]*oapi_spec.PathItem
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]map[string]bool
+	}{
+		{
+			name: "enough distinct values with same operations - clustered",
+			args: args{
+				parameterizedPaths: map[string]map[string]bool{
+					"/users/alice": {"/users/alice": true},
+					"/users/bob":   {"/users/bob": true},
+					"/users/carol": {"/users/carol": true},
+					"/users/dave":  {"/users/dave": true},
+					"/users/eve":   {"/users/eve": true},
+				},
+				pathItems: createPathItemsWithMethods(map[string][]string{
+					"/users/alice": {http.MethodGet},
+					"/users/bob":   {http.MethodGet},
+					"/users/carol": {http.MethodGet},
+					"/users/dave":  {http.MethodGet},
+					"/users/eve":   {http.MethodGet},
+				}),
+			},
+			want: map[string]map[string]bool{
+				"/users/{userId}": {
+					"/users/alice": true,
+					"/users/bob":   true,
+					"/users/carol": true,
+					"/users/dave":  true,
+					"/users/eve":   true,
+				},
+			},
+		},
+		{
+			name: "not enough distinct values - not clustered",
+			args: args{
+				parameterizedPaths: map[string]map[string]bool{
+					"/users/alice": {"/users/alice": true},
+					"/users/bob":   {"/users/bob": true},
+				},
+				pathItems: createPathItemsWithMethods(map[string][]string{
+					"/users/alice": {http.MethodGet},
+					"/users/bob":   {http.MethodGet},
+				}),
+			},
+			want: map[string]map[string]bool{
+				"/users/alice": {"/users/alice": true},
+				"/users/bob":   {"/users/bob": true},
+			},
+		},
+		{
+			name: "different operations - not clustered",
+			args: args{
+				parameterizedPaths: map[string]map[string]bool{
+					"/api/users":    {"/api/users": true},
+					"/api/orders":   {"/api/orders": true},
+					"/api/items":    {"/api/items": true},
+					"/api/carts":    {"/api/carts": true},
+					"/api/payments": {"/api/payments": true},
+				},
+				pathItems: createPathItemsWithMethods(map[string][]string{
+					"/api/users":    {http.MethodGet},
+					"/api/orders":   {http.MethodGet, http.MethodPost},
+					"/api/items":    {http.MethodPut},
+					"/api/carts":    {http.MethodGet, http.MethodDelete},
+					"/api/payments": {http.MethodPost},
+				}),
+			},
+			want: map[string]map[string]bool{
+				"/api/users":    {"/api/users": true},
+				"/api/orders":   {"/api/orders": true},
+				"/api/items":    {"/api/items": true},
+				"/api/carts":    {"/api/carts": true},
+				"/api/payments": {"/api/payments": true},
+			},
+		},
+		{
+			name: "same operations resource names - not clustered",
+			args: args{
+				parameterizedPaths: map[string]map[string]bool{
+					"/api/users":    {"/api/users": true},
+					"/api/orders":   {"/api/orders": true},
+					"/api/items":    {"/api/items": true},
+					"/api/carts":    {"/api/carts": true},
+					"/api/payments": {"/api/payments": true},
+				},
+				pathItems: createPathItemsWithMethods(map[string][]string{
+					"/api/users":    {http.MethodGet},
+					"/api/orders":   {http.MethodGet},
+					"/api/items":    {http.MethodGet},
+					"/api/carts":    {http.MethodGet},
+					"/api/payments": {http.MethodGet},
+				}),
+			},
+			want: map[string]map[string]bool{
+				"/api/users":    {"/api/users": true},
+				"/api/orders":   {"/api/orders": true},
+				"/api/items":    {"/api/items": true},
+				"/api/carts":    {"/api/carts": true},
+				"/api/payments": {"/api/payments": true},
+			},
+		},
+		{
+			name: "identifier values with same operations - clustered",
+			args: args{
+				parameterizedPaths: map[string]map[string]bool{
+					"/files/a1b2": {"/files/a1b2": true},
+					"/files/c3d4": {"/files/c3d4": true},
+					"/files/e5f6": {"/files/e5f6": true},
+					"/files/ab12": {"/files/ab12": true},
+					"/files/logs": {"/files/logs": true},
+				},
+				pathItems: createPathItemsWithMethods(map[string][]string{
+					"/files/a1b2": {http.MethodGet},
+					"/files/c3d4": {http.MethodGet},
+					"/files/e5f6": {http.MethodGet},
+					"/files/ab12": {http.MethodGet},
+					"/files/logs": {http.MethodGet},
+				}),
+			},
+			want: map[string]map[string]bool{
+				"/files/{fileId}": {
+					"/files/a1b2": true,
+					"/files/c3d4": true,
+					"/files/e5f6": true,
+					"/files/ab12": true,
+					"/files/logs": true,
+				},
+			},
+		},
+		{
+			name: "low variety - not clustered",
+			args: args{
+				parameterizedPaths: map[string]map[string]bool{
+					"/api/a":      {"/api/a": true},
+					"/api/b":      {"/api/b": true},
+					"/api/c":      {"/api/c": true},
+					"/api/d":      {"/api/d": true},
+					"/api/e":      {"/api/e": true},
+					"/api/health": {"/api/health": true},
+					"/api/status": {"/api/status": true},
+				},
+				pathItems: createPathItemsWithMethods(map[string][]string{
+					"/api/a":      {http.MethodGet},
+					"/api/b":      {http.MethodGet},
+					"/api/c":      {http.MethodGet},
+					"/api/d":      {http.MethodGet},
+					"/api/e":      {http.MethodGet},
+					"/api/health": {http.MethodPost},
+					"/api/status": {http.MethodPut},
+				}),
+			},
+			want: map[string]map[string]bool{
+				"/api/a":      {"/api/a": true},
+				"/api/b":      {"/api/b": true},
+				"/api/c":      {"/api/c": true},
+				"/api/d":      {"/api/d": true},
+				"/api/e":      {"/api/e": true},
+				"/api/health": {"/api/health": true},
+				"/api/status": {"/api/status": true},
+			},
+		},
+		{
+			name: "nested clusters with existing param",
+			args: args{
+				parameterizedPaths: map[string]map[string]bool{
+					"/users/alice/repos/r1": {"/users/alice/repos/r1": true},
+					"/users/bob/repos/r2":   {"/users/bob/repos/r2": true},
+					"/users/carol/repos/r3": {"/users/carol/repos/r3": true},
+					"/users/dave/repos/r4":  {"/users/dave/repos/r4": true},
+					"/users/eve/repos/r5":   {"/users/eve/repos/r5": true},
+					"/users/{param1}/repos/r6": {
+						"/users/123/repos/r6": true,
+					},
+				},
+				pathItems: createPathItemsWithMethods(map[string][]string{
+					"/users/alice/repos/r1": {http.MethodGet},
+					"/users/bob/repos/r2":   {http.MethodGet},
+					"/users/carol/repos/r3": {http.MethodGet},
+					"/users/dave/repos/r4":  {http.MethodGet},
+					"/users/eve/repos/r5":   {http.MethodGet},
+					"/users/123/repos/r6":   {http.MethodGet},
+				}),
+			},
+			want: map[string]map[string]bool{
+				"/users/{userId}/repos/{repoId}": {
+					"/users/alice/repos/r1": true,
+					"/users/bob/repos/r2":   true,
+					"/users/carol/repos/r3": true,
+					"/users/dave/repos/r4":  true,
+					"/users/eve/repos/r5":   true,
+					"/users/123/repos/r6":   true,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parameterizeByCardinality(tt.args.parameterizedPaths, tt.args.pathItems); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parameterizeByCardinality() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
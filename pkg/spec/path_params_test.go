@@ -46,14 +46,56 @@ func Test_createParameterizedPath(t *testing.T) {
 			args: args{
 				path: "/api/123/hello",
 			},
-			want: "/api/{param1}/hello",
+			want: "/api/{apiId}/hello",
 		},
 		{
 			name: "2 suspect param",
 			args: args{
 				path: "/api/123/hello/234",
 			},
-			want: "/api/{param1}/hello/{param2}",
+			want: "/api/{apiId}/hello/{helloId}",
+		},
+		{
+			name: "no preceding static segment - fallback",
+			args: args{
+				path: "/123/234",
+			},
+			want: "/{param1}/{param2}",
+		},
+		{
+			name: "name collision",
+			args: args{
+				path: "/users/123/users/234",
+			},
+			want: "/users/{userId}/users/{userId2}",
+		},
+		{
+			name: "embedded suspect param with prefix",
+			args: args{
+				path: "/reports/report-123",
+			},
+			want: "/reports/report-{reportId}",
+		},
+		{
+			name: "embedded suspect param with extension",
+			args: args{
+				path: "/files/1234.json",
+			},
+			want: "/files/{fileId}.json",
+		},
+		{
+			name: "embedded uuid",
+			args: args{
+				path: "/api/order-77e1c83b-7bb0-437b-bc50-a7a58e5660ac",
+			},
+			want: "/api/order-{orderId}",
+		},
+		{
+			name: "version segment - no word",
+			args: args{
+				path: "/api/v1.2.3",
+			},
+			want: "/api/v1.2.3",
 		},
 	}
 	for _, tt := range tests {
@@ -65,6 +107,57 @@ func Test_createParameterizedPath(t *testing.T) {
 	}
 }
 
+func Test_createSemanticParamName(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment string
+		want    string
+	}{
+		{
+			name:    "plural",
+			segment: "users",
+			want:    "userId",
+		},
+		{
+			name:    "plural ies",
+			segment: "categories",
+			want:    "categoryId",
+		},
+		{
+			name:    "plural es",
+			segment: "boxes",
+			want:    "boxId",
+		},
+		{
+			name:    "singular",
+			segment: "status",
+			want:    "statusId",
+		},
+		{
+			name:    "multiple words",
+			segment: "order-items",
+			want:    "orderItemId",
+		},
+		{
+			name:    "starts with a digit",
+			segment: "2fa",
+			want:    "",
+		},
+		{
+			name:    "no alphanumeric chars",
+			segment: "-_-",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := createSemanticParamName(tt.segment); got != tt.want {
+				t.Errorf("createSemanticParamName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_isSuspectPathParam(t *testing.T) {
 	type args struct {
 		pathPart string
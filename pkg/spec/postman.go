@@ -0,0 +1,743 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	uuid "github.com/satori/go.uuid"
+)
+
+type ExportSource string
+
+const (
+	ExportSourceApproved ExportSource = "APPROVED"
+	ExportSourceLearning ExportSource = "LEARNING"
+)
+
+const (
+	PostmanCollectionSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+	postmanBaseURLVariable     = "baseUrl"
+	postmanUsernameVariable    = "username"
+	postmanPasswordVariable    = "password"
+	postmanBearerTokenVariable = "bearerToken"
+	postmanAccessTokenVariable = "accessToken"
+	postmanAPIKeyVariable      = "apiKey"
+
+	// maxExampleDepth limits the example generation of recursive schemas.
+	maxExampleDepth = 10
+)
+
+var pathParamTemplate = regexp.MustCompile(`{([^{}]+)}`)
+
+// PostmanCollection is a Postman Collection v2.1.
+// https://schema.getpostman.com/json/collection/v2.1.0/collection.json
+type PostmanCollection struct {
+	Info     *PostmanInfo       `json:"info"`
+	Item     []*PostmanItem     `json:"item"`
+	Variable []*PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanInfo struct {
+	PostmanID   string `json:"_postman_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// PostmanItem is a folder (with items) or a request (with example responses).
+type PostmanItem struct {
+	Name     string             `json:"name"`
+	Item     []*PostmanItem     `json:"item,omitempty"`
+	Request  *PostmanRequest    `json:"request,omitempty"`
+	Response []*PostmanResponse `json:"response,omitempty"`
+}
+
+type PostmanRequest struct {
+	Method      string             `json:"method"`
+	Description string             `json:"description,omitempty"`
+	Header      []*PostmanKeyValue `json:"header"`
+	URL         *PostmanURL        `json:"url"`
+	Body        *PostmanBody       `json:"body,omitempty"`
+	Auth        *PostmanAuth       `json:"auth,omitempty"`
+}
+
+type PostmanURL struct {
+	Raw      string             `json:"raw"`
+	Host     []string           `json:"host"`
+	Path     []string           `json:"path,omitempty"`
+	Query    []*PostmanKeyValue `json:"query,omitempty"`
+	Variable []*PostmanKeyValue `json:"variable,omitempty"`
+}
+
+type PostmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type PostmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []*PostmanKeyValue  `json:"urlencoded,omitempty"`
+	FormData   []*PostmanKeyValue  `json:"formdata,omitempty"`
+	Options    *PostmanBodyOptions `json:"options,omitempty"`
+}
+
+type PostmanBodyOptions struct {
+	Raw *PostmanRawOptions `json:"raw,omitempty"`
+}
+
+type PostmanRawOptions struct {
+	Language string `json:"language"`
+}
+
+// PostmanAuth is the request auth, with the attributes of the auth type (e.g. basic).
+type PostmanAuth struct {
+	Type   string             `json:"type"`
+	Basic  []*PostmanKeyValue `json:"basic,omitempty"`
+	Bearer []*PostmanKeyValue `json:"bearer,omitempty"`
+	APIKey []*PostmanKeyValue `json:"apikey,omitempty"`
+	OAuth2 []*PostmanKeyValue `json:"oauth2,omitempty"`
+}
+
+type PostmanResponse struct {
+	Name                   string             `json:"name"`
+	OriginalRequest        *PostmanRequest    `json:"originalRequest"`
+	Status                 string             `json:"status,omitempty"`
+	Code                   int                `json:"code,omitempty"`
+	PostmanPreviewLanguage string             `json:"_postman_previewlanguage,omitempty"`
+	Header                 []*PostmanKeyValue `json:"header"`
+	Body                   string             `json:"body,omitempty"`
+}
+
+type PostmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// ExportPostmanCollection exports the approved or the learning spec to a Postman Collection v2.1.
+// The requests are grouped to folders by the operation tag, or by the first static path segment, and have their
+// params, headers, auth (by the spec security schemes), example body and example responses. The json example bodies
+// are the body samples that were recorded on LearnTelemetry (see BodySample), and the other example bodies are
+// created from the schemas (using the schema examples if exist).
+// The base URL, and the auth credentials are collection variables.
+func (s *Spec) ExportPostmanCollection(source ExportSource, opts ...GenerateOption) (*PostmanCollection, error) {
+	params := &GenerateParams{}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var pathItems map[string]*oapi_spec.PathItem
+	var securitySchemes oapi_spec.SecuritySchemes
+	bodySamples := s.getBodySamples
+	switch source {
+	case ExportSourceApproved:
+		if s.ApprovedSpec != nil {
+			pathItems, securitySchemes = s.ApprovedSpec.PathItems, s.ApprovedSpec.SecuritySchemes
+		}
+	case ExportSourceLearning:
+		if s.LearningSpec != nil {
+			pathItems = s.LearningSpec.PathItems
+			securitySchemes = getLearningSecuritySchemes(s.LearningSpec)
+		}
+		// the samples are recorded by the parameterized path of the learning path
+		bodySamples = func(method, path string) []*BodySample {
+			return s.getBodySamples(method, createParameterizedPath(path))
+		}
+	default:
+		return nil, fmt.Errorf("spec source: %v is not valid", source)
+	}
+
+	metadata := s.getGeneratedSpecMetadata(params.globalMetadata)
+	baseURL := ""
+	if len(metadata.Servers) > 0 {
+		baseURL = strings.TrimSuffix(metadata.Servers[0].URL, "/")
+	}
+
+	exporter := &postmanExporter{
+		securitySchemes: securitySchemes,
+		variables:       map[string]string{postmanBaseURLVariable: baseURL},
+		bodySamples:     bodySamples,
+	}
+	collection := &PostmanCollection{
+		Info: &PostmanInfo{
+			Name:        metadata.Title,
+			Description: metadata.Description,
+			Schema:      PostmanCollectionSchema,
+		},
+		Item: exporter.createItems(pathItems),
+	}
+	collection.Variable = exporter.createVariables()
+	if s.ID != uuid.Nil {
+		collection.Info.PostmanID = s.ID.String()
+	}
+
+	return collection, nil
+}
+
+// getLearningSecuritySchemes returns the security schemes of the learning spec operations, as they are populated on
+// review approval.
+func getLearningSecuritySchemes(learningSpec *LearningSpec) oapi_spec.SecuritySchemes {
+	securitySchemes := oapi_spec.SecuritySchemes{}
+	for _, pathItem := range learningSpec.PathItems {
+		securitySchemes = updateSecuritySchemesFromPathItem(securitySchemes, pathItem)
+	}
+	for name, securityScheme := range learningSpec.SecuritySchemes {
+		securitySchemes[name] = securityScheme
+	}
+
+	return securitySchemes
+}
+
+type postmanExporter struct {
+	securitySchemes oapi_spec.SecuritySchemes
+	// collection variables by name, with their default value
+	variables map[string]string
+	// bodySamples returns the body samples of the operation
+	bodySamples func(method, path string) []*BodySample
+}
+
+// createItems creates a folder for each tag (or first static path segment) with the requests of its operations,
+// the operations without a tag or a static path segment are in the collection root.
+func (e *postmanExporter) createItems(pathItems map[string]*oapi_spec.PathItem) []*PostmanItem {
+	items := []*PostmanItem{}
+	folders := make(map[string]*PostmanItem)
+
+	for _, path := range sortedPaths(pathItems) {
+		pathItem := pathItems[path]
+		operations := pathItem.Operations()
+		for _, method := range sortedUnionMethods(operations, nil) {
+			operation := operations[method]
+			item := e.createRequestItem(path, method, pathItem, operation)
+
+			folderName := getPostmanFolderName(path, operation)
+			if folderName == "" {
+				items = append(items, item)
+				continue
+			}
+			folder, ok := folders[folderName]
+			if !ok {
+				folder = &PostmanItem{Name: folderName}
+				folders[folderName] = folder
+			}
+			folder.Item = append(folder.Item, item)
+		}
+	}
+
+	folderNames := make([]string, 0, len(folders))
+	for folderName := range folders {
+		folderNames = append(folderNames, folderName)
+	}
+	sort.Strings(folderNames)
+	for _, folderName := range folderNames {
+		items = append(items, folders[folderName])
+	}
+
+	return items
+}
+
+func getPostmanFolderName(path string, operation *oapi_spec.Operation) string {
+	if len(operation.Tags) > 0 {
+		return operation.Tags[0]
+	}
+	if tags := inferOperationTags(path); len(tags) > 0 {
+		return tags[0]
+	}
+
+	return ""
+}
+
+func (e *postmanExporter) createRequestItem(path, method string, pathItem *oapi_spec.PathItem, operation *oapi_spec.Operation) *PostmanItem {
+	name := operation.Summary
+	if name == "" {
+		name = method + " " + path
+	}
+
+	request := &PostmanRequest{
+		Method:      method,
+		Description: operation.Description,
+		Header:      []*PostmanKeyValue{},
+		URL:         createPostmanURL(path),
+		Auth:        e.createAuth(operation.Security),
+	}
+
+	for _, parameter := range append(append(oapi_spec.Parameters{}, pathItem.Parameters...), operation.Parameters...) {
+		if parameter == nil || parameter.Value == nil {
+			continue
+		}
+		param := parameter.Value
+		keyValue := &PostmanKeyValue{
+			Key:         param.Name,
+			Value:       getParameterExample(param),
+			Description: param.Description,
+		}
+		switch param.In {
+		case oapi_spec.ParameterInPath:
+			setPostmanURLVariable(request.URL, keyValue)
+		case oapi_spec.ParameterInQuery:
+			request.URL.Query = append(request.URL.Query, keyValue)
+		case oapi_spec.ParameterInHeader:
+			request.Header = append(request.Header, keyValue)
+		}
+	}
+	request.URL.Raw = createPostmanRawURL(request.URL)
+
+	samples := e.bodySamples(method, path)
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		if mediaType, ok := getPreferredMediaType(operation.RequestBody.Value.Content); ok {
+			request.Header = append(request.Header, &PostmanKeyValue{Key: "Content-Type", Value: mediaType})
+			request.Body = createPostmanBody(mediaType, operation.RequestBody.Value.Content[mediaType])
+			if sampleBody := getRequestBodySample(samples); sampleBody != "" && isJSONMediaType(mediaType) {
+				request.Body = createPostmanJSONBody([]byte(sampleBody))
+			}
+		}
+	}
+
+	return &PostmanItem{
+		Name:     name,
+		Request:  request,
+		Response: createPostmanResponses(name, request, operation.Responses, samples),
+	}
+}
+
+func getRequestBodySample(samples []*BodySample) string {
+	for _, sample := range samples {
+		if sample.RequestBody != "" {
+			return sample.RequestBody
+		}
+	}
+
+	return ""
+}
+
+func getResponseBodySample(samples []*BodySample, statusCode string) string {
+	for _, sample := range samples {
+		if sample.StatusCode == statusCode && sample.ResponseBody != "" {
+			return sample.ResponseBody
+		}
+	}
+
+	return ""
+}
+
+// createPostmanURL creates the url of the path template, with the path params as postman path variables (e.g. :userId).
+func createPostmanURL(path string) *PostmanURL {
+	url := &PostmanURL{
+		Host: []string{"{{" + postmanBaseURLVariable + "}}"},
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		url.Path = append(url.Path, pathParamTemplate.ReplaceAllString(segment, ":$1"))
+		for _, match := range pathParamTemplate.FindAllStringSubmatch(segment, -1) {
+			url.Variable = append(url.Variable, &PostmanKeyValue{Key: match[1]})
+		}
+	}
+
+	return url
+}
+
+// setPostmanURLVariable sets the path variable, path params that are not in the path template are ignored.
+func setPostmanURLVariable(url *PostmanURL, variable *PostmanKeyValue) {
+	for i, existing := range url.Variable {
+		if existing.Key == variable.Key {
+			url.Variable[i] = variable
+			return
+		}
+	}
+}
+
+func createPostmanRawURL(url *PostmanURL) string {
+	raw := strings.Join(url.Host, ".")
+	if len(url.Path) > 0 {
+		raw += "/" + strings.Join(url.Path, "/")
+	}
+
+	query := make([]string, 0, len(url.Query))
+	for _, param := range url.Query {
+		query = append(query, param.Key+"="+param.Value)
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+
+	return raw
+}
+
+// createAuth creates the request auth from the first security scheme of the operation security requirements,
+// the credentials are collection variables.
+func (e *postmanExporter) createAuth(security *oapi_spec.SecurityRequirements) *PostmanAuth {
+	if security == nil {
+		return nil
+	}
+
+	for _, requirement := range *security {
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			schemeRef, ok := e.securitySchemes[name]
+			if !ok || schemeRef == nil || schemeRef.Value == nil {
+				continue
+			}
+			if auth := e.createSecuritySchemeAuth(schemeRef.Value); auth != nil {
+				return auth
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *postmanExporter) createSecuritySchemeAuth(scheme *oapi_spec.SecurityScheme) *PostmanAuth {
+	switch {
+	case scheme.Type == apiKeyType:
+		in := scheme.In
+		if in == "" {
+			in = oapi_spec.ParameterInHeader
+		}
+		return &PostmanAuth{
+			Type: "apikey",
+			APIKey: []*PostmanKeyValue{
+				{Key: "key", Value: scheme.Name, Type: "string"},
+				{Key: "value", Value: e.useVariable(postmanAPIKeyVariable), Type: "string"},
+				{Key: "in", Value: in, Type: "string"},
+			},
+		}
+	case scheme.Type == basicAuthType && strings.EqualFold(scheme.Scheme, basicAuthScheme):
+		return &PostmanAuth{
+			Type: "basic",
+			Basic: []*PostmanKeyValue{
+				{Key: "username", Value: e.useVariable(postmanUsernameVariable), Type: "string"},
+				{Key: "password", Value: e.useVariable(postmanPasswordVariable), Type: "string"},
+			},
+		}
+	case scheme.Type == basicAuthType && strings.EqualFold(scheme.Scheme, "bearer"):
+		return &PostmanAuth{
+			Type: "bearer",
+			Bearer: []*PostmanKeyValue{
+				{Key: "token", Value: e.useVariable(postmanBearerTokenVariable), Type: "string"},
+			},
+		}
+	case scheme.Type == oauth2Type:
+		return &PostmanAuth{
+			Type: "oauth2",
+			OAuth2: []*PostmanKeyValue{
+				{Key: "accessToken", Value: e.useVariable(postmanAccessTokenVariable), Type: "string"},
+				{Key: "addTokenTo", Value: "header", Type: "string"},
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+// useVariable adds the collection variable, and returns its reference.
+func (e *postmanExporter) useVariable(name string) string {
+	if _, ok := e.variables[name]; !ok {
+		e.variables[name] = ""
+	}
+
+	return "{{" + name + "}}"
+}
+
+func (e *postmanExporter) createVariables() []*PostmanVariable {
+	names := make([]string, 0, len(e.variables))
+	for name := range e.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	variables := make([]*PostmanVariable, 0, len(names))
+	for _, name := range names {
+		variables = append(variables, &PostmanVariable{Key: name, Value: e.variables[name], Type: "string"})
+	}
+
+	return variables
+}
+
+func createPostmanResponses(name string, request *PostmanRequest, responses oapi_spec.Responses, samples []*BodySample) []*PostmanResponse {
+	ret := []*PostmanResponse{}
+	for _, statusCode := range sortedStatusCodes(responses) {
+		responseRef := responses[statusCode]
+		// postman example responses have an exact status code (not default or a range)
+		code, err := strconv.Atoi(statusCode)
+		if err != nil || responseRef == nil || responseRef.Value == nil {
+			continue
+		}
+
+		response := &PostmanResponse{
+			Name:            name + " - " + statusCode,
+			OriginalRequest: request,
+			Status:          http.StatusText(code),
+			Code:            code,
+			Header:          []*PostmanKeyValue{},
+		}
+		if mediaType, ok := getPreferredMediaType(responseRef.Value.Content); ok {
+			response.Header = append(response.Header, &PostmanKeyValue{Key: "Content-Type", Value: mediaType})
+			body := createPostmanBody(mediaType, responseRef.Value.Content[mediaType])
+			if sampleBody := getResponseBodySample(samples, statusCode); sampleBody != "" && isJSONMediaType(mediaType) {
+				body = createPostmanJSONBody([]byte(sampleBody))
+			}
+			if body != nil {
+				response.Body = body.Raw
+				if body.Options != nil && body.Options.Raw != nil {
+					response.PostmanPreviewLanguage = body.Options.Raw.Language
+				}
+			}
+		}
+		for _, headerName := range sortedHeaderRefNames(responseRef.Value.Headers) {
+			header := responseRef.Value.Headers[headerName]
+			value := ""
+			if header != nil && header.Value != nil && header.Value.Schema != nil {
+				value = exampleToString(createSchemaExample(header.Value.Schema.Value, 0))
+			}
+			response.Header = append(response.Header, &PostmanKeyValue{Key: headerName, Value: value})
+		}
+
+		ret = append(ret, response)
+	}
+
+	return ret
+}
+
+func sortedHeaderRefNames(headers oapi_spec.Headers) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// getPreferredMediaType returns the json media type if exists, or the first media type.
+func getPreferredMediaType(content oapi_spec.Content) (string, bool) {
+	if len(content) == 0 {
+		return "", false
+	}
+
+	mediaTypes := sortedMediaTypes(content)
+	for _, mediaType := range mediaTypes {
+		if isJSONMediaType(mediaType) {
+			return mediaType, true
+		}
+	}
+
+	return mediaTypes[0], true
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "application/json") || strings.HasSuffix(strings.Split(mediaType, ";")[0], "+json")
+}
+
+func createPostmanBody(mediaType string, media *oapi_spec.MediaType) *PostmanBody {
+	if media == nil {
+		return nil
+	}
+
+	var example interface{}
+	if media.Example != nil {
+		example = media.Example
+	} else if media.Schema != nil {
+		example = createSchemaExample(media.Schema.Value, 0)
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "application/x-www-form-urlencoded"):
+		return &PostmanBody{Mode: "urlencoded", URLEncoded: createFormParams(example)}
+	case strings.HasPrefix(mediaType, "multipart/form-data"):
+		return &PostmanBody{Mode: "formdata", FormData: createFormParams(example)}
+	case isJSONMediaType(mediaType):
+		raw, err := json.Marshal(example)
+		if err != nil {
+			return nil
+		}
+		return createPostmanJSONBody(raw)
+	default:
+		return &PostmanBody{Mode: "raw", Raw: exampleToString(example)}
+	}
+}
+
+// createPostmanJSONBody returns a raw json body, indented for readability.
+func createPostmanJSONBody(raw []byte) *PostmanBody {
+	indented := &bytes.Buffer{}
+	if err := json.Indent(indented, raw, "", "  "); err != nil {
+		return nil
+	}
+
+	return &PostmanBody{
+		Mode:    "raw",
+		Raw:     indented.String(),
+		Options: &PostmanBodyOptions{Raw: &PostmanRawOptions{Language: "json"}},
+	}
+}
+
+func createFormParams(example interface{}) []*PostmanKeyValue {
+	fields, ok := example.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]*PostmanKeyValue, 0, len(names))
+	for _, name := range names {
+		params = append(params, &PostmanKeyValue{Key: name, Value: exampleToString(fields[name]), Type: "text"})
+	}
+
+	return params
+}
+
+func getParameterExample(param *oapi_spec.Parameter) string {
+	if param.Example != nil {
+		return exampleToString(param.Example)
+	}
+	if param.Schema != nil {
+		return exampleToString(createSchemaExample(param.Schema.Value, 0))
+	}
+
+	return ""
+}
+
+func exampleToString(example interface{}) string {
+	switch value := example.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, exampleToString(item))
+		}
+		return strings.Join(values, ",")
+	case map[string]interface{}:
+		valueB, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		return string(valueB)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// createSchemaExample creates an example value of the schema, using the schema example or default if exist.
+func createSchemaExample(schema *oapi_spec.Schema, depth int) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	for _, schemas := range []oapi_spec.SchemaRefs{schema.OneOf, schema.AnyOf} {
+		if len(schemas) > 0 && schemas[0] != nil {
+			return createSchemaExample(schemas[0].Value, depth+1)
+		}
+	}
+	if len(schema.AllOf) > 0 {
+		example := map[string]interface{}{}
+		for _, allOfSchema := range schema.AllOf {
+			if allOfSchema == nil {
+				continue
+			}
+			if fields, ok := createSchemaExample(allOfSchema.Value, depth+1).(map[string]interface{}); ok {
+				for name, value := range fields {
+					example[name] = value
+				}
+			}
+		}
+		return example
+	}
+
+	switch schema.Type {
+	case oapi_spec.TypeString:
+		return createStringExample(schema.Format)
+	case oapi_spec.TypeInteger:
+		return 0
+	case oapi_spec.TypeNumber:
+		return 0.0
+	case oapi_spec.TypeBoolean:
+		return true
+	case oapi_spec.TypeArray:
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{createSchemaExample(schema.Items.Value, depth+1)}
+	case oapi_spec.TypeObject, "":
+		example := map[string]interface{}{}
+		for name, property := range schema.Properties {
+			if property != nil {
+				example[name] = createSchemaExample(property.Value, depth+1)
+			}
+		}
+		return example
+	default:
+		return nil
+	}
+}
+
+func createStringExample(format string) string {
+	switch format {
+	case "date":
+		return "2021-01-01"
+	case "time":
+		return "00:00:00"
+	case "date-time":
+		return "2021-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "ipv6":
+		return "::1"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	default:
+		return "string"
+	}
+}
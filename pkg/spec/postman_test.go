@@ -0,0 +1,293 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+func createPostmanTestSpec(t *testing.T) *Spec {
+	t.Helper()
+
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	for _, telemetry := range []*Telemetry{
+		createTelemetryWithSecurity("1", http.MethodGet, "/users/1?verbose=true", "example.com", "200", "", `{"name": "a"}`),
+		createTelemetryWithSecurity("2", http.MethodGet, "/users/2?verbose=false", "example.com", "200", "", `{"name": "b"}`),
+		createTelemetry("3", http.MethodPost, "/users", "example.com", "201", `{"name": "a", "age": 1}`, ""),
+		createTelemetry("4", http.MethodGet, "/", "example.com", "200", "", ""),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+
+	return s
+}
+
+func findPostmanItem(items []*PostmanItem, name string) *PostmanItem {
+	for _, item := range items {
+		if item.Name == name {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func TestSpec_ExportPostmanCollection(t *testing.T) {
+	s := createPostmanTestSpec(t)
+	approveSuggestedReview(t, s)
+	s.SetMetadata(&SpecMetadata{Title: "Users"})
+
+	collection, err := s.ExportPostmanCollection(ExportSourceApproved)
+	assert.NilError(t, err)
+	assert.Equal(t, collection.Info.Name, "Users")
+	assert.Equal(t, collection.Info.Schema, PostmanCollectionSchema)
+	assert.DeepEqual(t, collection.Variable, []*PostmanVariable{
+		{Key: postmanAccessTokenVariable, Value: "", Type: "string"},
+		{Key: postmanBaseURLVariable, Value: "http://example.com:443", Type: "string"},
+	})
+
+	// the root path operation is not in a folder, and the folders are sorted
+	assert.Equal(t, len(collection.Item), 2)
+	assert.Equal(t, collection.Item[0].Name, "Get root")
+	users := collection.Item[1]
+	assert.Equal(t, users.Name, "users")
+	assert.Equal(t, len(users.Item), 2)
+
+	createUser := findPostmanItem(users.Item, "Create user")
+	assert.Assert(t, createUser != nil)
+	assert.Equal(t, createUser.Request.Method, http.MethodPost)
+	assert.Equal(t, createUser.Request.URL.Raw, "{{baseUrl}}/users")
+	assert.Assert(t, createUser.Request.Auth == nil)
+	assert.DeepEqual(t, createUser.Request.Header, []*PostmanKeyValue{{Key: "Content-Type", Value: mediaTypeApplicationJSON}})
+	assert.Equal(t, createUser.Request.Body.Mode, "raw")
+	var body map[string]interface{}
+	assert.NilError(t, json.Unmarshal([]byte(createUser.Request.Body.Raw), &body))
+	// the example bodies are the learned body samples
+	assert.DeepEqual(t, body, map[string]interface{}{"name": "a", "age": float64(1)})
+	assert.Equal(t, len(createUser.Response), 1)
+	assert.Equal(t, createUser.Response[0].Code, http.StatusCreated)
+
+	getUser := findPostmanItem(users.Item, "Get user")
+	assert.Assert(t, getUser != nil)
+	assert.Equal(t, getUser.Request.URL.Raw, "{{baseUrl}}/users/:userId?verbose=true")
+	assert.DeepEqual(t, getUser.Request.URL.Path, []string{"users", ":userId"})
+	assert.DeepEqual(t, getUser.Request.URL.Variable, []*PostmanKeyValue{{Key: "userId", Value: "0"}})
+	assert.DeepEqual(t, getUser.Request.URL.Query, []*PostmanKeyValue{{Key: "verbose", Value: "true"}})
+	assert.Equal(t, getUser.Request.Auth.Type, "oauth2")
+	assert.Equal(t, getUser.Request.Auth.OAuth2[0].Value, "{{accessToken}}")
+	assert.Equal(t, len(getUser.Response), 1)
+	assert.Equal(t, getUser.Response[0].Code, http.StatusOK)
+	assert.Equal(t, getUser.Response[0].PostmanPreviewLanguage, "json")
+	assert.Equal(t, getUser.Response[0].Body, "{\n  \"name\": \"a\"\n}")
+
+	// the collection is valid json
+	_, err = json.Marshal(collection)
+	assert.NilError(t, err)
+}
+
+func TestSpec_ExportPostmanCollection_Learning(t *testing.T) {
+	s := createPostmanTestSpec(t)
+
+	collection, err := s.ExportPostmanCollection(ExportSourceLearning)
+	assert.NilError(t, err)
+	// the learning paths are not parameterized, and are grouped by the first path segment
+	assert.Equal(t, len(collection.Item), 2)
+	users := collection.Item[1]
+	assert.Equal(t, users.Name, "users")
+	assert.Equal(t, len(users.Item), 3)
+	assert.Equal(t, users.Item[0].Name, "POST /users")
+	assert.Equal(t, users.Item[0].Request.Body.Raw, "{\n  \"name\": \"a\",\n  \"age\": 1\n}")
+	assert.Equal(t, users.Item[1].Response[0].Body, "{\n  \"name\": \"a\"\n}")
+	assert.Equal(t, users.Item[1].Request.URL.Raw, "{{baseUrl}}/users/1?verbose=true")
+	assert.Equal(t, users.Item[1].Request.Auth.Type, "oauth2")
+
+	_, err = s.ExportPostmanCollection("unknown")
+	assert.Assert(t, err != nil)
+}
+
+func TestSpec_ExportPostmanCollection_SchemaExamples(t *testing.T) {
+	s := createPostmanTestSpec(t)
+	approveSuggestedReview(t, s)
+	// without body samples, the example bodies are created from the schemas
+	s.BodySamples = nil
+
+	collection, err := s.ExportPostmanCollection(ExportSourceApproved)
+	assert.NilError(t, err)
+	users := collection.Item[1]
+	createUser := findPostmanItem(users.Item, "Create user")
+	var body map[string]interface{}
+	assert.NilError(t, json.Unmarshal([]byte(createUser.Request.Body.Raw), &body))
+	assert.DeepEqual(t, body, map[string]interface{}{"name": "string", "age": float64(0)})
+	getUser := findPostmanItem(users.Item, "Get user")
+	assert.Equal(t, getUser.Response[0].Body, "{\n  \"name\": \"string\"\n}")
+}
+
+func TestSpec_recordBodySample(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("1", http.MethodGet, "/users/1", "example.com", "200", "", `{"name": "a"}`),
+		// same operation and status code
+		createTelemetry("2", http.MethodGet, "/users/2", "example.com", "200", "", `{"name": "b"}`),
+		// not a json body
+		createTelemetry("3", http.MethodGet, "/users/3", "example.com", "400", "", `bad request`),
+		createTelemetry("4", http.MethodGet, "/users/4", "example.com", "404", "", `{"error": "not found"}`),
+		createTelemetry("5", http.MethodGet, "/users/5", "example.com", "500", "", `{"error": "internal"}`),
+		// the operation samples limit was reached
+		createTelemetry("6", http.MethodGet, "/users/6", "example.com", "503", "", `{"error": "unavailable"}`),
+		// too large
+		createTelemetry("7", http.MethodPost, "/users", "example.com", "201", `"`+strings.Repeat("a", maxBodySampleSize)+`"`, ""),
+	} {
+		s.recordBodySample(telemetry)
+	}
+
+	assert.DeepEqual(t, s.getBodySamples(http.MethodGet, "/users/{id}"), []*BodySample{
+		{StatusCode: "200", ResponseBody: `{"name": "a"}`},
+		{StatusCode: "404", ResponseBody: `{"error": "not found"}`},
+		{StatusCode: "500", ResponseBody: `{"error": "internal"}`},
+	})
+	assert.Equal(t, len(s.getBodySamples(http.MethodPost, "/users")), 0)
+}
+
+func Test_postmanExporter_createAuth(t *testing.T) {
+	securitySchemes := oapi_spec.SecuritySchemes{
+		BasicAuthSecuritySchemeKey:  &oapi_spec.SecuritySchemeRef{Value: NewBasicAuthSecurityScheme()},
+		BearerAuthSecuritySchemeKey: &oapi_spec.SecuritySchemeRef{Value: oapi_spec.NewJWTSecurityScheme()},
+		APIKeyAuthSecuritySchemeKey: &oapi_spec.SecuritySchemeRef{Value: NewAPIKeySecuritySchemeInQuery("api_key")},
+	}
+	tests := []struct {
+		name          string
+		security      *oapi_spec.SecurityRequirements
+		want          *PostmanAuth
+		wantVariables []string
+	}{
+		{
+			name: "no security",
+		},
+		{
+			name:     "basic",
+			security: &oapi_spec.SecurityRequirements{{BasicAuthSecuritySchemeKey: {}}},
+			want: &PostmanAuth{
+				Type: "basic",
+				Basic: []*PostmanKeyValue{
+					{Key: "username", Value: "{{username}}", Type: "string"},
+					{Key: "password", Value: "{{password}}", Type: "string"},
+				},
+			},
+			wantVariables: []string{postmanPasswordVariable, postmanUsernameVariable},
+		},
+		{
+			name:     "bearer",
+			security: &oapi_spec.SecurityRequirements{{BearerAuthSecuritySchemeKey: {}}},
+			want: &PostmanAuth{
+				Type:   "bearer",
+				Bearer: []*PostmanKeyValue{{Key: "token", Value: "{{bearerToken}}", Type: "string"}},
+			},
+			wantVariables: []string{postmanBearerTokenVariable},
+		},
+		{
+			name:     "api key",
+			security: &oapi_spec.SecurityRequirements{{APIKeyAuthSecuritySchemeKey: {}}},
+			want: &PostmanAuth{
+				Type: "apikey",
+				APIKey: []*PostmanKeyValue{
+					{Key: "key", Value: "api_key", Type: "string"},
+					{Key: "value", Value: "{{apiKey}}", Type: "string"},
+					{Key: "in", Value: "query", Type: "string"},
+				},
+			},
+			wantVariables: []string{postmanAPIKeyVariable},
+		},
+		{
+			name:     "first known security scheme",
+			security: &oapi_spec.SecurityRequirements{{"unknown": {}}, {BearerAuthSecuritySchemeKey: {}}},
+			want: &PostmanAuth{
+				Type:   "bearer",
+				Bearer: []*PostmanKeyValue{{Key: "token", Value: "{{bearerToken}}", Type: "string"}},
+			},
+			wantVariables: []string{postmanBearerTokenVariable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &postmanExporter{securitySchemes: securitySchemes, variables: map[string]string{}}
+			assert.DeepEqual(t, e.createAuth(tt.security), tt.want)
+			var variables []string
+			for _, variable := range e.createVariables() {
+				variables = append(variables, variable.Key)
+			}
+			assert.DeepEqual(t, variables, tt.wantVariables)
+		})
+	}
+}
+
+func Test_createSchemaExample(t *testing.T) {
+	withExample := oapi_spec.NewStringSchema().WithFormat("uuid")
+	withExample.Example = "abc"
+	recursive := oapi_spec.NewObjectSchema()
+	recursive.Properties = oapi_spec.Schemas{"child": {Value: recursive}}
+
+	tests := []struct {
+		name   string
+		schema *oapi_spec.Schema
+		want   interface{}
+	}{
+		{
+			name:   "schema example",
+			schema: withExample,
+			want:   "abc",
+		},
+		{
+			name:   "enum",
+			schema: oapi_spec.NewStringSchema().WithEnum("a", "b"),
+			want:   "a",
+		},
+		{
+			name:   "string format",
+			schema: oapi_spec.NewStringSchema().WithFormat("date-time"),
+			want:   "2021-01-01T00:00:00Z",
+		},
+		{
+			name:   "array",
+			schema: oapi_spec.NewArraySchema().WithItems(oapi_spec.NewBoolSchema()),
+			want:   []interface{}{true},
+		},
+		{
+			name:   "object",
+			schema: oapi_spec.NewObjectSchema().WithProperty("id", oapi_spec.NewInt64Schema()),
+			want:   map[string]interface{}{"id": 0},
+		},
+		{
+			name:   "one of",
+			schema: oapi_spec.NewOneOfSchema(oapi_spec.NewFloat64Schema(), oapi_spec.NewStringSchema()),
+			want:   0.0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, createSchemaExample(tt.schema, 0), tt.want)
+		})
+	}
+
+	// recursive schemas are limited by depth
+	_, err := json.Marshal(createSchemaExample(recursive, 0))
+	assert.NilError(t, err)
+}
@@ -16,8 +16,10 @@
 package spec
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -25,6 +27,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/openclarity/speculator/pkg/pathtrie"
+	"github.com/openclarity/speculator/pkg/utils"
 )
 
 // Sentinel errors for spec version issues.
@@ -34,33 +37,96 @@ var (
 )
 
 type ProvidedSpec struct {
+	// Doc is the provided doc with its refs cleared (see clearRefFromDoc)
 	Doc                 *openapi3.T
 	OriginalSpecVersion OASVersion
+	// OriginalDoc is the provided doc (json) with its refs, used to propose a completed spec (see CompleteProvidedSpec).
+	OriginalDoc []byte
+	// Servers are the doc servers with resolved server variables, resolved once when the spec is set.
+	Servers []*ProvidedSpecServer
 }
 
-func (s *Spec) LoadProvidedSpec(providedSpec []byte, pathToPathID map[string]string) error {
+// LoadProvidedSpec loads a provided spec file, or a zip, tar or tar.gz archive of a multi-file spec (see
+// LoadProvidedSpecBundle).
+// The paths without a path ID in pathToPathID get a generated path ID (see GeneratePathID), unless
+// WithCallerPathIDsOnly is set.
+func (s *Spec) LoadProvidedSpec(providedSpec []byte, pathToPathID map[string]string, opts ...PathIDOption) error {
+	if isSpecArchive(providedSpec) {
+		bundle, err := LoadSpecBundleFromArchive(providedSpec, "")
+		if err != nil {
+			return fmt.Errorf("failed to load spec bundle: %w", err)
+		}
+		return s.LoadProvidedSpecBundle(bundle, pathToPathID, opts...)
+	}
+
 	doc, oasVersion, err := LoadAndValidateRawJSONSpec(providedSpec)
 	if err != nil {
 		return fmt.Errorf("failed to load and validate spec: %w", err)
 	}
 
+	s.setProvidedSpec(doc, oasVersion, pathToPathID, createPathIDParams(opts))
+
+	return nil
+}
+
+// LoadProvidedSpecBundle loads a provided spec split across multiple files, the external refs between the bundle
+// files are resolved.
+func (s *Spec) LoadProvidedSpecBundle(bundle *SpecBundle, pathToPathID map[string]string, opts ...PathIDOption) error {
+	doc, oasVersion, err := LoadAndValidateSpecBundle(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to load and validate spec bundle: %w", err)
+	}
+
+	s.setProvidedSpec(doc, oasVersion, pathToPathID, createPathIDParams(opts))
+
+	return nil
+}
+
+func (s *Spec) setProvidedSpec(doc *openapi3.T, oasVersion OASVersion, pathToPathID map[string]string, params *PathIDParams) {
 	if s.ProvidedSpec == nil {
 		s.ProvidedSpec = &ProvidedSpec{}
 	}
+	originalDoc, err := json.Marshal(doc)
+	if err != nil {
+		log.Errorf("Failed to marshal the provided spec, the completed spec will not keep its refs. %v", err)
+	}
+	s.ProvidedSpec.OriginalDoc = originalDoc
 	// will save doc without refs for proper diff logic
 	s.ProvidedSpec.Doc = clearRefFromDoc(doc)
 	s.ProvidedSpec.OriginalSpecVersion = oasVersion
+	s.ProvidedSpec.Servers = resolveServers(s.ProvidedSpec.Doc)
+	// the coverage of the previous provided spec doesn't apply to the new one
+	s.ProvidedSpecCoverage = nil
 	log.Debugf("Setting provided spec version %q", s.ProvidedSpec.GetSpecVersion())
 
 	// path trie need to be repopulated from start on each new spec
 	s.ProvidedPathTrie = pathtrie.New()
 	for path := range s.ProvidedSpec.Doc.Paths {
-		if pathID, ok := pathToPathID[path]; ok {
-			s.ProvidedPathTrie.Insert(path, pathID)
+		pathID, ok := pathToPathID[path]
+		if !ok {
+			if params.callerPathIDsOnly {
+				continue
+			}
+			pathID = s.GeneratePathID(path)
 		}
+		s.ProvidedPathTrie.Insert(path, pathID)
 	}
 
-	return nil
+	for _, ambiguousPaths := range s.ProvidedSpec.GetAmbiguousPaths() {
+		log.Warnf("Ambiguous paths in provided spec, %v will be matched over %v", ambiguousPaths.PreferredPath, ambiguousPaths.OtherPath)
+	}
+}
+
+// GetProvidedSpecAmbiguousPaths returns the provided spec path templates pairs that can match the same path.
+func (s *Spec) GetProvidedSpecAmbiguousPaths() []pathtrie.AmbiguousPaths {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.HasProvidedSpec() {
+		return nil
+	}
+
+	return s.ProvidedSpec.GetAmbiguousPaths()
 }
 
 func LoadAndValidateRawJSONSpec(spec []byte) (*openapi3.T, OASVersion, error) {
@@ -90,6 +156,12 @@ func LoadAndValidateRawJSONSpec(spec []byte) (*openapi3.T, OASVersion, error) {
 			log.Errorf("provided spec is not valid OpenAPI 3.0: %s. %v", jsonSpec, err)
 			return nil, Unknown, fmt.Errorf("provided spec is not valid OpenAPI 3.0: %w", err)
 		}
+	case OASv31:
+		log.Debugf("OASv3.1 spec provided")
+		if doc, err = LoadAndValidateRawJSONSpecV3FromV31(jsonSpec); err != nil {
+			log.Errorf("provided spec is not valid OpenAPI 3.1: %s. %v", jsonSpec, err)
+			return nil, Unknown, fmt.Errorf("provided spec is not valid OpenAPI 3.1: %w", err)
+		}
 	case Unknown:
 		return nil, Unknown, fmt.Errorf("%w (%v)", ErrUnknownSpecVersion, oasVersion)
 	default:
@@ -107,33 +179,217 @@ func (p *ProvidedSpec) GetSpecVersion() OASVersion {
 	return p.OriginalSpecVersion
 }
 
-func (p *ProvidedSpec) GetBasePath() string {
-	for _, server := range p.Doc.Servers {
-		if server.URL == "" || server.URL == "/" {
+// GetAmbiguousPaths returns the path templates pairs that can match the same path and have the same amount of
+// path params, so the matched path is decided only by the path trie tie-breaking rules (e.g. /a/{x}/c and /a/b/{y}).
+func (p *ProvidedSpec) GetAmbiguousPaths() []pathtrie.AmbiguousPaths {
+	pathTrie := pathtrie.New()
+	for path := range p.Doc.Paths {
+		pathTrie.Insert(path, path)
+	}
+
+	return pathTrie.GetAmbiguousPaths()
+}
+
+// maxServerURLsPerServer limits the expansion of server variables enums, beyond it the remaining variables
+// are resolved only with their default values.
+const maxServerURLsPerServer = 100
+
+// ProvidedSpecServer is a provided spec server with resolved server variables.
+type ProvidedSpecServer struct {
+	// URL is the server URL with the variables replaced by their values.
+	URL string
+	// BasePath is the path of the server URL without a trailing slash, empty for a root path.
+	BasePath string
+}
+
+// GetServers returns all the provided spec servers with resolved server variables (see resolveServers).
+// The servers that were resolved when the spec was set are reused.
+func (p *ProvidedSpec) GetServers() []*ProvidedSpecServer {
+	if p.Servers != nil {
+		return p.Servers
+	}
+
+	return resolveServers(p.Doc)
+}
+
+// resolveServers returns the doc servers with resolved server variables. A variable with an enum is
+// expanded to a server for each of the enum values, starting with the default value, so the first server of each
+// spec server is resolved with the variables default values.
+func resolveServers(doc *openapi3.T) []*ProvidedSpecServer {
+	var ret []*ProvidedSpecServer
+	seen := make(map[string]bool)
+	for _, server := range doc.Servers {
+		if server == nil {
 			continue
 		}
+		for _, url := range resolveServerURLs(server) {
+			if seen[url] {
+				continue
+			}
+			seen[url] = true
+			ret = append(ret, &ProvidedSpecServer{
+				URL:      url,
+				BasePath: getServerURLBasePath(url),
+			})
+		}
+	}
+
+	return ret
+}
 
-		// strip scheme if exits
-		urlNoScheme := server.URL
-		schemeSplittedURL := strings.Split(server.URL, "://")
-		if len(schemeSplittedURL) > 1 {
-			urlNoScheme = schemeSplittedURL[1]
+// GetBasePath returns the base path of the first server that has a non root base path.
+func (p *ProvidedSpec) GetBasePath() string {
+	for _, server := range p.GetServers() {
+		if hasBasePath(server.BasePath) {
+			return server.BasePath
 		}
+	}
 
-		// get path
-		var path string
-		splittedURLNoScheme := strings.SplitN(urlNoScheme, "/", 2) // nolint:gomnd
-		if len(splittedURLNoScheme) > 1 {
-			path = splittedURLNoScheme[1]
+	return ""
+}
+
+// GetMatchingServer returns the server with the longest base path that the given path starts with.
+// A server with a root base path matches any path.
+func (p *ProvidedSpec) GetMatchingServer(path string) (*ProvidedSpecServer, bool) {
+	var ret *ProvidedSpecServer
+	for _, server := range p.GetServers() {
+		if !isPathUnderBasePath(server.BasePath, path) {
+			continue
 		}
-		if path == "" {
+		if ret == nil || len(server.BasePath) > len(ret.BasePath) {
+			ret = server
+		}
+	}
+
+	return ret, ret != nil
+}
+
+// splitBasePath returns the base path of the server that matches the path, and the path without it.
+// If no server matches, the path is returned as is with the first server base path.
+func (p *ProvidedSpec) splitBasePath(path string) (basePath string, pathNoBase string) {
+	if server, found := p.GetMatchingServer(path); found {
+		return server.BasePath, trimBasePathIfNeeded(server.BasePath, path)
+	}
+
+	return p.GetBasePath(), path
+}
+
+func isPathUnderBasePath(basePath, path string) bool {
+	if !hasBasePath(basePath) {
+		return true
+	}
+
+	return path == basePath || strings.HasPrefix(path, basePath+"/")
+}
+
+// resolveServerURLs returns the server URL with each combination of the server variables values.
+// Variables that are not defined by the server are left as is.
+func resolveServerURLs(server *openapi3.Server) []string {
+	urls := []string{server.URL}
+	names, err := server.ParameterNames()
+	if err != nil {
+		log.Warnf("Failed to get server %q variables: %v", server.URL, err)
+		return urls
+	}
+
+	resolved := make(map[string]bool)
+	for _, name := range names {
+		variable, ok := server.Variables[name]
+		if !ok || variable == nil || resolved[name] {
+			continue
+		}
+		resolved[name] = true
+
+		values := getServerVariableValues(variable)
+		if len(values) == 0 {
 			continue
 		}
+		if len(urls)*len(values) > maxServerURLsPerServer {
+			values = values[:1]
+		}
 
-		return "/" + path
+		expandedURLs := make([]string, 0, len(urls)*len(values))
+		for _, url := range urls {
+			for _, value := range values {
+				expandedURLs = append(expandedURLs, strings.ReplaceAll(url, "{"+name+"}", value))
+			}
+		}
+		urls = expandedURLs
 	}
 
-	return ""
+	return urls
+}
+
+// getServerVariableValues returns the default value followed by the rest of the enum values.
+func getServerVariableValues(variable *openapi3.ServerVariable) []string {
+	var values []string
+	if variable.Default != "" {
+		values = append(values, variable.Default)
+	}
+	for _, value := range variable.Enum {
+		if value != variable.Default {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func getServerURLBasePath(url string) string {
+	// strip scheme if exits
+	urlNoScheme := url
+	schemeSplittedURL := strings.Split(url, "://")
+	if len(schemeSplittedURL) > 1 {
+		urlNoScheme = schemeSplittedURL[1]
+	}
+
+	// get path
+	var path string
+	splittedURLNoScheme := strings.SplitN(urlNoScheme, "/", 2) // nolint:gomnd
+	if len(splittedURLNoScheme) > 1 {
+		path = strings.TrimSuffix(splittedURLNoScheme[1], "/")
+	}
+	if path == "" {
+		return ""
+	}
+
+	return "/" + path
+}
+
+// GetMatchingPathTemplate returns the provided spec path template (including the base path) that matches
+// the given parameterized path, ignoring param names. e.g. /api/users/{userId} will match /users/{id}
+// for a base path of /api, and /api/users/{id} will be returned.
+func (p *ProvidedSpec) GetMatchingPathTemplate(parameterizedPath string) (string, bool) {
+	basePath, pathNoBase := p.splitBasePath(parameterizedPath)
+	pathKey := utils.ClearPathParamNames(pathNoBase)
+
+	templates := make([]string, 0, len(p.Doc.Paths))
+	for template := range p.Doc.Paths {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	for _, template := range templates {
+		if utils.ClearPathParamNames(template) == pathKey {
+			return addBasePathIfNeeded(basePath, template), true
+		}
+	}
+
+	return "", false
+}
+
+// getOriginalDoc returns a copy of the provided doc with its refs, or of the ref-cleared doc if it was not kept.
+func (p *ProvidedSpec) getOriginalDoc() (*openapi3.T, error) {
+	if p.OriginalDoc == nil {
+		return cloneDoc(p.Doc)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(p.OriginalDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load doc: %w", err)
+	}
+
+	return doc, nil
 }
 
 func clearRefFromDoc(doc *openapi3.T) *openapi3.T {
@@ -144,6 +400,10 @@ func clearRefFromDoc(doc *openapi3.T) *openapi3.T {
 	for path, item := range doc.Paths {
 		doc.Paths[path] = clearRefFromPathItem(item)
 	}
+	// circular component schemas must be cleared as well, even if they are not used by the paths
+	for name, schemaRef := range doc.Components.Schemas {
+		doc.Components.Schemas[name] = clearRefFromSchemaRef(schemaRef)
+	}
 
 	return doc
 }
@@ -319,51 +579,67 @@ func clearRefFromParameter(parameter *openapi3.Parameter) *openapi3.Parameter {
 }
 
 func clearRefFromSchemaRef(schemaRef *openapi3.SchemaRef) *openapi3.SchemaRef {
+	return clearRefFromSchemaRefInPath(schemaRef, make(map[*openapi3.Schema]bool))
+}
+
+// clearRefFromSchemaRefInPath clears the refs of the schema, a circular ref (to a schema in the path from the root
+// schema) is replaced with an empty schema, so the schema can be marshaled and diffed.
+func clearRefFromSchemaRefInPath(schemaRef *openapi3.SchemaRef, path map[*openapi3.Schema]bool) *openapi3.SchemaRef {
 	if schemaRef == nil {
 		return schemaRef
 	}
 
+	if schemaRef.Value != nil && path[schemaRef.Value] {
+		log.Debugf("Replacing circular ref %q with an empty schema", schemaRef.Ref)
+		return &openapi3.SchemaRef{
+			Value: &openapi3.Schema{},
+		}
+	}
+
 	return &openapi3.SchemaRef{
-		Value: clearRefFromSchema(schemaRef.Value),
+		Value: clearRefFromSchema(schemaRef.Value, path),
 	}
 }
 
-func clearRefFromSchema(schema *openapi3.Schema) *openapi3.Schema {
+func clearRefFromSchema(schema *openapi3.Schema, path map[*openapi3.Schema]bool) *openapi3.Schema {
 	if schema == nil {
 		return schema
 	}
 
-	schema.OneOf = clearRefFromSchemaRefs(schema.OneOf)
-	schema.AnyOf = clearRefFromSchemaRefs(schema.AnyOf)
-	schema.AllOf = clearRefFromSchemaRefs(schema.AllOf)
-	schema.Not = clearRefFromSchemaRef(schema.Not)
-	schema.Items = clearRefFromSchemaRef(schema.Items)
-	schema.Properties = clearRefFromSchemas(schema.Properties)
-	schema.AdditionalProperties = clearRefFromSchemaRef(schema.AdditionalProperties)
+	path[schema] = true
+	defer delete(path, schema)
+
+	schema.OneOf = clearRefFromSchemaRefs(schema.OneOf, path)
+	schema.AnyOf = clearRefFromSchemaRefs(schema.AnyOf, path)
+	schema.AllOf = clearRefFromSchemaRefs(schema.AllOf, path)
+	schema.Not = clearRefFromSchemaRefInPath(schema.Not, path)
+	schema.Items = clearRefFromSchemaRefInPath(schema.Items, path)
+	schema.Properties = clearRefFromSchemas(schema.Properties, path)
+	schema.AdditionalProperties = clearRefFromSchemaRefInPath(schema.AdditionalProperties, path)
 
 	return schema
 }
 
-func clearRefFromSchemas(schemas openapi3.Schemas) openapi3.Schemas {
+func clearRefFromSchemas(schemas openapi3.Schemas, path map[*openapi3.Schema]bool) openapi3.Schemas {
 	if len(schemas) == 0 {
 		return schemas
 	}
 
 	retSchemas := make(openapi3.Schemas, len(schemas))
 	for key, schemaRef := range schemas {
-		retSchemas[key] = clearRefFromSchemaRef(schemaRef)
+		retSchemas[key] = clearRefFromSchemaRefInPath(schemaRef, path)
 	}
 	return retSchemas
 }
 
-func clearRefFromSchemaRefs(schemaRefs openapi3.SchemaRefs) openapi3.SchemaRefs {
+func clearRefFromSchemaRefs(schemaRefs openapi3.SchemaRefs, path map[*openapi3.Schema]bool) openapi3.SchemaRefs {
 	if len(schemaRefs) == 0 {
 		return schemaRefs
 	}
 
 	retSchemaRefs := make(openapi3.SchemaRefs, len(schemaRefs))
 	for i, schemaRef := range schemaRefs {
-		retSchemaRefs[i] = clearRefFromSchemaRef(schemaRef)
+		retSchemaRefs[i] = clearRefFromSchemaRefInPath(schemaRef, path)
 	}
 	return retSchemaRefs
 }
@@ -41,6 +41,7 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 	wantProvidedSpec := &ProvidedSpec{
 		Doc:                 v3,
 		OriginalSpecVersion: OASv3,
+		Servers:             []*ProvidedSpecServer{{URL: "https://example.io/v1", BasePath: "/v1"}},
 	}
 
 	v2, err := LoadAndValidateRawJSONSpecV3FromV2([]byte(jsonSpecV2))
@@ -55,6 +56,7 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 			Paths: openapi3.Paths{},
 		},
 		OriginalSpecVersion: OASv3,
+		Servers:             []*ProvidedSpecServer{{URL: "https://example.io/v1", BasePath: "/v1"}},
 	}
 	err = json.Unmarshal([]byte(jsonSpecWithRefAfterRemoveRef), wantProvidedSpecWithRefAfterRemoveRef.Doc)
 	assert.NilError(t, err)
@@ -70,12 +72,23 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 	wantProvidedPathv2Trie := createPathTrie(pathToPathIDv2)
 	emptyPathTrie := createPathTrie(nil)
 
+	generatedPathIDs := map[string]string{
+		"/artists":            (&Spec{}).GeneratePathID("/artists"),
+		"/artists/{username}": (&Spec{}).GeneratePathID("/artists/{username}"),
+	}
+	wantGeneratedPathTrie := createPathTrie(generatedPathIDs)
+	wantSuppliedAndGeneratedPathTrie := createPathTrie(map[string]string{
+		"/artists":            "1",
+		"/artists/{username}": generatedPathIDs["/artists/{username}"],
+	})
+
 	type fields struct {
 		ProvidedSpec *ProvidedSpec
 	}
 	type args struct {
 		providedSpec []byte
 		pathToPathID map[string]string
+		opts         []PathIDOption
 	}
 	tests := []struct {
 		name                 string
@@ -93,6 +106,7 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 			args: args{
 				providedSpec: []byte(jsonSpec),
 				pathToPathID: pathToPathID,
+				opts:         []PathIDOption{WithCallerPathIDsOnly()},
 			},
 			wantErr:              false,
 			wantProvidedPathTrie: wantProvidedPathTrie,
@@ -119,6 +133,7 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 			args: args{
 				providedSpec: []byte(jsonSpecWithRef),
 				pathToPathID: pathToPathID,
+				opts:         []PathIDOption{WithCallerPathIDsOnly()},
 			},
 			wantErr:              false,
 			wantProvidedPathTrie: wantProvidedPathTrie,
@@ -132,11 +147,37 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 			args: args{
 				providedSpec: []byte(jsonSpec),
 				pathToPathID: map[string]string{},
+				opts:         []PathIDOption{WithCallerPathIDsOnly()},
 			},
 			wantErr:              false,
 			wantProvidedPathTrie: emptyPathTrie,
 			wantProvidedSpec:     wantProvidedSpec,
 		},
+		{
+			name: "json spec with generated path IDs",
+			fields: fields{
+				ProvidedSpec: nil,
+			},
+			args: args{
+				providedSpec: []byte(jsonSpec),
+			},
+			wantErr:              false,
+			wantProvidedPathTrie: wantGeneratedPathTrie,
+			wantProvidedSpec:     wantProvidedSpec,
+		},
+		{
+			name: "json spec with supplied and generated path IDs",
+			fields: fields{
+				ProvidedSpec: nil,
+			},
+			args: args{
+				providedSpec: []byte(jsonSpec),
+				pathToPathID: pathToPathID,
+			},
+			wantErr:              false,
+			wantProvidedPathTrie: wantSuppliedAndGeneratedPathTrie,
+			wantProvidedSpec:     wantProvidedSpec,
+		},
 		{
 			name: "yaml spec",
 			fields: fields{
@@ -145,6 +186,7 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 			args: args{
 				providedSpec: []byte(yamlSpec),
 				pathToPathID: pathToPathID,
+				opts:         []PathIDOption{WithCallerPathIDsOnly()},
 			},
 			wantErr:              false,
 			wantProvidedPathTrie: wantProvidedPathTrie,
@@ -188,11 +230,17 @@ func TestSpec_LoadProvidedSpec(t *testing.T) {
 					ProvidedSpec: tt.fields.ProvidedSpec,
 				},
 			}
-			if err := s.LoadProvidedSpec(tt.args.providedSpec, tt.args.pathToPathID); (err != nil) != tt.wantErr {
+			if err := s.LoadProvidedSpec(tt.args.providedSpec, tt.args.pathToPathID, tt.args.opts...); (err != nil) != tt.wantErr {
 				t.Errorf("LoadProvidedSpec() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if !tt.wantErr {
-				assert.DeepEqual(t, s.ProvidedSpec, tt.wantProvidedSpec, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}))
+				assert.DeepEqual(t, s.ProvidedSpec, tt.wantProvidedSpec, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}),
+					cmpopts.IgnoreFields(ProvidedSpec{}, "OriginalDoc"))
+				// the original doc is the doc before its refs were cleared
+				originalDoc, err := s.ProvidedSpec.getOriginalDoc()
+				assert.NilError(t, err)
+				assert.DeepEqual(t, clearRefFromDoc(originalDoc), s.ProvidedSpec.Doc, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}),
+					cmpopts.EquateEmpty())
 				if !reflect.DeepEqual(s.ProvidedPathTrie, tt.wantProvidedPathTrie) {
 					t.Errorf("LoadProvidedSpec() got = %v, want %v", marshal(s.ProvidedPathTrie), marshal(tt.wantProvidedPathTrie))
 				}
@@ -301,6 +349,52 @@ func TestProvidedSpec_GetBasePath(t *testing.T) {
 			},
 			want: "/v1",
 		},
+		{
+			name: "url templating with variables",
+			fields: fields{
+				Doc: &openapi3.T{
+					Servers: []*openapi3.Server{
+						{
+							URL: "https://{region}.api.example.com/{version}",
+							Variables: map[string]*openapi3.ServerVariable{
+								"region":  {Default: "us", Enum: []string{"eu", "us"}},
+								"version": {Default: "v2", Enum: []string{"v1", "v2"}},
+							},
+						},
+					},
+				},
+			},
+			want: "/v2",
+		},
+		{
+			name: "trailing slash",
+			fields: fields{
+				Doc: &openapi3.T{
+					Servers: []*openapi3.Server{
+						{
+							URL: "https://api.example.com/v1/",
+						},
+					},
+				},
+			},
+			want: "/v1",
+		},
+		{
+			name: "first server with a base path",
+			fields: fields{
+				Doc: &openapi3.T{
+					Servers: []*openapi3.Server{
+						{
+							URL: "https://api.example.com",
+						},
+						{
+							URL: "https://api.example.com/v1",
+						},
+					},
+				},
+			},
+			want: "/v1",
+		},
 		{
 			name: "bad url",
 			fields: fields{
@@ -327,6 +421,212 @@ func TestProvidedSpec_GetBasePath(t *testing.T) {
 	}
 }
 
+func TestProvidedSpec_GetServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers openapi3.Servers
+		want    []*ProvidedSpecServer
+	}{
+		{
+			name: "no servers",
+			want: nil,
+		},
+		{
+			name: "multiple servers",
+			servers: openapi3.Servers{
+				{URL: "https://api.example.com/v1"},
+				{URL: "/"},
+				{URL: "https://api.example.com/v1"},
+			},
+			want: []*ProvidedSpecServer{
+				{URL: "https://api.example.com/v1", BasePath: "/v1"},
+				{URL: "/", BasePath: ""},
+			},
+		},
+		{
+			name: "variables with default and enum",
+			servers: openapi3.Servers{
+				{
+					URL: "{scheme}://{region}.api.example.com/{version}",
+					Variables: map[string]*openapi3.ServerVariable{
+						"region":  {Default: "us"},
+						"version": {Default: "v2", Enum: []string{"v1", "v2", "v3"}},
+					},
+				},
+			},
+			want: []*ProvidedSpecServer{
+				{URL: "{scheme}://us.api.example.com/v2", BasePath: "/v2"},
+				{URL: "{scheme}://us.api.example.com/v1", BasePath: "/v1"},
+				{URL: "{scheme}://us.api.example.com/v3", BasePath: "/v3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ProvidedSpec{
+				Doc: &openapi3.T{Servers: tt.servers},
+			}
+			assert.DeepEqual(t, p.GetServers(), tt.want)
+		})
+	}
+}
+
+func TestSpec_LoadProvidedSpec_ResolvedServers(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, nil))
+	want := resolveServers(s.ProvidedSpec.Doc)
+	assert.Assert(t, len(want) > 0)
+	assert.DeepEqual(t, s.ProvidedSpec.Servers, want)
+
+	// the servers that were resolved when the spec was set are reused
+	s.ProvidedSpec.Doc.Servers = openapi3.Servers{{URL: "https://other.example.com/other"}}
+	assert.DeepEqual(t, s.ProvidedSpec.GetServers(), want)
+}
+
+func TestProvidedSpec_GetMatchingServer(t *testing.T) {
+	p := &ProvidedSpec{
+		Doc: &openapi3.T{
+			Servers: openapi3.Servers{
+				{URL: "https://api.example.com"},
+				{URL: "https://api.example.com/api"},
+				{
+					URL: "https://api.example.com/api/{version}",
+					Variables: map[string]*openapi3.ServerVariable{
+						"version": {Default: "v1", Enum: []string{"v1", "v2"}},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		path      string
+		wantURL   string
+		wantFound bool
+	}{
+		{
+			name:      "longest base path",
+			path:      "/api/v2/users",
+			wantURL:   "https://api.example.com/api/v2",
+			wantFound: true,
+		},
+		{
+			name:      "variable value not in enum",
+			path:      "/api/v3/users",
+			wantURL:   "https://api.example.com/api",
+			wantFound: true,
+		},
+		{
+			name:      "root base path",
+			path:      "/apis/users",
+			wantURL:   "https://api.example.com",
+			wantFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := p.GetMatchingServer(tt.path)
+			assert.Equal(t, found, tt.wantFound)
+			if found {
+				assert.Equal(t, got.URL, tt.wantURL)
+			}
+		})
+	}
+
+	// no root server
+	p = &ProvidedSpec{Doc: &openapi3.T{Servers: openapi3.Servers{{URL: "/api"}}}}
+	_, found := p.GetMatchingServer("/users")
+	assert.Assert(t, !found)
+}
+
+func TestProvidedSpec_GetMatchingPathTemplate(t *testing.T) {
+	doc := &openapi3.T{
+		Servers: []*openapi3.Server{
+			{
+				URL: "https://api.example.com/api",
+			},
+		},
+		Paths: openapi3.Paths{
+			"/users/{id}":                   &openapi3.PathItem{},
+			"/users/{id}/orders/{order_id}": &openapi3.PathItem{},
+			"/users/me":                     &openapi3.PathItem{},
+			"/reports/report-{id}.{ext}":    &openapi3.PathItem{},
+		},
+	}
+	tests := []struct {
+		name              string
+		parameterizedPath string
+		want              string
+		wantFound         bool
+	}{
+		{
+			name:              "match with different param names",
+			parameterizedPath: "/api/users/{userId}/orders/{orderId}",
+			want:              "/api/users/{id}/orders/{order_id}",
+			wantFound:         true,
+		},
+		{
+			name:              "static segment does not match param",
+			parameterizedPath: "/api/users/me",
+			want:              "/api/users/me",
+			wantFound:         true,
+		},
+		{
+			name:              "param does not match static segment",
+			parameterizedPath: "/api/users/{userId}/orders/latest",
+			want:              "",
+			wantFound:         false,
+		},
+		{
+			name:              "partial segment params",
+			parameterizedPath: "/api/reports/report-{reportId}.{param2}",
+			want:              "/api/reports/report-{id}.{ext}",
+			wantFound:         true,
+		},
+		{
+			name:              "different length",
+			parameterizedPath: "/api/users",
+			want:              "",
+			wantFound:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ProvidedSpec{
+				Doc: doc,
+			}
+			got, found := p.GetMatchingPathTemplate(tt.parameterizedPath)
+			if got != tt.want {
+				t.Errorf("GetMatchingPathTemplate() got = %v, want %v", got, tt.want)
+			}
+			if found != tt.wantFound {
+				t.Errorf("GetMatchingPathTemplate() found = %v, want %v", found, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestProvidedSpec_GetAmbiguousPaths(t *testing.T) {
+	p := &ProvidedSpec{
+		Doc: &openapi3.T{
+			Paths: openapi3.Paths{
+				"/a/{x}/c": &openapi3.PathItem{},
+				"/a/b/{y}": &openapi3.PathItem{},
+				"/a/b":     &openapi3.PathItem{},
+			},
+		},
+	}
+	want := []pathtrie.AmbiguousPaths{
+		{
+			PreferredPath: "/a/b/{y}",
+			OtherPath:     "/a/{x}/c",
+		},
+	}
+	if got := p.GetAmbiguousPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAmbiguousPaths() = %v, want %v", got, want)
+	}
+}
+
 func Test_clearRefFromDoc(t *testing.T) {
 	type args struct {
 		doc *openapi3.T
@@ -1680,7 +1980,7 @@ func Test_clearRefFromSchema(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.DeepEqual(t, clearRefFromSchema(tt.args.schema), tt.want, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}))
+			assert.DeepEqual(t, clearRefFromSchema(tt.args.schema, make(map[*openapi3.Schema]bool)), tt.want, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}))
 		})
 	}
 }
@@ -1740,7 +2040,7 @@ func Test_clearRefFromSchemas(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.DeepEqual(t, clearRefFromSchemas(tt.args.schemas), tt.want, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}))
+			assert.DeepEqual(t, clearRefFromSchemas(tt.args.schemas, make(map[*openapi3.Schema]bool)), tt.want, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}))
 		})
 	}
 }
@@ -1828,7 +2128,7 @@ func Test_clearRefFromSchemaRefs(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.DeepEqual(t, clearRefFromSchemaRefs(tt.args.schemaRefs), tt.want, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}))
+			assert.DeepEqual(t, clearRefFromSchemaRefs(tt.args.schemaRefs, make(map[*openapi3.Schema]bool)), tt.want, cmpopts.IgnoreUnexported(openapi3.Schema{}), cmpopts.IgnoreTypes(openapi3.ExtensionProps{}))
 		})
 	}
 }
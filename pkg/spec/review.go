@@ -75,18 +75,51 @@ func (s *Spec) CreateSuggestedReview() *SuggestedSpecReview {
 }
 
 func (s *Spec) createLearningParametrizedPaths() *LearningParametrizedPaths {
-	var learningParametrizedPaths LearningParametrizedPaths
+	return &LearningParametrizedPaths{
+		Paths: s.createParametrizedPaths(s.LearningSpec.PathItems),
+	}
+}
 
-	learningParametrizedPaths.Paths = make(map[string]map[string]bool)
+// createParametrizedPaths groups the learned paths by their parameterized path.
+func (s *Spec) createParametrizedPaths(pathItems map[string]*oapi_spec.PathItem) map[string]map[string]bool {
+	parametrizedPaths := make(map[string]map[string]bool)
 
-	for path := range s.LearningSpec.PathItems {
+	for path := range pathItems {
 		parameterizedPath := createParameterizedPath(path)
-		if _, ok := learningParametrizedPaths.Paths[parameterizedPath]; !ok {
-			learningParametrizedPaths.Paths[parameterizedPath] = make(map[string]bool)
+		if _, ok := parametrizedPaths[parameterizedPath]; !ok {
+			parametrizedPaths[parameterizedPath] = make(map[string]bool)
 		}
-		learningParametrizedPaths.Paths[parameterizedPath][path] = true
+		parametrizedPaths[parameterizedPath][path] = true
+	}
+
+	// group sibling paths that can't be identified as params by themselves (e.g. /users/alice, /users/bob)
+	parametrizedPaths = parameterizeByCardinality(parametrizedPaths, pathItems)
+
+	if s.HasProvidedSpec() {
+		parametrizedPaths = s.useProvidedSpecParamNames(parametrizedPaths)
 	}
-	return &learningParametrizedPaths
+
+	return parametrizedPaths
+}
+
+// useProvidedSpecParamNames replaces parameterized paths with the provided spec path template
+// when they match, so that the provided spec param names are reused.
+func (s *Spec) useProvidedSpecParamNames(parametrizedPaths map[string]map[string]bool) map[string]map[string]bool {
+	ret := make(map[string]map[string]bool)
+
+	for parametrizedPath, paths := range parametrizedPaths {
+		if template, ok := s.ProvidedSpec.GetMatchingPathTemplate(parametrizedPath); ok {
+			parametrizedPath = template
+		}
+		if _, ok := ret[parametrizedPath]; !ok {
+			ret[parametrizedPath] = make(map[string]bool)
+		}
+		for path := range paths {
+			ret[parametrizedPath][path] = true
+		}
+	}
+
+	return ret
 }
 
 func (s *Spec) ApplyApprovedReview(approvedReviews *ApprovedSpecReview, version OASVersion) error {
@@ -101,6 +134,13 @@ func (s *Spec) ApplyApprovedReview(approvedReviews *ApprovedSpecReview, version
 
 	for _, pathItemReview := range approvedReviews.PathItemsReview {
 		mergedPathItem := &oapi_spec.PathItem{}
+		// evolve the operations and path params of a path adopted from the provided spec
+		if clonedSpec.AdoptedPaths[pathItemReview.ParameterizedPath] {
+			if approvedPathItem := clonedSpec.ApprovedSpec.GetPathItem(pathItemReview.ParameterizedPath); approvedPathItem != nil {
+				mergedPathItem = MergePathItems(mergedPathItem, approvedPathItem)
+				mergedPathItem.Parameters = append(oapi_spec.Parameters{}, approvedPathItem.Parameters...)
+			}
+		}
 		for path := range pathItemReview.Paths {
 			pathItem, ok := approvedReviews.PathToPathItem[path]
 			if !ok {
@@ -119,15 +159,21 @@ func (s *Spec) ApplyApprovedReview(approvedReviews *ApprovedSpecReview, version
 		clonedSpec.ApprovedSpec.PathItems[pathItemReview.ParameterizedPath] = mergedPathItem
 
 		// add the modified path to the path tree
-		isNewPath := clonedSpec.ApprovedPathTrie.Insert(pathItemReview.ParameterizedPath, pathItemReview.PathUUID)
+		pathUUID := pathItemReview.PathUUID
+		if pathUUID == "" {
+			pathUUID = clonedSpec.GeneratePathID(pathItemReview.ParameterizedPath)
+		}
+		isNewPath := clonedSpec.ApprovedPathTrie.Insert(pathItemReview.ParameterizedPath, pathUUID)
 		if !isNewPath {
-			log.Warnf("Path was updated, a new path should be created in a normal case. path=%v, uuid=%v", pathItemReview.ParameterizedPath, pathItemReview.PathUUID)
+			log.Warnf("Path was updated, a new path should be created in a normal case. path=%v, uuid=%v", pathItemReview.ParameterizedPath, pathUUID)
 		}
 
 		// populate SecuritySchemes from the approved merged path item
 		clonedSpec.ApprovedSpec.SecuritySchemes = updateSecuritySchemesFromPathItem(clonedSpec.ApprovedSpec.SecuritySchemes, mergedPathItem)
 	}
 
+	setOperationsInfo(clonedSpec.ApprovedSpec.PathItems, clonedSpec.OperationOverrides)
+
 	if _, err := clonedSpec.GenerateOASJson(version); err != nil {
 		return fmt.Errorf("failed to generate Open API Spec. %w", err)
 	}
@@ -158,16 +204,48 @@ func addPathParamsToPathItem(pathItem *oapi_spec.PathItem, suggestedPath string,
 	parts := strings.Split(suggestedPathTrimed, "/")
 
 	for i, part := range parts {
-		if !utils.IsPathParam(part) {
+		if !utils.HasPathParam(part) {
 			continue
 		}
 
-		part = strings.TrimPrefix(part, utils.ParamPrefix)
-		part = strings.TrimSuffix(part, utils.ParamSuffix)
-		paramList := getOnlyIndexedPartFromPaths(paths, i)
-		paramInfo := createPathParam(part, getParamSchema(paramList))
-		pathItem.Parameters = append(pathItem.Parameters, &oapi_spec.ParameterRef{
-			Value: paramInfo.Parameter,
-		})
+		// collect the values of every param in the segment, e.g. for `{name}.{ext}` and `a.json`, `b.json`:
+		// name -> a, b and ext -> json, json
+		tokens := utils.SplitPathSegmentTemplate(part)
+		var paramsLists [][]string
+		for _, segment := range getOnlyIndexedPartFromPaths(paths, i) {
+			values, ok := utils.MatchPathSegmentTemplate(part, segment)
+			if !ok {
+				continue
+			}
+			if paramsLists == nil {
+				paramsLists = make([][]string, len(values))
+			}
+			for j, value := range values {
+				paramsLists[j] = append(paramsLists[j], value)
+			}
+		}
+
+		paramIndex := 0
+		for _, token := range tokens {
+			if !utils.IsPathParam(token) {
+				continue
+			}
+			var paramList []string
+			if paramIndex < len(paramsLists) {
+				paramList = paramsLists[paramIndex]
+			}
+			paramIndex++
+
+			name := strings.TrimPrefix(token, utils.ParamPrefix)
+			name = strings.TrimSuffix(name, utils.ParamSuffix)
+			if pathItem.Parameters.GetByInAndName(oapi_spec.ParameterInPath, name) != nil {
+				// keep an existing param (e.g. of a path adopted from the provided spec)
+				continue
+			}
+			paramInfo := createPathParam(name, getParamSchema(paramList))
+			pathItem.Parameters = append(pathItem.Parameters, &oapi_spec.ParameterRef{
+				Value: paramInfo.Parameter,
+			})
+		}
 	}
 }
@@ -103,7 +103,7 @@ func TestSpec_ApplyApprovedReview(t *testing.T) {
 					ApprovedSpec: &ApprovedSpec{
 						PathItems: map[string]*oapi_spec.PathItem{
 							"/api/{param1}": &NewTestPathItem().
-								WithOperation(http.MethodGet, NewOperation(t, DataCombined).Op).
+								WithOperation(http.MethodGet, NewOperation(t, DataCombined).WithOperationInfo("getApi", "Get api", "api").Op).
 								WithPathParams("param1", oapi_spec.NewInt64Schema()).PathItem,
 						},
 						SpecVersion: OASv3,
@@ -176,10 +176,10 @@ func TestSpec_ApplyApprovedReview(t *testing.T) {
 					ApprovedSpec: &ApprovedSpec{
 						PathItems: map[string]*oapi_spec.PathItem{
 							"/api/{param1}": &NewTestPathItem().
-								WithOperation(http.MethodGet, NewOperation(t, Data2).Op).
+								WithOperation(http.MethodGet, NewOperation(t, Data2).WithOperationInfo("getApi", "Get api", "api").Op).
 								WithPathParams("param1", oapi_spec.NewInt64Schema()).PathItem,
 							"/api/1": &NewTestPathItem().
-								WithOperation(http.MethodPost, NewOperation(t, Data).Op).PathItem,
+								WithOperation(http.MethodPost, NewOperation(t, Data).WithOperationInfo("postApi1", "Post api 1", "api").Op).PathItem,
 						},
 						SpecVersion: OASv2,
 					},
@@ -252,8 +252,8 @@ func TestSpec_ApplyApprovedReview(t *testing.T) {
 					ApprovedSpec: &ApprovedSpec{
 						PathItems: map[string]*oapi_spec.PathItem{
 							"/api/{test}": &NewTestPathItem().
-								WithOperation(http.MethodPost, NewOperation(t, Data).Op).
-								WithOperation(http.MethodGet, NewOperation(t, Data).Op).
+								WithOperation(http.MethodPost, NewOperation(t, Data).WithOperationInfo("postApi", "Post api", "api").Op).
+								WithOperation(http.MethodGet, NewOperation(t, Data).WithOperationInfo("getApi", "Get api", "api").Op).
 								WithPathParams("test", oapi_spec.NewStringSchema()).PathItem,
 						},
 						SpecVersion: OASv3,
@@ -343,12 +343,12 @@ func TestSpec_ApplyApprovedReview(t *testing.T) {
 					ApprovedSpec: &ApprovedSpec{
 						PathItems: map[string]*oapi_spec.PathItem{
 							"/api/{param1}": &NewTestPathItem().
-								WithOperation(http.MethodGet, NewOperation(t, Data).Op).
+								WithOperation(http.MethodGet, NewOperation(t, Data).WithOperationInfo("getApi", "Get api", "api").Op).
 								WithPathParams("param1", oapi_spec.NewInt64Schema()).PathItem,
 							"/api/foo": &NewTestPathItem().
-								WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
+								WithOperation(http.MethodGet, NewOperation(t, Data).WithOperationInfo("getApiFoo", "Get api foo", "api").Op).PathItem,
 							"/user/{param1}/bar/{param2}": &NewTestPathItem().
-								WithOperation(http.MethodGet, NewOperation(t, Data).Op).
+								WithOperation(http.MethodGet, NewOperation(t, Data).WithOperationInfo("getUserBar", "Get user bar", "user").Op).
 								WithPathParams("param1", oapi_spec.NewInt64Schema()).
 								WithPathParams("param2", oapi_spec.NewInt64Schema()).PathItem,
 						},
@@ -443,12 +443,15 @@ func TestSpec_ApplyApprovedReview(t *testing.T) {
 						PathItems: map[string]*oapi_spec.PathItem{
 							"/api/{param1}": &NewTestPathItem().WithOperation(http.MethodGet,
 								NewOperation(t, Data).
-									WithSecurityRequirement(oapi_spec.SecurityRequirement{BasicAuthSecuritySchemeKey: {}}).Op).
+									WithSecurityRequirement(oapi_spec.SecurityRequirement{BasicAuthSecuritySchemeKey: {}}).
+									WithOperationInfo("getApi", "Get api", "api").Op).
 								WithPathParams("param1", oapi_spec.NewInt64Schema()).PathItem,
-							"/api/foo": &NewTestPathItem().WithOperation(http.MethodGet, NewOperation(t, Data).Op).PathItem,
+							"/api/foo": &NewTestPathItem().WithOperation(http.MethodGet,
+								NewOperation(t, Data).WithOperationInfo("getApiFoo", "Get api foo", "api").Op).PathItem,
 							"/user/{param1}/bar/{param2}": &NewTestPathItem().WithOperation(http.MethodGet,
 								NewOperation(t, Data).
-									WithSecurityRequirement(oapi_spec.SecurityRequirement{OAuth2SecuritySchemeKey: {}}).Op).
+									WithSecurityRequirement(oapi_spec.SecurityRequirement{OAuth2SecuritySchemeKey: {}}).
+									WithOperationInfo("getUserBar", "Get user bar", "user").Op).
 								WithPathParams("param1", oapi_spec.NewInt64Schema()).
 								WithPathParams("param2", oapi_spec.NewInt64Schema()).PathItem,
 						},
@@ -520,7 +523,7 @@ func TestSpec_CreateSuggestedReview(t *testing.T) {
 				},
 				LearningParametrizedPaths: &LearningParametrizedPaths{
 					Paths: map[string]map[string]bool{
-						"/api/{param1}": {"/api/1": true, "/api/2": true},
+						"/api/{apiId}": {"/api/1": true, "/api/2": true},
 					},
 				},
 			},
@@ -532,7 +535,7 @@ func TestSpec_CreateSuggestedReview(t *testing.T) {
 				PathItemsReview: []*SuggestedSpecReviewPathItem{
 					{
 						ReviewPathItem: ReviewPathItem{
-							ParameterizedPath: "/api/{param1}",
+							ParameterizedPath: "/api/{apiId}",
 							Paths: map[string]bool{
 								"/api/1": true,
 								"/api/2": true,
@@ -556,9 +559,9 @@ func TestSpec_CreateSuggestedReview(t *testing.T) {
 				},
 				LearningParametrizedPaths: &LearningParametrizedPaths{
 					Paths: map[string]map[string]bool{
-						"/api/{param1}":                  {"/api/1": true, "/api/2": true},
-						"/api/foo/{param1}/bar/{param2}": {"/api/foo/1/bar/2": true},
-						"/api/foo":                       {"/api/foo": true},
+						"/api/{apiId}":                 {"/api/1": true, "/api/2": true},
+						"/api/foo/{fooId}/bar/{barId}": {"/api/foo/1/bar/2": true},
+						"/api/foo":                     {"/api/foo": true},
 					},
 				},
 			},
@@ -572,7 +575,7 @@ func TestSpec_CreateSuggestedReview(t *testing.T) {
 				PathItemsReview: []*SuggestedSpecReviewPathItem{
 					{
 						ReviewPathItem: ReviewPathItem{
-							ParameterizedPath: "/api/{param1}",
+							ParameterizedPath: "/api/{apiId}",
 							Paths: map[string]bool{
 								"/api/1": true,
 								"/api/2": true,
@@ -581,7 +584,7 @@ func TestSpec_CreateSuggestedReview(t *testing.T) {
 					},
 					{
 						ReviewPathItem: ReviewPathItem{
-							ParameterizedPath: "/api/foo/{param1}/bar/{param2}",
+							ParameterizedPath: "/api/foo/{fooId}/bar/{barId}",
 							Paths: map[string]bool{
 								"/api/foo/1/bar/2": true,
 							},
@@ -647,7 +650,7 @@ func TestSpec_createLearningParametrizedPaths(t *testing.T) {
 			},
 			want: &LearningParametrizedPaths{
 				Paths: map[string]map[string]bool{
-					"/api/{param1}": {"/api/1": true},
+					"/api/{apiId}": {"/api/1": true},
 				},
 			},
 		},
@@ -706,6 +709,21 @@ func Test_addPathParamsToPathItem(t *testing.T) {
 				WithPathParams("param1", oapi_spec.NewInt64Schema()).
 				WithPathParams("param2", oapi_spec.NewInt64Schema()).PathItem,
 		},
+		{
+			name: "partial segment params",
+			args: args{
+				pathItem:      &NewTestPathItem().PathItem,
+				suggestedPath: "/files/{name}.{ext}/report-{reportId}",
+				paths: map[string]bool{
+					"files/a.json/report-1":   true,
+					"files/b.yaml/report-234": true,
+				},
+			},
+			wantPathItem: &NewTestPathItem().
+				WithPathParams("name", oapi_spec.NewStringSchema()).
+				WithPathParams("ext", oapi_spec.NewStringSchema()).
+				WithPathParams("reportId", oapi_spec.NewInt64Schema()).PathItem,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -38,13 +38,16 @@ func updateSchemas(schemas spec.Schemas, op *spec.Operation) (retSchemas spec.Sc
 		return schemas, op
 	}
 
-	for i, response := range op.Responses {
+	// go over the responses and contents in a sorted order, so the generated schema names are stable
+	for _, code := range sortedStatusCodes(op.Responses) {
+		response := op.Responses[code]
 		if response.Value == nil {
 			continue
 		}
-		for content, mediaType := range response.Value.Content {
+		for _, content := range sortedMediaTypes(response.Value.Content) {
+			mediaType := response.Value.Content[content]
 			schemas, mediaType.Schema = schemaToRef(schemas, mediaType.Schema.Value, "", 0)
-			op.Responses[i].Value.Content[content] = mediaType
+			op.Responses[code].Value.Content[content] = mediaType
 		}
 	}
 
@@ -52,14 +55,16 @@ func updateSchemas(schemas spec.Schemas, op *spec.Operation) (retSchemas spec.Sc
 		if parameter.Value == nil {
 			continue
 		}
-		for content, mediaType := range parameter.Value.Content {
+		for _, content := range sortedMediaTypes(parameter.Value.Content) {
+			mediaType := parameter.Value.Content[content]
 			schemas, mediaType.Schema = schemaToRef(schemas, mediaType.Schema.Value, "", 0)
 			op.Parameters[i].Value.Content[content] = mediaType
 		}
 	}
 
 	if op.RequestBody != nil && op.RequestBody.Value != nil {
-		for content, mediaType := range op.RequestBody.Value.Content {
+		for _, content := range sortedMediaTypes(op.RequestBody.Value.Content) {
+			mediaType := op.RequestBody.Value.Content[content]
 			schemas, mediaType.Schema = schemaToRef(schemas, mediaType.Schema.Value, "", 0)
 			op.RequestBody.Value.Content[content] = mediaType
 		}
@@ -97,9 +102,9 @@ func schemaToRef(schemas spec.Schemas, schema *spec.Schema, schemeNameHint strin
 		return schemas, spec.NewSchemaRef("", schema)
 	}
 
-	// go over all properties in the object and convert each one to ref if needed
+	// go over all properties in the object (sorted, for stable schema names) and convert each one to ref if needed
 	var propNames []string
-	for propName := range schema.Properties {
+	for _, propName := range sortedSchemaNames(schema.Properties) {
 		var ref *spec.SchemaRef
 		schemas, ref = schemaToRef(schemas, schema.Properties[propName].Value, propName, depth+1)
 		if ref != nil {
@@ -150,11 +155,22 @@ func getUniqueSchemeName(schemes spec.Schemas, name string) string {
 	}
 }
 
+func sortedSchemaNames(schemas spec.Schemas) []string {
+	ret := make([]string, 0, len(schemas))
+	for name := range schemas {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
 // will look for identical scheme in schemes map.
 func findScheme(schemas spec.Schemas, schema *spec.Schema) (schemeName string, exist bool) {
 	schemaBytes, _ := json.Marshal(schema)
 	differ := gojsondiff.New()
-	for name, defSchema := range schemas {
+	for _, name := range sortedSchemaNames(schemas) {
+		defSchema := schemas[name]
 		defSchemaBytes, _ := json.Marshal(defSchema)
 		diff, err := differ.Compare(defSchemaBytes, schemaBytes)
 		if err != nil {
@@ -63,11 +63,27 @@ type SpecInfo struct {
 
 	ApprovedPathTrie pathtrie.PathTrie
 	ProvidedPathTrie pathtrie.PathTrie
+	// Provided spec operations observed on LearnTelemetry
+	ProvidedSpecCoverage *ProvidedSpecCoverage
+	// Diff groups by fingerprint, aggregated on DiffTelemetryAggregated
+	DiffGroups map[string]*DiffGroup
+	// Shadow diff paths and their methods, used to parameterize the shadow diff groups paths
+	ShadowDiffPaths map[string]map[string]bool
+	// Metadata of the generated spec
+	Metadata *SpecMetadata
+	// Telemetry schemes observed on LearnTelemetry, used for the generated spec default servers
+	ObservedSchemes []string
+	// Operation id, summary and tags overrides by approved path
+	OperationOverrides map[string]*OperationOverride
+	// JSON body samples by operation (see getBodySamplesKey), recorded on LearnTelemetry
+	BodySamples map[string][]*BodySample
+	// Approved paths adopted from the provided spec, evolved (rather than replaced) on ApplyApprovedReview
+	AdoptedPaths map[string]bool
 }
 
 type LearningParametrizedPaths struct {
 	// map parameterized paths into a list of paths included in it.
-	// e.g: /api/{param1} -> /api/1, /api/2
+	// e.g: /api/{apiId} -> /api/1, /api/2
 	// non parameterized path will map to itself
 	Paths map[string]map[string]bool
 }
@@ -143,6 +159,7 @@ func (s *Spec) UnsetProvidedSpec() {
 
 	s.ProvidedSpec = nil
 	s.ProvidedPathTrie = pathtrie.New()
+	s.ProvidedSpecCoverage = nil
 }
 
 func (s *Spec) LearnTelemetry(telemetry *Telemetry) error {
@@ -175,6 +192,13 @@ func (s *Spec) LearnTelemetry(telemetry *Telemetry) error {
 
 	// add/update this path item in the spec
 	s.LearningSpec.AddPathItem(path, pathItem)
+	s.addObservedScheme(telemetry.Scheme)
+	s.recordBodySample(telemetry)
+
+	if err := s.recordProvidedSpecCoverage(telemetry); err != nil {
+		// the telemetry was already learned
+		log.Errorf("Failed to record provided spec coverage. %v", err)
+	}
 
 	return nil
 }
@@ -190,9 +214,7 @@ func (s *Spec) GetPathID(path string, specSource SpecSource) (string, error) {
 			log.Infof("No provided spec, path id will be empty")
 			return "", nil
 		}
-		basePath := s.ProvidedSpec.GetBasePath()
-
-		pathNoBase := trimBasePathIfNeeded(basePath, path)
+		_, pathNoBase := s.ProvidedSpec.splitBasePath(path)
 
 		_, value, found := s.ProvidedPathTrie.GetPathAndValue(pathNoBase)
 		if found {
@@ -221,8 +243,17 @@ func (s *Spec) GetPathID(path string, specSource SpecSource) (string, error) {
 	return specID, nil
 }
 
-func (s *Spec) GenerateOASYaml(version OASVersion) ([]byte, error) {
-	oasJSON, err := s.GenerateOASJson(version)
+type GenerateParams struct {
+	// globalMetadata is used for the metadata fields that are not set by the spec metadata
+	globalMetadata *SpecMetadata
+	// canonical will generate the spec in a canonical form
+	canonical bool
+}
+
+type GenerateOption func(*GenerateParams)
+
+func (s *Spec) GenerateOASYaml(version OASVersion, opts ...GenerateOption) ([]byte, error) {
+	oasJSON, err := s.GenerateOASJson(version, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate json spec: %w", err)
 	}
@@ -235,7 +266,12 @@ func (s *Spec) GenerateOASYaml(version OASVersion) ([]byte, error) {
 	return oasYaml, nil
 }
 
-func (s *Spec) GenerateOASJson(version OASVersion) ([]byte, error) {
+func (s *Spec) GenerateOASJson(version OASVersion, opts ...GenerateOption) ([]byte, error) {
+	params := &GenerateParams{}
+	for _, opt := range opts {
+		opt(params)
+	}
+
 	// yaml.Marshal does not omit empty fields
 	var schemas oapi_spec.Schemas
 
@@ -244,46 +280,76 @@ func (s *Spec) GenerateOASJson(version OASVersion) ([]byte, error) {
 		return nil, fmt.Errorf("failed to clone approved spec. %v", err)
 	}
 
+	if params.canonical {
+		canonicalizePathItems(clonedApprovedSpec.PathItems)
+	}
 	clonedApprovedSpec.PathItems, schemas = reconstructObjectRefs(clonedApprovedSpec.PathItems)
 
+	metadata := s.getGeneratedSpecMetadata(params.globalMetadata)
 	generatedSpec := &oapi_spec.T{
-		OpenAPI: "3.0.3",
+		OpenAPI: openAPIVersion30,
 		Components: oapi_spec.Components{
 			Schemas: schemas,
 		},
-		Info:  createDefaultSwaggerInfo(),
-		Paths: clonedApprovedSpec.PathItems,
-		Servers: oapi_spec.Servers{
-			{
-				// https://swagger.io/docs/specification/api-host-and-base-path/
-				URL: "http://" + s.Host + ":" + s.Port,
-			},
-		},
+		Info:         metadata.createInfo(),
+		Paths:        clonedApprovedSpec.PathItems,
+		Servers:      metadata.Servers,
+		Tags:         metadata.Tags,
+		ExternalDocs: metadata.ExternalDocs,
+	}
+
+	ret, err := marshalOASJson(generatedSpec, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err = LoadAndValidateRawJSONSpec(ret); err != nil {
+		log.Errorf("Failed to validate the spec. %v\n\nspec: %s", err, ret)
+		return nil, fmt.Errorf("failed to validate the spec. %w", err)
 	}
 
+	if params.canonical {
+		if ret, err = formatCanonicalJSON(ret); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// marshalOASJson marshals the OpenAPI 3.0 document in the given version.
+func marshalOASJson(doc *oapi_spec.T, version OASVersion) ([]byte, error) {
 	var ret []byte
-	if version == OASv2 {
+	switch version {
+	case OASv2:
 		log.Debugf("Generating OASv2 spec")
-		generatedSpecV2, err := openapi2conv.FromV3(generatedSpec)
+		docV2, err := openapi2conv.FromV3(doc)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert spec from v3: %v", err)
 		}
 
-		ret, err = json.Marshal(generatedSpecV2)
+		ret, err = json.Marshal(docV2)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal the spec. %v", err)
 		}
-	} else {
-		log.Debugf("Generating OASv3 spec")
-		ret, err = json.Marshal(generatedSpec)
+	case OASv31:
+		log.Debugf("Generating OASv3.1 spec")
+		specV3, err := json.Marshal(doc)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal the spec. %v", err)
 		}
-	}
 
-	if _, _, err = LoadAndValidateRawJSONSpec(ret); err != nil {
-		log.Errorf("Failed to validate the spec. %v\n\nspec: %s", err, ret)
-		return nil, fmt.Errorf("failed to validate the spec. %w", err)
+		ret, err = convertSpecV30ToV31(specV3)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert spec to v3.1: %v", err)
+		}
+	default:
+		var err error
+		log.Debugf("Generating OASv3 spec")
+		ret, err = json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the spec. %v", err)
+		}
 	}
 
 	return ret, nil
@@ -347,7 +413,9 @@ func LoadAndValidateRawJSONSpecV3FromV2(spec []byte) (*oapi_spec.T, error) {
 }
 
 func reconstructObjectRefs(pathItems map[string]*oapi_spec.PathItem) (retPathItems map[string]*oapi_spec.PathItem, schemas oapi_spec.Schemas) {
-	for _, item := range pathItems {
+	// go over the paths in a sorted order, so the generated schema names are stable
+	for _, path := range sortedPaths(pathItems) {
+		item := pathItems[path]
 		schemas, item.Get = updateSchemas(schemas, item.Get)
 		schemas, item.Put = updateSchemas(schemas, item.Put)
 		schemas, item.Post = updateSchemas(schemas, item.Post)
@@ -0,0 +1,695 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	refKey = "$ref"
+
+	refFragmentSeparator = "#"
+
+	// maxSpecBundleArchiveSize limits the total size of the spec files that are extracted from an archive.
+	maxSpecBundleArchiveSize = 64 << 20 // 64MB
+)
+
+var (
+	ErrSpecBundleFileNotFound     = errors.New("spec bundle file not found")
+	ErrSpecBundleRootFileNotFound = errors.New("spec bundle root file not found")
+	ErrSpecBundleAmbiguousRoot    = errors.New("spec bundle has more than one root file candidate")
+	ErrSpecBundleTooLarge         = errors.New("spec bundle is too large")
+)
+
+var (
+	zipMagic  = []byte("PK\x03\x04")
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// SpecBundle is a provided spec split across multiple files, referencing each other with relative external refs
+// (e.g. $ref: ./schemas/user.yaml#/User).
+type SpecBundle struct {
+	// RootFile is the bundle file name of the spec document.
+	RootFile string
+	// Files by their slash separated name, relative to the bundle root (e.g. schemas/user.yaml).
+	Files map[string][]byte
+}
+
+// NewSingleFileSpecBundle returns a bundle of a single spec file.
+func NewSingleFileSpecBundle(spec []byte) *SpecBundle {
+	const rootFile = "openapi.json"
+
+	return &SpecBundle{
+		RootFile: rootFile,
+		Files:    map[string][]byte{rootFile: spec},
+	}
+}
+
+// LoadSpecBundle loads a bundle from a zip, tar or tar.gz archive, any other data is loaded as a single spec file.
+func LoadSpecBundle(data []byte) (*SpecBundle, error) {
+	if !isSpecArchive(data) {
+		return NewSingleFileSpecBundle(data), nil
+	}
+
+	return LoadSpecBundleFromArchive(data, "")
+}
+
+// LoadSpecBundleFromDir loads the spec (json or yaml) files under the directory.
+// The root file is detected (see NewSpecBundle) if not set.
+func LoadSpecBundleFromDir(dir, rootFile string) (*SpecBundle, error) {
+	files := make(map[string][]byte)
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isSpecFileName(filePath) {
+			return nil
+		}
+		name, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[name] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec directory %v: %w", dir, err)
+	}
+
+	return NewSpecBundle(files, rootFile)
+}
+
+// LoadSpecBundleFromFile loads a spec file, or a zip, tar or tar.gz archive (see LoadSpecBundleFromArchive).
+// The files that are reached through the external refs of a spec file are loaded as well, resolved relative to the
+// file that references them, so the bundle root is the spec file directory.
+func LoadSpecBundleFromFile(filePath string) (*SpecBundle, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file %v: %w", filePath, err)
+	}
+	if isSpecArchive(data) {
+		return LoadSpecBundleFromArchive(data, "")
+	}
+
+	dir := filepath.Dir(filePath)
+	rootFile := cleanBundleFileName(filepath.Base(filePath))
+	files := map[string][]byte{rootFile: data}
+	pending := []string{rootFile}
+	for len(pending) > 0 {
+		fileName := pending[0]
+		pending = pending[1:]
+		for _, refFile := range getExternalRefFiles(files[fileName], fileName) {
+			if _, ok := files[refFile]; ok {
+				continue
+			}
+			refData, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(refFile)))
+			if err != nil {
+				// a missing ref file is reported when the refs are resolved
+				log.Debugf("Failed to read ref file %v of %v. %v", refFile, fileName, err)
+				continue
+			}
+			files[refFile] = refData
+			pending = append(pending, refFile)
+		}
+	}
+
+	return NewSpecBundle(files, rootFile)
+}
+
+// getExternalRefFiles returns the bundle file names of the external refs of the file, resolved relative to the file.
+func getExternalRefFiles(data []byte, fileName string) []string {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil
+	}
+
+	var ret []string
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := value[refKey].(string); ok {
+				if refFile, _ := splitRef(ref); refFile != "" {
+					if refURL, err := url.Parse(refFile); err == nil && !refURL.IsAbs() && refURL.Host == "" {
+						ret = append(ret, cleanBundleFileName(path.Join(path.Dir(fileName), refURL.Path)))
+					}
+				}
+			}
+			for _, item := range value {
+				collect(item)
+			}
+		case []interface{}:
+			for _, item := range value {
+				collect(item)
+			}
+		}
+	}
+	collect(doc)
+
+	return ret
+}
+
+// LoadSpecBundleFromArchive loads the spec (json or yaml) files of a zip, tar or tar.gz archive.
+// The root file is detected (see NewSpecBundle) if not set.
+func LoadSpecBundleFromArchive(data []byte, rootFile string) (*SpecBundle, error) {
+	var files map[string][]byte
+	var err error
+	if bytes.HasPrefix(data, zipMagic) {
+		files, err = readZipFiles(data, maxSpecBundleArchiveSize)
+	} else {
+		files, err = readTarFiles(data, maxSpecBundleArchiveSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec archive: %w", err)
+	}
+
+	return NewSpecBundle(files, rootFile)
+}
+
+// NewSpecBundle returns a bundle of the files. If the root file is not set, the root file is the shallowest file that
+// has an `openapi` or `swagger` field.
+func NewSpecBundle(files map[string][]byte, rootFile string) (*SpecBundle, error) {
+	bundle := &SpecBundle{
+		Files: make(map[string][]byte, len(files)),
+	}
+	for name, data := range files {
+		bundle.Files[cleanBundleFileName(name)] = data
+	}
+
+	if rootFile != "" {
+		bundle.RootFile = cleanBundleFileName(rootFile)
+		if _, ok := bundle.Files[bundle.RootFile]; !ok {
+			return nil, fmt.Errorf("%w: %v", ErrSpecBundleFileNotFound, rootFile)
+		}
+		return bundle, nil
+	}
+
+	rootFile, err := detectSpecBundleRootFile(bundle.Files)
+	if err != nil {
+		return nil, err
+	}
+	bundle.RootFile = rootFile
+
+	return bundle, nil
+}
+
+func detectSpecBundleRootFile(files map[string][]byte) (string, error) {
+	var candidates []string
+	for name, data := range files {
+		jsonData, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			continue
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(jsonData, &doc); err != nil {
+			continue
+		}
+		_, isOpenAPI := doc["openapi"]
+		_, isSwagger := doc["swagger"]
+		if isOpenAPI || isSwagger {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", ErrSpecBundleRootFileNotFound
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		depthI, depthJ := strings.Count(candidates[i], "/"), strings.Count(candidates[j], "/")
+		if depthI != depthJ {
+			return depthI < depthJ
+		}
+		return candidates[i] < candidates[j]
+	})
+	if len(candidates) > 1 && strings.Count(candidates[0], "/") == strings.Count(candidates[1], "/") {
+		return "", fmt.Errorf("%w: %v", ErrSpecBundleAmbiguousRoot, candidates)
+	}
+
+	return candidates[0], nil
+}
+
+func isSpecArchive(data []byte) bool {
+	if bytes.HasPrefix(data, zipMagic) || bytes.HasPrefix(data, gzipMagic) {
+		return true
+	}
+
+	_, err := tar.NewReader(bytes.NewReader(data)).Next()
+	return err == nil
+}
+
+func isSpecFileName(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// cleanBundleFileName returns a slash separated file name relative to the bundle root, a file name can't point
+// outside of the bundle (e.g. ../../schemas/user.yaml will be schemas/user.yaml).
+func cleanBundleFileName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
+
+// readZipFiles returns the spec files of the zip, the total size of the files is limited by maxSize.
+func readZipFiles(data []byte, maxSize int64) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !isSpecFileName(file.Name) {
+			continue
+		}
+		fileReader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %v: %w", file.Name, err)
+		}
+		fileData, err := readLimited(fileReader, maxSize)
+		_ = fileReader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %v: %w", file.Name, err)
+		}
+		files[file.Name] = fileData
+		maxSize -= int64(len(fileData))
+	}
+
+	return files, nil
+}
+
+// readTarFiles returns the spec files of the tar (or tar.gz), the total size of the files is limited by maxSize.
+func readTarFiles(data []byte, maxSize int64) (map[string][]byte, error) {
+	var reader io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, gzipMagic) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !isSpecFileName(header.Name) {
+			continue
+		}
+		fileData, err := readLimited(tarReader, maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %v: %w", header.Name, err)
+		}
+		files[header.Name] = fileData
+		maxSize -= int64(len(fileData))
+	}
+
+	return files, nil
+}
+
+// readLimited reads the reader up to maxSize bytes, and fails if the reader has more data.
+func readLimited(reader io.Reader, maxSize int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: more than %v bytes", ErrSpecBundleTooLarge, maxSize)
+	}
+
+	return data, nil
+}
+
+// LoadAndValidateSpecBundle inlines the external refs of the bundle root file, and loads the result as a single
+// spec file (see LoadAndValidateRawJSONSpec).
+func LoadAndValidateSpecBundle(bundle *SpecBundle) (*openapi3.T, OASVersion, error) {
+	jsonSpec, err := bundle.inlineExternalRefs()
+	if err != nil {
+		return nil, Unknown, fmt.Errorf("failed to resolve external refs: %w", err)
+	}
+
+	return LoadAndValidateRawJSONSpec(jsonSpec)
+}
+
+// inlineExternalRefs returns the root file (as json) with every external ref (e.g. $ref: ./schemas/user.yaml#/User)
+// replaced by the referenced value, refs local to the root file (e.g. $ref: '#/components/schemas/User') are kept.
+// An external ref that references itself (directly or not) is kept as a local ref to a schema that is added to the
+// root file schemas (components.schemas, or definitions for OASv2).
+func (b *SpecBundle) inlineExternalRefs() ([]byte, error) {
+	resolver := newBundleRefResolver(b)
+
+	root, err := resolver.getDoc(b.RootFile)
+	if err != nil {
+		return nil, err
+	}
+	rootMap, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("root file %v is not an object", b.RootFile)
+	}
+	resolver.schemas = getBundleRootSchemas(rootMap)
+	resolver.schemasPrefix = refFragmentSeparator + "/components/schemas/"
+	if _, isSwagger := rootMap["swagger"]; isSwagger {
+		resolver.schemasPrefix = refFragmentSeparator + "/definitions/"
+	}
+
+	inlined, err := resolver.inline(rootMap, b.RootFile)
+	if err != nil {
+		return nil, err
+	}
+	if inlinedRoot, ok := inlined.(map[string]interface{}); ok {
+		if err := resolver.addCircularRefSchemas(inlinedRoot); err != nil {
+			return nil, err
+		}
+	}
+
+	ret, err := json.Marshal(inlined)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
+	}
+
+	return ret, nil
+}
+
+type bundleRefResolver struct {
+	bundle *SpecBundle
+	// docs are the parsed bundle files by file name.
+	docs map[string]interface{}
+	// inlined are the inlined values of the resolved external refs by ref (file#fragment), so a ref that is used
+	// in many places is inlined once.
+	inlined map[string]interface{}
+	// visiting are the refs (file#fragment) currently being inlined, for detecting circular refs.
+	visiting map[string]bool
+	// schemas of the root file, and the local ref prefix of a root schema (e.g. #/components/schemas/).
+	schemas       map[string]interface{}
+	schemasPrefix string
+	// circularRefs are the circular external refs (file#fragment) in the order they were found, and circularNames
+	// are the names of the root schemas that they are added as.
+	circularRefs  []string
+	circularNames map[string]string
+}
+
+func newBundleRefResolver(bundle *SpecBundle) *bundleRefResolver {
+	return &bundleRefResolver{
+		bundle:        bundle,
+		docs:          make(map[string]interface{}),
+		inlined:       make(map[string]interface{}),
+		visiting:      make(map[string]bool),
+		circularNames: make(map[string]string),
+	}
+}
+
+func getBundleRootSchemas(root map[string]interface{}) map[string]interface{} {
+	if _, isSwagger := root["swagger"]; isSwagger {
+		schemas, _ := root["definitions"].(map[string]interface{})
+		return schemas
+	}
+	components, _ := root["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+
+	return schemas
+}
+
+// getCircularRefSchemaName returns the name of the root schema that the circular ref is added as, a unique name
+// is derived from the ref (e.g. ./schemas/user.yaml#/User -> User) on the first call.
+func (r *bundleRefResolver) getCircularRefSchemaName(refID string) string {
+	if name, ok := r.circularNames[refID]; ok {
+		return name
+	}
+
+	refFile, fragment := splitRef(refID)
+	baseName := path.Base(fragment)
+	if fragment == "" || baseName == "/" {
+		baseName = strings.TrimSuffix(path.Base(refFile), path.Ext(refFile))
+	}
+	baseName = nonAlphanumericChar.ReplaceAllString(baseName, "")
+	if baseName == "" {
+		baseName = "Schema"
+	}
+
+	isUsed := func(name string) bool {
+		if _, ok := r.schemas[name]; ok {
+			return true
+		}
+		for _, usedName := range r.circularNames {
+			if usedName == name {
+				return true
+			}
+		}
+		return false
+	}
+	name := baseName
+	for counter := 2; isUsed(name); counter++ {
+		name = fmt.Sprintf("%s%d", baseName, counter)
+	}
+
+	r.circularNames[refID] = name
+	r.circularRefs = append(r.circularRefs, refID)
+
+	return name
+}
+
+// addCircularRefSchemas adds the values of the circular refs to the inlined root file schemas. A value can have
+// circular refs that were not found yet, so the refs list can grow while adding.
+func (r *bundleRefResolver) addCircularRefSchemas(root map[string]interface{}) error {
+	if len(r.circularRefs) == 0 {
+		return nil
+	}
+
+	var schemas map[string]interface{}
+	if r.schemasPrefix == refFragmentSeparator+"/definitions/" {
+		schemas = getOrCreateObject(root, "definitions")
+	} else {
+		schemas = getOrCreateObject(getOrCreateObject(root, "components"), "schemas")
+	}
+
+	for i := 0; i < len(r.circularRefs); i++ {
+		refID := r.circularRefs[i]
+		refFile, fragment := splitRef(refID)
+		value, err := r.getRefValue(refFile, fragment)
+		if err != nil {
+			return err
+		}
+		r.visiting[refID] = true
+		inlined, err := r.inline(value, refFile)
+		delete(r.visiting, refID)
+		if err != nil {
+			return err
+		}
+		schemas[r.circularNames[refID]] = inlined
+	}
+
+	return nil
+}
+
+func getOrCreateObject(parent map[string]interface{}, key string) map[string]interface{} {
+	if object, ok := parent[key].(map[string]interface{}); ok {
+		return object
+	}
+	object := make(map[string]interface{})
+	parent[key] = object
+
+	return object
+}
+
+func (r *bundleRefResolver) getDoc(fileName string) (interface{}, error) {
+	if doc, ok := r.docs[fileName]; ok {
+		return doc, nil
+	}
+
+	data, ok := r.bundle.Files[fileName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrSpecBundleFileNotFound, fileName)
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %v into json: %w", fileName, err)
+	}
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %v: %w", fileName, err)
+	}
+	r.docs[fileName] = doc
+
+	return doc, nil
+}
+
+// inline returns a copy of the value (of the given file) with the external refs inlined.
+func (r *bundleRefResolver) inline(value interface{}, fileName string) (interface{}, error) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := value[refKey].(string); ok {
+			return r.inlineRef(ref, fileName)
+		}
+		ret := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			inlined, err := r.inline(item, fileName)
+			if err != nil {
+				return nil, err
+			}
+			ret[key] = inlined
+		}
+		return ret, nil
+	case []interface{}:
+		ret := make([]interface{}, len(value))
+		for i, item := range value {
+			inlined, err := r.inline(item, fileName)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = inlined
+		}
+		return ret, nil
+	default:
+		return value, nil
+	}
+}
+
+func (r *bundleRefResolver) inlineRef(ref, fileName string) (interface{}, error) {
+	refFile, fragment := splitRef(ref)
+	if refFile == "" {
+		refFile = fileName
+	} else {
+		refURL, err := url.Parse(refFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ref %q: %w", ref, err)
+		}
+		if refURL.IsAbs() || refURL.Host != "" {
+			return nil, fmt.Errorf("remote ref %q is not supported", ref)
+		}
+		refFile = cleanBundleFileName(path.Join(path.Dir(fileName), refURL.Path))
+	}
+
+	// local refs of the root file are resolved by the spec loader
+	if refFile == r.bundle.RootFile {
+		return map[string]interface{}{refKey: refFragmentSeparator + fragment}, nil
+	}
+
+	refID := refFile + refFragmentSeparator + fragment
+	if r.visiting[refID] {
+		name := r.getCircularRefSchemaName(refID)
+		log.Debugf("Circular external ref %q in %v, keeping it as a local ref to schema %v", ref, fileName, name)
+		return map[string]interface{}{refKey: r.schemasPrefix + name}, nil
+	}
+	if inlined, ok := r.inlined[refID]; ok {
+		return inlined, nil
+	}
+	r.visiting[refID] = true
+	defer delete(r.visiting, refID)
+
+	value, err := r.getRefValue(refFile, fragment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref %q in %v: %w", ref, fileName, err)
+	}
+
+	inlined, err := r.inline(value, refFile)
+	if err != nil {
+		return nil, err
+	}
+	r.inlined[refID] = inlined
+
+	return inlined, nil
+}
+
+func (r *bundleRefResolver) getRefValue(refFile, fragment string) (interface{}, error) {
+	doc, err := r.getDoc(refFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return getJSONPointerValue(doc, fragment)
+}
+
+func splitRef(ref string) (file, fragment string) {
+	parts := strings.SplitN(ref, refFragmentSeparator, 2) // nolint:gomnd
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// getJSONPointerValue returns the value of the JSON pointer (e.g. /components/schemas/User) in the document.
+func getJSONPointerValue(doc interface{}, pointer string) (interface{}, error) {
+	pointer, err := url.PathUnescape(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON pointer %q: %w", pointer, err)
+	}
+	if pointer == "" || pointer == "/" {
+		return doc, nil
+	}
+
+	value := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch current := value.(type) {
+		case map[string]interface{}:
+			next, ok := current[token]
+			if !ok {
+				return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+			}
+			value = current[index]
+		default:
+			return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,504 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const testBundleRootSpec = `openapi: 3.0.3
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /users/{userId}:
+    parameters:
+      - $ref: './parameters.yaml#/UserID'
+    get:
+      responses:
+        '200':
+          description: user
+          content:
+            application/json:
+              schema:
+                $ref: './schemas/user.yaml#/User'
+components:
+  schemas:
+    Error:
+      type: object
+      properties:
+        message:
+          type: string
+`
+
+const testBundleParameters = `UserID:
+  name: userId
+  in: path
+  required: true
+  schema:
+    type: string
+    format: uuid
+`
+
+const testBundleUserSchema = `User:
+  type: object
+  properties:
+    name:
+      type: string
+    address:
+      $ref: './address.yaml'
+    error:
+      $ref: '../openapi.yaml#/components/schemas/Error'
+    manager:
+      $ref: '#/User'
+`
+
+const testBundleAddressSchema = `type: object
+properties:
+  city:
+    type: string
+`
+
+const testBundleSwaggerRootSpec = `swagger: '2.0'
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      produces:
+        - application/json
+      responses:
+        '200':
+          description: users
+          schema:
+            type: array
+            items:
+              $ref: 'definitions.yaml#/User'
+`
+
+const testBundleSwaggerDefinitions = `User:
+  type: object
+  properties:
+    name:
+      type: string
+`
+
+func newTestSpecBundleFiles() map[string][]byte {
+	return map[string][]byte{
+		"openapi.yaml":         []byte(testBundleRootSpec),
+		"parameters.yaml":      []byte(testBundleParameters),
+		"schemas/user.yaml":    []byte(testBundleUserSchema),
+		"schemas/address.yaml": []byte(testBundleAddressSchema),
+	}
+}
+
+func TestLoadAndValidateSpecBundle(t *testing.T) {
+	bundle, err := NewSpecBundle(newTestSpecBundleFiles(), "")
+	assert.NilError(t, err)
+	assert.Equal(t, bundle.RootFile, "openapi.yaml")
+
+	doc, oasVersion, err := LoadAndValidateSpecBundle(bundle)
+	assert.NilError(t, err)
+	assert.Equal(t, oasVersion, OASv3)
+
+	pathItem := doc.Paths["/users/{userId}"]
+	assert.Assert(t, pathItem != nil)
+	assert.Equal(t, pathItem.Parameters[0].Value.Name, "userId")
+	assert.Equal(t, pathItem.Parameters[0].Value.Schema.Value.Format, "uuid")
+
+	user := pathItem.Get.Responses["200"].Value.Content["application/json"].Schema.Value
+	assert.Equal(t, user.Properties["name"].Value.Type, "string")
+	assert.Equal(t, user.Properties["address"].Value.Properties["city"].Value.Type, "string")
+	// a ref to the root file is kept as a local ref
+	assert.Equal(t, user.Properties["error"].Ref, "#/components/schemas/Error")
+	assert.Equal(t, user.Properties["error"].Value.Properties["message"].Value.Type, "string")
+	// a circular external ref is kept as a local ref to a schema that is added to the root file
+	assert.Equal(t, user.Properties["manager"].Ref, "#/components/schemas/User")
+	assert.Equal(t, user.Properties["manager"].Value.Properties["name"].Value.Type, "string")
+	assert.Equal(t, user.Properties["manager"].Value.Properties["manager"].Value, user.Properties["manager"].Value)
+	assert.Assert(t, doc.Components.Schemas["Error"] != nil)
+}
+
+func TestLoadAndValidateSpecBundle_OASv2(t *testing.T) {
+	bundle, err := NewSpecBundle(map[string][]byte{
+		"swagger.yaml":     []byte(testBundleSwaggerRootSpec),
+		"definitions.yaml": []byte(testBundleSwaggerDefinitions),
+	}, "")
+	assert.NilError(t, err)
+
+	doc, oasVersion, err := LoadAndValidateSpecBundle(bundle)
+	assert.NilError(t, err)
+	assert.Equal(t, oasVersion, OASv2)
+
+	users := doc.Paths["/users"].Get.Responses["200"].Value.Content["application/json"].Schema.Value
+	assert.Equal(t, users.Items.Value.Properties["name"].Value.Type, "string")
+}
+
+func TestLoadAndValidateSpecBundle_CircularRefs(t *testing.T) {
+	const nodeSchema = `Node:
+  type: object
+  properties:
+    children:
+      type: array
+      items:
+        $ref: '#/Node'
+`
+	tests := []struct {
+		name     string
+		rootSpec string
+		wantRef  string
+	}{
+		{
+			name: "root schema name is used",
+			rootSpec: `openapi: 3.0.3
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /nodes:
+    get:
+      responses:
+        '200':
+          description: node
+          content:
+            application/json:
+              schema:
+                $ref: 'node.yaml#/Node'
+components:
+  schemas:
+    Node:
+      type: string
+`,
+			wantRef: "#/components/schemas/Node2",
+		},
+		{
+			name: "OASv2",
+			rootSpec: `swagger: '2.0'
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /nodes:
+    get:
+      produces:
+        - application/json
+      responses:
+        '200':
+          description: node
+          schema:
+            $ref: 'node.yaml#/Node'
+`,
+			wantRef: "#/definitions/Node",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := NewSpecBundle(map[string][]byte{
+				"openapi.yaml": []byte(tt.rootSpec),
+				"node.yaml":    []byte(nodeSchema),
+			}, "openapi.yaml")
+			assert.NilError(t, err)
+
+			jsonSpec, err := bundle.inlineExternalRefs()
+			assert.NilError(t, err)
+			assert.Assert(t, strings.Contains(string(jsonSpec), `{"$ref":"`+tt.wantRef+`"}`), "got spec: %s", jsonSpec)
+
+			_, _, err = LoadAndValidateSpecBundle(bundle)
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func Test_bundleRefResolver_inlineRef_Shared(t *testing.T) {
+	bundle, err := NewSpecBundle(newTestSpecBundleFiles(), "")
+	assert.NilError(t, err)
+	resolver := newBundleRefResolver(bundle)
+
+	// the same ref from different files is inlined once
+	address, err := resolver.inlineRef("./address.yaml", "schemas/user.yaml")
+	assert.NilError(t, err)
+	address2, err := resolver.inlineRef("schemas/address.yaml#", "openapi.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, len(resolver.inlined), 1)
+	assert.DeepEqual(t, address2, address)
+}
+
+func TestLoadAndValidateSpecBundle_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		wantErr error
+	}{
+		{
+			name: "missing ref file",
+			files: map[string][]byte{
+				"openapi.yaml":    []byte(testBundleRootSpec),
+				"parameters.yaml": []byte(testBundleParameters),
+			},
+			wantErr: ErrSpecBundleFileNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := NewSpecBundle(tt.files, "")
+			assert.NilError(t, err)
+			_, _, err = LoadAndValidateSpecBundle(bundle)
+			assert.Assert(t, errors.Is(err, tt.wantErr), "got error: %v", err)
+		})
+	}
+}
+
+func TestLoadSpecBundleFromFile(t *testing.T) {
+	dir := t.TempDir()
+	files := newTestSpecBundleFiles()
+	// files that are not referenced by the spec file are not loaded
+	files["other/openapi.yaml"] = []byte(testBundleSwaggerRootSpec)
+	files["unused.yaml"] = []byte(testBundleSwaggerDefinitions)
+	for name, data := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(filePath), 0o700))
+		assert.NilError(t, ioutil.WriteFile(filePath, data, 0o600))
+	}
+
+	bundle, err := LoadSpecBundleFromFile(filepath.Join(dir, "openapi.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, bundle.RootFile, "openapi.yaml")
+	var names []string
+	for name := range bundle.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"openapi.yaml", "parameters.yaml", "schemas/address.yaml", "schemas/user.yaml"})
+
+	_, _, err = LoadAndValidateSpecBundle(bundle)
+	assert.NilError(t, err)
+
+	// a file in a sub directory is the bundle root
+	bundle, err = LoadSpecBundleFromFile(filepath.Join(dir, "schemas", "user.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, bundle.RootFile, "user.yaml")
+	assert.Equal(t, len(bundle.Files), 2)
+}
+
+func TestNewSpecBundle(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string][]byte
+		rootFile     string
+		wantRootFile string
+		wantErr      error
+	}{
+		{
+			name:         "detect root file",
+			files:        newTestSpecBundleFiles(),
+			wantRootFile: "openapi.yaml",
+		},
+		{
+			name: "detect shallowest root file",
+			files: map[string][]byte{
+				"api/openapi.yaml":          []byte(testBundleRootSpec),
+				"api/other/openapi.yaml":    []byte(testBundleRootSpec),
+				"api/schemas/address.yaml":  []byte(testBundleAddressSchema),
+				"api/parameters/param.yaml": []byte(testBundleParameters),
+			},
+			wantRootFile: "api/openapi.yaml",
+		},
+		{
+			name:         "given root file is cleaned",
+			files:        map[string][]byte{"./api/openapi.yaml": []byte(testBundleRootSpec)},
+			rootFile:     "api/./openapi.yaml",
+			wantRootFile: "api/openapi.yaml",
+		},
+		{
+			name:     "given root file not found",
+			files:    newTestSpecBundleFiles(),
+			rootFile: "swagger.yaml",
+			wantErr:  ErrSpecBundleFileNotFound,
+		},
+		{
+			name:    "no root file",
+			files:   map[string][]byte{"address.yaml": []byte(testBundleAddressSchema)},
+			wantErr: ErrSpecBundleRootFileNotFound,
+		},
+		{
+			name: "ambiguous root file",
+			files: map[string][]byte{
+				"openapi.yaml": []byte(testBundleRootSpec),
+				"swagger.yaml": []byte(testBundleSwaggerRootSpec),
+			},
+			wantErr: ErrSpecBundleAmbiguousRoot,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := NewSpecBundle(tt.files, tt.rootFile)
+			if tt.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tt.wantErr), "got error: %v", err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, bundle.RootFile, tt.wantRootFile)
+		})
+	}
+}
+
+func createTestZip(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, data := range files {
+		fileWriter, err := writer.Create("spec/" + name)
+		assert.NilError(t, err)
+		_, err = fileWriter.Write(data)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func createTestTarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	writer := tar.NewWriter(gzipWriter)
+	for name, data := range files {
+		assert.NilError(t, writer.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o600,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := writer.Write(data)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, writer.Close())
+	assert.NilError(t, gzipWriter.Close())
+
+	return buf.Bytes()
+}
+
+func TestSpec_LoadProvidedSpec_Archive(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive func(t *testing.T, files map[string][]byte) []byte
+	}{
+		{
+			name:    "zip",
+			archive: createTestZip,
+		},
+		{
+			name:    "tar.gz",
+			archive: createTestTarGz,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+			err := s.LoadProvidedSpec(tt.archive(t, newTestSpecBundleFiles()), map[string]string{"/users/{userId}": "1"})
+			assert.NilError(t, err)
+
+			user := s.ProvidedSpec.Doc.Paths["/users/{userId}"].Get.Responses["200"].Value.Content["application/json"].Schema
+			// refs are cleared from the provided spec
+			assert.Equal(t, user.Value.Properties["error"].Ref, "")
+			assert.Equal(t, user.Value.Properties["address"].Value.Properties["city"].Value.Type, "string")
+			path, pathID, found := s.ProvidedPathTrie.GetPathAndValue("/users/123")
+			assert.Assert(t, found)
+			assert.Equal(t, path, "/users/{userId}")
+			assert.Equal(t, pathID, "1")
+		})
+	}
+}
+
+func Test_readArchiveFiles_MaxSize(t *testing.T) {
+	files := newTestSpecBundleFiles()
+	var size int64
+	for _, data := range files {
+		size += int64(len(data))
+	}
+
+	got, err := readZipFiles(createTestZip(t, files), size)
+	assert.NilError(t, err)
+	assert.Equal(t, len(got), len(files))
+	_, err = readZipFiles(createTestZip(t, files), size-1)
+	assert.Assert(t, errors.Is(err, ErrSpecBundleTooLarge), "got error: %v", err)
+
+	got, err = readTarFiles(createTestTarGz(t, files), size)
+	assert.NilError(t, err)
+	assert.Equal(t, len(got), len(files))
+	_, err = readTarFiles(createTestTarGz(t, files), size-1)
+	assert.Assert(t, errors.Is(err, ErrSpecBundleTooLarge), "got error: %v", err)
+}
+
+const testCircularRefSpec = `openapi: 3.0.3
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /nodes:
+    get:
+      responses:
+        '200':
+          description: node
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Node'
+components:
+  schemas:
+    Node:
+      type: object
+      properties:
+        name:
+          type: string
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+`
+
+func TestSpec_LoadProvidedSpec_CircularRef(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec([]byte(testCircularRefSpec), map[string]string{"/nodes": "1"}))
+
+	node := s.ProvidedSpec.Doc.Paths["/nodes"].Get.Responses["200"].Value.Content["application/json"].Schema.Value
+	assert.Equal(t, node.Properties["name"].Value.Type, "string")
+	assert.Equal(t, node.Properties["children"].Value.Type, "array")
+	// the circular ref is replaced with an empty schema
+	assert.Equal(t, node.Properties["children"].Value.Items.Value.Type, "")
+
+	// the provided spec can be marshaled and encoded
+	_, err := json.Marshal(s.ProvidedSpec.Doc)
+	assert.NilError(t, err)
+	assert.NilError(t, gob.NewEncoder(&bytes.Buffer{}).Encode(s.ProvidedSpec))
+}
@@ -0,0 +1,298 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddedExtensionName marks the paths, operations, parameters and responses that were added to the provided spec
+// by CompleteProvidedSpec.
+const AddedExtensionName = "x-speculator-added"
+
+type ProvidedSpecCompletion struct {
+	// Spec is the proposed provided spec (json), in the provided spec original version.
+	Spec []byte
+	// Comparison of the provided spec (as the original) with the proposed spec (as the modified),
+	// for reviewing the additions before exporting the proposed spec.
+	Comparison *SpecComparison
+}
+
+// CompleteProvidedSpec proposes an updated provided spec with the undocumented (shadow) paths, operations,
+// parameters and response status codes that were observed in the approved and learning specs.
+// Learning spec paths that match an approved path are ignored. Every addition is marked with AddedExtensionName.
+// The proposed spec keeps the provided spec refs and components.
+func (s *Spec) CompleteProvidedSpec() (*ProvidedSpecCompletion, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.HasProvidedSpec() {
+		return nil, ErrNoProvidedSpec
+	}
+
+	doc, err := s.ProvidedSpec.getOriginalDoc()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provided spec: %w", err)
+	}
+
+	observedPathItems, err := s.getObservedPathItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get observed paths: %w", err)
+	}
+
+	provided := &ProvidedSpec{Doc: doc, Servers: s.ProvidedSpec.Servers}
+	securitySchemes := s.getObservedSecuritySchemes()
+	for _, path := range sortedPaths(observedPathItems) {
+		observedPathItem := observedPathItems[path]
+		providedPath, found := s.getProvidedPathTemplate(provided, path)
+		if !found {
+			log.Debugf("Adding undocumented path %v to the provided spec", path)
+			_, pathNoBase := provided.splitBasePath(path)
+			doc.Paths[pathNoBase] = createAddedPathItem(observedPathItem)
+			addSecuritySchemesIfNeeded(doc, observedPathItem, securitySchemes)
+			continue
+		}
+
+		paramNames := getPathParamNamesMapping(path, providedPath)
+		_, providedPathNoBase := provided.splitBasePath(providedPath)
+		providedPathItem := doc.Paths[providedPathNoBase]
+		for method, observedOp := range observedPathItem.Operations() {
+			observedOp = withRenamedPathParams(withPathItemParameters(observedPathItem, observedOp), paramNames)
+			providedOp := providedPathItem.GetOperation(method)
+			if providedOp == nil {
+				log.Debugf("Adding undocumented operation %v %v to the provided spec", method, providedPath)
+				setAdded(&observedOp.ExtensionProps)
+				providedPathItem.SetOperation(method, observedOp)
+				addSecuritySchemesIfNeeded(doc, &oapi_spec.PathItem{Get: observedOp}, securitySchemes)
+				continue
+			}
+			completeOperation(providedPathItem, providedOp, observedOp)
+		}
+	}
+
+	specVersion := s.ProvidedSpec.GetSpecVersion()
+	ret, err := marshalOASJson(doc, specVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	proposedDoc, _, err := LoadAndValidateRawJSONSpec(ret)
+	if err != nil {
+		log.Errorf("Failed to validate the proposed spec. %v\n\nspec: %s", err, ret)
+		return nil, fmt.Errorf("failed to validate the proposed spec. %w", err)
+	}
+
+	originalDoc, err := cloneDoc(s.ProvidedSpec.Doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone provided spec: %w", err)
+	}
+	comparison, err := CompareSpecs(originalDoc, proposedDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare the proposed spec: %w", err)
+	}
+
+	return &ProvidedSpecCompletion{
+		Spec:       ret,
+		Comparison: comparison,
+	}, nil
+}
+
+// getObservedPathItems returns a copy of the approved spec path items and the learning spec path items that
+// do not match an approved path. The learning paths are parameterized as in the suggested review, and the path
+// items of the same parameterized path are merged.
+func (s *Spec) getObservedPathItems() (map[string]*oapi_spec.PathItem, error) {
+	approvedPathItems := make(map[string]*oapi_spec.PathItem)
+	if s.ApprovedSpec != nil {
+		approvedPathItems = s.ApprovedSpec.PathItems
+	}
+	learningPathItems := make(map[string]*oapi_spec.PathItem)
+	if s.LearningSpec != nil {
+		for path, pathItem := range s.LearningSpec.PathItems {
+			if _, _, found := s.ApprovedPathTrie.GetPathAndValue(path); found {
+				continue
+			}
+			learningPathItems[path] = pathItem
+		}
+	}
+
+	ret, err := clonePathItems(approvedPathItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone approved path items: %w", err)
+	}
+	learningPathItems, err = clonePathItems(learningPathItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone learning path items: %w", err)
+	}
+
+	for parametrizedPath, paths := range s.createParametrizedPaths(learningPathItems) {
+		// an approved path item already has the path params
+		mergedPathItem, isApproved := ret[parametrizedPath]
+		if !isApproved {
+			mergedPathItem = &oapi_spec.PathItem{}
+			addPathParamsToPathItem(mergedPathItem, parametrizedPath, paths)
+		}
+		for path := range paths {
+			mergedPathItem = MergePathItems(mergedPathItem, learningPathItems[path])
+		}
+		ret[parametrizedPath] = mergedPathItem
+	}
+
+	return ret, nil
+}
+
+func clonePathItems(pathItems map[string]*oapi_spec.PathItem) (map[string]*oapi_spec.PathItem, error) {
+	pathItemsB, err := json.Marshal(pathItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal path items: %w", err)
+	}
+	ret := make(map[string]*oapi_spec.PathItem)
+	if err := json.Unmarshal(pathItemsB, &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal path items: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (s *Spec) getObservedSecuritySchemes() oapi_spec.SecuritySchemes {
+	ret := oapi_spec.SecuritySchemes{}
+	if s.LearningSpec != nil {
+		for name, scheme := range s.LearningSpec.SecuritySchemes {
+			ret[name] = scheme
+		}
+	}
+	if s.ApprovedSpec != nil {
+		for name, scheme := range s.ApprovedSpec.SecuritySchemes {
+			ret[name] = scheme
+		}
+	}
+
+	return ret
+}
+
+// getProvidedPathTemplate returns the provided spec path template (including the base path) of the observed path.
+// A parameterized path is matched ignoring param names, and a path that was not parameterized (e.g. /pets/rex)
+// is matched by the provided path trie.
+func (s *Spec) getProvidedPathTemplate(provided *ProvidedSpec, path string) (string, bool) {
+	if template, found := provided.GetMatchingPathTemplate(path); found {
+		return template, true
+	}
+
+	basePath, pathNoBase := provided.splitBasePath(path)
+	if providedPath, _, found := s.ProvidedPathTrie.GetPathAndValue(pathNoBase); found {
+		return addBasePathIfNeeded(basePath, providedPath), true
+	}
+
+	return "", false
+}
+
+// completeOperation adds the observed parameters and response status codes that are missing from the provided
+// operation. Path params are documented by the path template, and the default response is generated for every
+// learned operation, so they are not added.
+func completeOperation(providedPathItem *oapi_spec.PathItem, providedOp, observedOp *oapi_spec.Operation) {
+	providedParams := withPathItemParameters(providedPathItem, providedOp).Parameters
+	for _, paramRef := range observedOp.Parameters {
+		param := paramRef.Value
+		if param == nil || param.In == oapi_spec.ParameterInPath || hasParameter(providedParams, param.In, param.Name) {
+			continue
+		}
+		setAdded(&param.ExtensionProps)
+		providedOp.Parameters = append(providedOp.Parameters, &oapi_spec.ParameterRef{Value: param})
+	}
+
+	for code, responseRef := range observedOp.Responses {
+		if _, ok := providedOp.Responses[code]; ok || code == "default" || responseRef.Value == nil {
+			continue
+		}
+		setAdded(&responseRef.Value.ExtensionProps)
+		if providedOp.Responses == nil {
+			providedOp.Responses = oapi_spec.Responses{}
+		}
+		providedOp.Responses[code] = &oapi_spec.ResponseRef{Value: responseRef.Value}
+	}
+}
+
+// hasParameter checks if the parameter is in the parameters, header names are case insensitive.
+func hasParameter(parameters oapi_spec.Parameters, in, name string) bool {
+	for _, paramRef := range parameters {
+		if paramRef.Value == nil || paramRef.Value.In != in {
+			continue
+		}
+		if paramRef.Value.Name == name || (in == oapi_spec.ParameterInHeader && strings.EqualFold(paramRef.Value.Name, name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func createAddedPathItem(pathItem *oapi_spec.PathItem) *oapi_spec.PathItem {
+	setAdded(&pathItem.ExtensionProps)
+	for _, operation := range pathItem.Operations() {
+		setAdded(&operation.ExtensionProps)
+	}
+
+	return pathItem
+}
+
+// addSecuritySchemesIfNeeded adds the security schemes of the path item operations that are missing from the doc.
+func addSecuritySchemesIfNeeded(doc *oapi_spec.T, pathItem *oapi_spec.PathItem, securitySchemes oapi_spec.SecuritySchemes) {
+	for _, operation := range pathItem.Operations() {
+		if operation.Security == nil {
+			continue
+		}
+		for _, requirement := range *operation.Security {
+			for name := range requirement {
+				if _, ok := doc.Components.SecuritySchemes[name]; ok {
+					continue
+				}
+				scheme, ok := securitySchemes[name]
+				if !ok {
+					continue
+				}
+				if doc.Components.SecuritySchemes == nil {
+					doc.Components.SecuritySchemes = oapi_spec.SecuritySchemes{}
+				}
+				doc.Components.SecuritySchemes[name] = scheme
+			}
+		}
+	}
+}
+
+func setAdded(extensionProps *oapi_spec.ExtensionProps) {
+	if extensionProps.Extensions == nil {
+		extensionProps.Extensions = make(map[string]interface{})
+	}
+	extensionProps.Extensions[AddedExtensionName] = true
+}
+
+func cloneDoc(doc *oapi_spec.T) (*oapi_spec.T, error) {
+	docB, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal doc: %w", err)
+	}
+
+	ret := &oapi_spec.T{}
+	if err := json.Unmarshal(docB, ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal doc: %w", err)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,210 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	"gotest.tools/assert"
+)
+
+const testCompletionProvidedSpecV2 = `swagger: '2.0'
+info:
+  title: completion
+  version: 1.0.0
+host: example.com
+basePath: /api
+paths:
+  /pets/{petId}:
+    get:
+      produces:
+        - application/json
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          type: integer
+      responses:
+        '200':
+          description: pet
+`
+
+func isAdded(extensionProps oapi_spec.ExtensionProps) bool {
+	_, ok := extensionProps.Extensions[AddedExtensionName]
+	return ok
+}
+
+func TestSpec_CompleteProvidedSpec(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, nil))
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("1", http.MethodGet, "/api/pets/1?verbose=true", "example.com", "404", "", `{"error": "not found"}`),
+		createTelemetry("2", http.MethodPost, "/api/pets/1", "example.com", "201", `{"name": "a"}`, ""),
+		createTelemetry("3", http.MethodGet, "/api/owners", "example.com", "200", "", `{"name": "a"}`),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+
+	completion, err := s.CompleteProvidedSpec()
+	assert.NilError(t, err)
+
+	doc, oasVersion, err := LoadAndValidateRawJSONSpec(completion.Spec)
+	assert.NilError(t, err)
+	assert.Equal(t, oasVersion, OASv3)
+
+	// undocumented path
+	owners := doc.Paths["/owners"]
+	assert.Assert(t, owners != nil)
+	assert.Assert(t, isAdded(owners.ExtensionProps))
+	assert.Assert(t, isAdded(owners.Get.ExtensionProps))
+
+	// undocumented operation
+	pets := doc.Paths["/pets/{petId}"]
+	assert.Assert(t, !isAdded(pets.ExtensionProps))
+	assert.Assert(t, pets.Post != nil)
+	assert.Assert(t, isAdded(pets.Post.ExtensionProps))
+
+	// undocumented param and status code of a documented operation
+	assert.Assert(t, !isAdded(pets.Get.ExtensionProps))
+	assert.Assert(t, !isAdded(pets.Get.Parameters.GetByInAndName(oapi_spec.ParameterInQuery, "status").ExtensionProps))
+	verbose := pets.Get.Parameters.GetByInAndName(oapi_spec.ParameterInQuery, "verbose")
+	assert.Assert(t, verbose != nil)
+	assert.Assert(t, isAdded(verbose.ExtensionProps))
+	assert.Assert(t, !isAdded(pets.Get.Responses["200"].Value.ExtensionProps))
+	assert.Assert(t, isAdded(pets.Get.Responses["404"].Value.ExtensionProps))
+	// documented operations are kept as is
+	assert.Assert(t, pets.Put != nil)
+
+	var changes []string
+	for _, change := range completion.Comparison.Changes {
+		changes = append(changes, fmt.Sprintf("%v %v %v %v", change.Kind, change.Method, change.Path, change.Location))
+	}
+	assert.DeepEqual(t, changes, []string{
+		"ADDED  /api/owners ",
+		"NEW_PARAM GET /api/pets/{petId} /parameters/query/verbose",
+		"NEW_STATUS_CODE GET /api/pets/{petId} /responses/404",
+		"ADDED POST /api/pets/{petId} ",
+	})
+
+	// the provided spec is not modified
+	assert.Assert(t, s.ProvidedSpec.Doc.Paths["/owners"] == nil)
+	assert.Assert(t, s.ProvidedSpec.Doc.Paths["/pets/{petId}"].Post == nil)
+}
+
+func TestSpec_CompleteProvidedSpec_ApprovedPaths(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, nil))
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("1", http.MethodDelete, "/api/pets/1", "example.com", "204", "", ""),
+		createTelemetry("2", http.MethodDelete, "/api/pets/2", "example.com", "204", "", ""),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+	approveSuggestedReview(t, s)
+	// a learning path that matches an approved path is ignored
+	assert.NilError(t, s.LearnTelemetry(createTelemetry("3", http.MethodDelete, "/api/pets/3", "example.com", "500", "", "")))
+
+	completion, err := s.CompleteProvidedSpec()
+	assert.NilError(t, err)
+
+	doc, _, err := LoadAndValidateRawJSONSpec(completion.Spec)
+	assert.NilError(t, err)
+	assert.Equal(t, len(doc.Paths), 1)
+	deleteOp := doc.Paths["/pets/{petId}"].Delete
+	assert.Assert(t, deleteOp != nil)
+	assert.Assert(t, isAdded(deleteOp.ExtensionProps))
+	// the approved path param is renamed to the provided path param
+	assert.Assert(t, deleteOp.Parameters.GetByInAndName(oapi_spec.ParameterInPath, "petId") != nil)
+	assert.Assert(t, deleteOp.Responses["500"] == nil)
+}
+
+func TestSpec_CompleteProvidedSpec_ParameterizedLearningPaths(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, nil))
+	for _, telemetry := range []*Telemetry{
+		createTelemetry("1", http.MethodGet, "/api/owners/1", "example.com", "200", "", `{"name": "a"}`),
+		createTelemetry("2", http.MethodGet, "/api/owners/2?verbose=true", "example.com", "404", "", ""),
+		createTelemetry("3", http.MethodDelete, "/api/owners/3", "example.com", "204", "", ""),
+	} {
+		assert.NilError(t, s.LearnTelemetry(telemetry))
+	}
+
+	completion, err := s.CompleteProvidedSpec()
+	assert.NilError(t, err)
+
+	doc, _, err := LoadAndValidateRawJSONSpec(completion.Spec)
+	assert.NilError(t, err)
+	// the concrete learning paths are added as a single parameterized path
+	assert.Assert(t, doc.Paths["/owners/1"] == nil)
+	owner := doc.Paths["/owners/{ownerId}"]
+	assert.Assert(t, owner != nil)
+	assert.Assert(t, isAdded(owner.ExtensionProps))
+	assert.Assert(t, owner.Delete != nil)
+	// the operations of the learning paths are merged
+	assert.Assert(t, owner.Get.Responses["200"] != nil)
+	assert.Assert(t, owner.Get.Responses["404"] != nil)
+	assert.Assert(t, owner.Get.Parameters.GetByInAndName(oapi_spec.ParameterInQuery, "verbose") != nil)
+	assert.Assert(t, owner.Parameters.GetByInAndName(oapi_spec.ParameterInPath, "ownerId") != nil)
+
+	var changes []string
+	for _, change := range completion.Comparison.Changes {
+		changes = append(changes, fmt.Sprintf("%v %v", change.Kind, change.Path))
+	}
+	assert.DeepEqual(t, changes, []string{"ADDED /api/owners/{ownerId}"})
+}
+
+func TestSpec_CompleteProvidedSpec_OriginalVersion(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec([]byte(testCompletionProvidedSpecV2), nil))
+	assert.NilError(t, s.LearnTelemetry(createTelemetry("1", http.MethodGet, "/api/pets/1", "example.com", "404", "", "")))
+
+	completion, err := s.CompleteProvidedSpec()
+	assert.NilError(t, err)
+
+	doc, oasVersion, err := LoadAndValidateRawJSONSpec(completion.Spec)
+	assert.NilError(t, err)
+	assert.Equal(t, oasVersion, OASv2)
+	assert.Assert(t, doc.Paths["/pets/{petId}"].Get.Responses["404"] != nil)
+	assert.Equal(t, len(completion.Comparison.Changes), 1)
+}
+
+func TestSpec_CompleteProvidedSpec_NoProvidedSpec(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+
+	_, err := s.CompleteProvidedSpec()
+	assert.Assert(t, errors.Is(err, ErrNoProvidedSpec))
+}
+
+func TestSpec_CompleteProvidedSpec_KeepsRefs(t *testing.T) {
+	s := CreateDefaultSpec("example.com", "443", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(adoptTestProvidedSpec, nil))
+	assert.NilError(t, s.LearnTelemetry(createTelemetry("1", http.MethodGet, "/api/pets/1", "example.com", "404", "", `{"error": "not found"}`)))
+
+	completion, err := s.CompleteProvidedSpec()
+	assert.NilError(t, err)
+
+	doc, _, err := LoadAndValidateRawJSONSpec(completion.Spec)
+	assert.NilError(t, err)
+	assert.Assert(t, doc.Components.Schemas["Pet"] != nil)
+	get := doc.Paths["/pets/{petId}"].Get
+	assert.Equal(t, get.Responses["200"].Value.Content["application/json"].Schema.Ref, "#/components/schemas/Pet")
+	assert.Assert(t, !isAdded(get.Responses["200"].Value.ExtensionProps))
+	assert.Assert(t, isAdded(get.Responses["404"].Value.ExtensionProps))
+}
@@ -18,6 +18,7 @@ package spec
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -298,3 +299,19 @@ func TestSpec_SpecInfoClone(t *testing.T) {
 		})
 	}
 }
+
+// approveSuggestedReview approves the suggested review of the learned telemetries.
+func approveSuggestedReview(t *testing.T, s *Spec) {
+	t.Helper()
+
+	approvedReview := &ApprovedSpecReview{PathToPathItem: s.LearningSpec.PathItems}
+	for i, item := range s.CreateSuggestedReview().PathItemsReview {
+		approvedReview.PathItemsReview = append(approvedReview.PathItemsReview, &ApprovedSpecReviewPathItem{
+			ReviewPathItem: item.ReviewPathItem,
+			PathUUID:       fmt.Sprint(i),
+		})
+	}
+	if err := s.ApplyApprovedReview(approvedReview, OASv3); err != nil {
+		t.Fatalf("ApplyApprovedReview() error = %v", err)
+	}
+}
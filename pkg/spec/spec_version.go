@@ -26,6 +26,7 @@ const (
 	Unknown OASVersion = iota
 	OASv2
 	OASv3
+	OASv31
 )
 
 func (o OASVersion) String() string {
@@ -36,6 +37,8 @@ func (o OASVersion) String() string {
 		return "OASv2"
 	case OASv3:
 		return "OASv3"
+	case OASv31:
+		return "OASv3.1"
 	}
 	return "Unknown"
 }
@@ -61,6 +64,9 @@ func GetJSONSpecVersion(jsonSpec []byte) (OASVersion, error) {
 
 	// openapi field is required in the OpenAPI Specification
 	if v3header.OpenAPI != nil && *v3header.OpenAPI != "" {
+		if isOpenAPIVersion31(*v3header.OpenAPI) {
+			return OASv31, nil
+		}
 		return OASv3, nil
 	}
 
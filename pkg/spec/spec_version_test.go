@@ -56,6 +56,14 @@ func TestGetJSONSpecVersion(t *testing.T) {
 			want:    OASv3,
 			wantErr: false,
 		},
+		{
+			name: "valid v3.1 spec",
+			args: args{
+				jsonSpec: []byte(`{"openapi": "3.1.0", "info": {"title": "Simple API", "version": "1.0.0"}}`),
+			},
+			want:    OASv31,
+			wantErr: false,
+		},
 		{
 			name: "invalid v3 spec",
 			args: args{
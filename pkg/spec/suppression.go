@@ -0,0 +1,219 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+type SuppressionAction string
+
+const (
+	// SuppressionActionSuppress removes the matched changes, or the whole diff, from the diff.
+	SuppressionActionSuppress SuppressionAction = "SUPPRESS"
+	// SuppressionActionDowngrade sets the severity of the matched changes, or the whole diff (including a diff past
+	// the operation sunset date), to non breaking.
+	SuppressionActionDowngrade SuppressionAction = "DOWNGRADE"
+)
+
+type SuppressionRules struct {
+	Rules []SuppressionRule `json:"rules"`
+}
+
+// SuppressionRule matches diffs by all of its set fields.
+// A rule that sets Parameter, JSONPointer or ChangeKind matches the diff changes, otherwise it matches the whole diff
+// (including shadow diffs that have no changes).
+type SuppressionRule struct {
+	Name string `json:"name,omitempty"`
+	// SpecKey (host:port) the rule is scoped to, the rule is global if not set.
+	// The spec key is matched by the speculator, and ignored by the spec.
+	SpecKey string `json:"specKey,omitempty"`
+	// Path template pattern of the diff, e.g. /health or /api/*/debug (see path.Match).
+	Path   string `json:"path,omitempty"`
+	Method string `json:"method,omitempty"`
+	// Parameter or header name (case insensitive), e.g. X-B3-TraceId.
+	Parameter string `json:"parameter,omitempty"`
+	// JSONPointer relative to the operation, matches the change location and everything under it,
+	// e.g. /responses/200/content/application~1json/schema/properties/debug
+	JSONPointer string            `json:"jsonPointer,omitempty"`
+	ChangeKind  DiffChangeKind    `json:"changeKind,omitempty"`
+	Action      SuppressionAction `json:"action"`
+}
+
+// WithSuppressionRules suppresses or downgrades the diffs matched by the rules.
+func WithSuppressionRules(rules []SuppressionRule) DiffOption {
+	return func(params *DiffParams) {
+		params.suppressionRules = rules
+	}
+}
+
+// LoadSuppressionRules loads and validates suppression rules from YAML (or JSON).
+func LoadSuppressionRules(data []byte) ([]SuppressionRule, error) {
+	rules := &SuppressionRules{}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal suppression rules: %w", err)
+	}
+
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		rule.Action = SuppressionAction(strings.ToUpper(string(rule.Action)))
+		rule.Method = strings.ToUpper(rule.Method)
+		rule.ChangeKind = DiffChangeKind(strings.ToUpper(string(rule.ChangeKind)))
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid suppression rule %v %q: %w", i, rule.Name, err)
+		}
+	}
+
+	return rules.Rules, nil
+}
+
+func (r *SuppressionRule) validate() error {
+	switch r.Action {
+	case SuppressionActionSuppress, SuppressionActionDowngrade:
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+
+	if _, err := path.Match(r.Path, ""); err != nil {
+		return fmt.Errorf("invalid path pattern %q: %w", r.Path, err)
+	}
+
+	if r.JSONPointer != "" && !strings.HasPrefix(r.JSONPointer, "/") {
+		return fmt.Errorf("invalid JSON pointer %q, must start with /", r.JSONPointer)
+	}
+
+	return nil
+}
+
+func (r *SuppressionRule) isChangeRule() bool {
+	return r.Parameter != "" || r.JSONPointer != "" || r.ChangeKind != ""
+}
+
+func (r *SuppressionRule) matchDiff(apiDiff *APIDiff, method string) bool {
+	if r.Method != "" && r.Method != strings.ToUpper(method) {
+		return false
+	}
+	if r.Path != "" {
+		if matched, _ := path.Match(r.Path, apiDiff.Path); !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *SuppressionRule) matchChange(change DiffChange) bool {
+	if r.ChangeKind != "" && r.ChangeKind != change.Kind {
+		return false
+	}
+	if r.JSONPointer != "" && !change.Location.hasPrefix(parseLocation(r.JSONPointer)) {
+		return false
+	}
+	if r.Parameter != "" && !strings.EqualFold(r.Parameter, getLocationParameterName(change.Location)) {
+		return false
+	}
+
+	return true
+}
+
+// applySuppressionRules returns the diff with the rules applied, a diff that all its changes were suppressed will be
+// returned as no diff.
+func (s *Spec) applySuppressionRules(apiDiff *APIDiff, method string, rules []SuppressionRule) *APIDiff {
+	if apiDiff == nil || apiDiff.Type == DiffTypeNoDiff {
+		return apiDiff
+	}
+
+	hadChanges := len(apiDiff.Changes) > 0
+	downgraded := false
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matchDiff(apiDiff, method) {
+			continue
+		}
+		if !rule.isChangeRule() {
+			if rule.Action == SuppressionActionSuppress {
+				return s.createSuppressedAPIDiff(apiDiff)
+			}
+			apiDiff.Changes = downgradeChanges(apiDiff.Changes, func(DiffChange) bool { return true })
+			downgraded = true
+			continue
+		}
+
+		if rule.Action == SuppressionActionSuppress {
+			var changes []DiffChange
+			for _, change := range apiDiff.Changes {
+				if !rule.matchChange(change) {
+					changes = append(changes, change)
+				}
+			}
+			apiDiff.Changes = changes
+		} else {
+			apiDiff.Changes = downgradeChanges(apiDiff.Changes, rule.matchChange)
+		}
+	}
+
+	if hadChanges && len(apiDiff.Changes) == 0 {
+		return s.createSuppressedAPIDiff(apiDiff)
+	}
+	switch {
+	case apiDiff.Severity == "":
+	case downgraded:
+		apiDiff.Severity = DiffSeverityNonBreaking
+	default:
+		apiDiff.Severity = getAPIDiffSeverity(apiDiff)
+	}
+
+	return apiDiff
+}
+
+// createSuppressedAPIDiff returns no diff, keeping the diff validation findings.
+func (s *Spec) createSuppressedAPIDiff(apiDiff *APIDiff) *APIDiff {
+	ret := s.createAPIDiffEvent(DiffTypeNoDiff, nil, nil, apiDiff.InteractionID, apiDiff.Path)
+	ret.ValidationFindings = apiDiff.ValidationFindings
+	ret.Server = apiDiff.Server
+
+	return ret
+}
+
+func downgradeChanges(changes []DiffChange, match func(DiffChange) bool) []DiffChange {
+	for i := range changes {
+		if match(changes[i]) {
+			changes[i].ConsumerSeverity = DiffSeverityNonBreaking
+			changes[i].ProviderSeverity = DiffSeverityNonBreaking
+		}
+	}
+
+	return changes
+}
+
+// getLocationParameterName returns the parameter or header name of a change location,
+// e.g. /parameters/header/X-Request-ID or /responses/200/headers/X-Rate-Limit, and empty string otherwise.
+func getLocationParameterName(location Location) string {
+	for i, segment := range location {
+		switch {
+		case segment == locationParameters && i == 0 && len(location) > 2:
+			return location[2]
+		case segment == locationHeaders && i > 0 && location[i-1] != locationProperties && len(location) > i+1:
+			return location[i+1]
+		}
+	}
+
+	return ""
+}
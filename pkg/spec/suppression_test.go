@@ -0,0 +1,253 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLoadSuppressionRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []SuppressionRule
+		wantErr bool
+	}{
+		{
+			name: "valid rules",
+			data: `
+rules:
+- name: tracing headers
+  parameter: X-B3-TraceId
+  action: suppress
+- specKey: host:8080
+  path: /health
+  method: get
+  action: SUPPRESS
+- jsonPointer: /responses/200/content/application~1json/schema/properties/debug
+  changeKind: added
+  action: downgrade
+`,
+			want: []SuppressionRule{
+				{Name: "tracing headers", Parameter: "X-B3-TraceId", Action: SuppressionActionSuppress},
+				{SpecKey: "host:8080", Path: "/health", Method: http.MethodGet, Action: SuppressionActionSuppress},
+				{
+					JSONPointer: "/responses/200/content/application~1json/schema/properties/debug",
+					ChangeKind:  DiffChangeKindAdded, Action: SuppressionActionDowngrade,
+				},
+			},
+		},
+		{
+			name:    "unknown action",
+			data:    "rules:\n- path: /health\n  action: ignore\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing action",
+			data:    "rules:\n- path: /health\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid path pattern",
+			data:    "rules:\n- path: /health[\n  action: suppress\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid json pointer",
+			data:    "rules:\n- jsonPointer: responses/200\n  action: suppress\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadSuppressionRules([]byte(tt.data))
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestSpec_applySuppressionRules(t *testing.T) {
+	headerChange := DiffChange{
+		Location:         Location{"parameters", "header", "X-B3-TraceId"},
+		Kind:             DiffChangeKindAdded,
+		ConsumerSeverity: DiffSeverityNonBreaking,
+		ProviderSeverity: DiffSeverityNonBreaking,
+	}
+	responseHeaderChange := DiffChange{
+		Location:         Location{"responses", "200", "headers", "x-b3-traceid"},
+		Kind:             DiffChangeKindRemoved,
+		ConsumerSeverity: DiffSeverityBreaking,
+		ProviderSeverity: DiffSeverityNonBreaking,
+	}
+	propertyChange := DiffChange{
+		Location:         Location{"responses", "200", "content", "application/json", "schema", "properties", "debug", "type"},
+		Kind:             DiffChangeKindTypeChanged,
+		ConsumerSeverity: DiffSeverityBreaking,
+		ProviderSeverity: DiffSeverityNonBreaking,
+	}
+	downgradedPropertyChange := propertyChange
+	downgradedPropertyChange.ConsumerSeverity = DiffSeverityNonBreaking
+
+	tests := []struct {
+		name    string
+		apiDiff *APIDiff
+		rules   []SuppressionRule
+		want    *APIDiff
+	}{
+		{
+			name:    "no rules",
+			apiDiff: &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{propertyChange}, Severity: DiffSeverityBreaking},
+			want:    &APIDiff{Type: DiffTypeGeneralDiff, Path: "/pets", Changes: []DiffChange{propertyChange}, Severity: DiffSeverityBreaking},
+		},
+		{
+			name:    "suppress shadow diff by path",
+			apiDiff: &APIDiff{Type: DiffTypeShadowDiff, Path: "/health"},
+			rules:   []SuppressionRule{{Path: "/health", Action: SuppressionActionSuppress}},
+			want:    &APIDiff{Type: DiffTypeNoDiff, Path: "/health"},
+		},
+		{
+			name:    "path pattern and method not matched",
+			apiDiff: &APIDiff{Type: DiffTypeShadowDiff, Path: "/api/pets/debug"},
+			rules: []SuppressionRule{
+				{Path: "/api/*/debug", Method: http.MethodPost, Action: SuppressionActionSuppress},
+				{Path: "/*/debug", Action: SuppressionActionSuppress},
+			},
+			want: &APIDiff{Type: DiffTypeShadowDiff, Path: "/api/pets/debug"},
+		},
+		{
+			name:    "path pattern and method matched",
+			apiDiff: &APIDiff{Type: DiffTypeShadowDiff, Path: "/api/pets/debug"},
+			rules:   []SuppressionRule{{Path: "/api/*/debug", Method: http.MethodGet, Action: SuppressionActionSuppress}},
+			want:    &APIDiff{Type: DiffTypeNoDiff, Path: "/api/pets/debug"},
+		},
+		{
+			name: "suppress header changes by name",
+			apiDiff: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityBreaking,
+				Changes: []DiffChange{headerChange, propertyChange, responseHeaderChange},
+			},
+			rules: []SuppressionRule{{Parameter: "x-b3-traceid", Action: SuppressionActionSuppress}},
+			want: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityBreaking,
+				Changes: []DiffChange{propertyChange},
+			},
+		},
+		{
+			name: "all changes suppressed",
+			apiDiff: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityNonBreaking,
+				Changes: []DiffChange{headerChange},
+			},
+			rules: []SuppressionRule{{Parameter: "X-B3-TraceId", ChangeKind: DiffChangeKindAdded, Action: SuppressionActionSuppress}},
+			want:  &APIDiff{Type: DiffTypeNoDiff, Path: "/pets"},
+		},
+		{
+			name: "downgrade changes by json pointer",
+			apiDiff: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityBreaking,
+				Changes: []DiffChange{headerChange, propertyChange},
+			},
+			rules: []SuppressionRule{{
+				JSONPointer: "/responses/200/content/application~1json/schema/properties/debug",
+				Action:      SuppressionActionDowngrade,
+			}},
+			want: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityNonBreaking,
+				Changes: []DiffChange{headerChange, downgradedPropertyChange},
+			},
+		},
+		{
+			name: "json pointer prefix of a location segment is not matched",
+			apiDiff: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityBreaking,
+				Changes: []DiffChange{propertyChange},
+			},
+			rules: []SuppressionRule{{
+				JSONPointer: "/responses/200/content/application~1json/schema/properties/deb",
+				Action:      SuppressionActionSuppress,
+			}},
+			want: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityBreaking,
+				Changes: []DiffChange{propertyChange},
+			},
+		},
+		{
+			name: "downgrade whole diff",
+			apiDiff: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityBreaking,
+				Changes: []DiffChange{propertyChange},
+			},
+			rules: []SuppressionRule{{Path: "/pets", Action: SuppressionActionDowngrade}},
+			want: &APIDiff{
+				Type: DiffTypeGeneralDiff, Path: "/pets", Severity: DiffSeverityNonBreaking,
+				Changes: []DiffChange{downgradedPropertyChange},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Spec{}
+			got := s.applySuppressionRules(tt.apiDiff, http.MethodGet, tt.rules)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestSpec_DiffTelemetry_SuppressionRules(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{"/pets/{petId}": "1"}))
+	telemetry := createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"name": 5}`)
+
+	apiDiff, err := s.DiffTelemetry(telemetry, SpecSourceProvided, WithDiffMode(DiffModeConformance))
+	assert.NilError(t, err)
+	assert.Equal(t, apiDiff.Type, DiffTypeGeneralDiff)
+
+	apiDiff, err = s.DiffTelemetry(telemetry, SpecSourceProvided, WithDiffMode(DiffModeConformance), WithSuppressionRules([]SuppressionRule{{
+		Path:        "/api/pets/{petId}",
+		JSONPointer: "/responses/200/content/application~1json/schema/properties/name",
+		Action:      SuppressionActionSuppress,
+	}}))
+	assert.NilError(t, err)
+	assert.Equal(t, apiDiff.Type, DiffTypeNoDiff)
+}
+
+func Test_getLocationParameterName(t *testing.T) {
+	tests := []struct {
+		location Location
+		want     string
+	}{
+		{location: Location{"parameters", "header", "X-Request-ID"}, want: "X-Request-ID"},
+		{location: Location{"parameters", "query", "filter.name"}, want: "filter.name"},
+		{location: Location{"parameters", "query", "limit", "schema", "type"}, want: "limit"},
+		{location: Location{"parameters"}, want: ""},
+		{location: Location{"responses", "200", "headers", "X-Rate-Limit"}, want: "X-Rate-Limit"},
+		{location: Location{"responses", "200", "content", "application/json", "schema", "properties", "headers"}, want: ""},
+		{location: Location{"requestBody", "content", "application/json", "schema", "properties", "headers", "type"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location.String(), func(t *testing.T) {
+			assert.Equal(t, getLocationParameterName(tt.location), tt.want)
+		})
+	}
+}
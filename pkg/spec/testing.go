@@ -116,6 +116,13 @@ func (op *TestOperation) Deprecated() *TestOperation {
 	return op
 }
 
+func (op *TestOperation) WithOperationInfo(operationID, summary string, tags ...string) *TestOperation {
+	op.Op.OperationID = operationID
+	op.Op.Summary = summary
+	op.Op.Tags = tags
+	return op
+}
+
 func (op *TestOperation) WithResponse(status int, response *oapi_spec.Response) *TestOperation {
 	op.Op.AddResponse(status, response)
 	if status != 0 {
@@ -0,0 +1,296 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	oapi_spec "github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+
+	"github.com/openclarity/speculator/pkg/utils"
+)
+
+type ValidationFindingKind string
+
+const (
+	ValidationFindingKindParameter    ValidationFindingKind = "PARAMETER"
+	ValidationFindingKindRequestBody  ValidationFindingKind = "REQUEST_BODY"
+	ValidationFindingKindResponseBody ValidationFindingKind = "RESPONSE_BODY"
+	ValidationFindingKindContentType  ValidationFindingKind = "CONTENT_TYPE"
+	ValidationFindingKindStatusCode   ValidationFindingKind = "STATUS_CODE"
+)
+
+type ValidationFinding struct {
+	Kind ValidationFindingKind
+	// Location of the invalid value (same as DiffChange.Location).
+	// e.g. parameters, query, limit or requestBody, items, 0, name or responses, 200, id
+	Location Location
+	// SchemaField is the schema keyword that failed (e.g. enum, pattern, maximum, required), empty if the
+	// value could not be validated against a schema (e.g. unexpected content type).
+	SchemaField string
+	Value       interface{}
+	Message     string
+}
+
+// ValidateTelemetry validates the telemetry request and response values against the matched provided spec operation.
+// Returns nil if there is no provided spec or the telemetry does not match any provided spec operation.
+func (s *Spec) ValidateTelemetry(telemetry *Telemetry) ([]ValidationFinding, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.HasProvidedSpec() {
+		return nil, nil
+	}
+
+	return s.validateTelemetry(telemetry)
+}
+
+func (s *Spec) validateTelemetry(telemetry *Telemetry) ([]ValidationFinding, error) {
+	path, _ := GetPathAndQuery(telemetry.Request.Path)
+	_, pathNoBase := s.ProvidedSpec.splitBasePath(path)
+
+	pathFromTrie, _, found := s.ProvidedPathTrie.GetPathAndValue(pathNoBase)
+	if !found {
+		return nil, nil
+	}
+	pathItem := s.ProvidedSpec.GetPathItem(pathFromTrie)
+	if pathItem == nil {
+		return nil, nil
+	}
+	operation := GetOperationFromPathItem(pathItem, telemetry.Request.Method)
+	if operation == nil {
+		return nil, nil
+	}
+
+	req, err := createHTTPRequestFromTelemetry(telemetry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+	}
+	if telemetry.Request.Common != nil && telemetry.Request.Common.TruncatedBody {
+		options.ExcludeRequestBody = true
+	}
+	if telemetry.Response != nil && telemetry.Response.Common != nil && telemetry.Response.Common.TruncatedBody {
+		options.ExcludeResponseBody = true
+	}
+
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: getPathParams(pathFromTrie, pathNoBase),
+		Route: &routers.Route{
+			Spec:      s.ProvidedSpec.Doc,
+			Path:      pathFromTrie,
+			PathItem:  pathItem,
+			Method:    req.Method,
+			Operation: operation,
+		},
+		Options: options,
+	}
+
+	var findings []ValidationFinding
+	if err := openapi3filter.ValidateRequest(context.Background(), requestInput); err != nil {
+		findings = append(findings, createValidationFindings("", nil, err)...)
+	}
+
+	if telemetry.Response != nil {
+		statusCode, err := strconv.Atoi(telemetry.Response.StatusCode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q: %w", telemetry.Response.StatusCode, err)
+		}
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 statusCode,
+			Header:                 createHTTPHeader(telemetry.Response.Common),
+			Options:                options,
+		}
+		responseInput.SetBodyBytes(getBody(telemetry.Response.Common))
+		if err := openapi3filter.ValidateResponse(context.Background(), responseInput); err != nil {
+			location := Location{locationResponses, telemetry.Response.StatusCode}
+			findings = append(findings, createValidationFindings(ValidationFindingKindResponseBody, location, err)...)
+		}
+	}
+
+	// request findings (parameters, requestBody) will be sorted before the response findings
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Location.less(findings[j].Location)
+	})
+
+	return findings, nil
+}
+
+func createHTTPRequestFromTelemetry(telemetry *Telemetry) (*http.Request, error) {
+	req, err := http.NewRequest(telemetry.Request.Method, telemetry.Request.Path, bytes.NewReader(getBody(telemetry.Request.Common)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Host = telemetry.Request.Host
+	req.Header = createHTTPHeader(telemetry.Request.Common)
+
+	return req, nil
+}
+
+func createHTTPHeader(common *Common) http.Header {
+	header := make(http.Header)
+	if common == nil {
+		return header
+	}
+	for _, h := range common.Headers {
+		header.Add(h.Key, h.Value)
+	}
+
+	return header
+}
+
+func getBody(common *Common) []byte {
+	if common == nil {
+		return nil
+	}
+
+	return common.Body
+}
+
+// getPathParams returns the values of the template params in path, e.g. for template /users/{id} and
+// path /users/1 will return {id: 1}.
+func getPathParams(template, path string) map[string]string {
+	pathParams := make(map[string]string)
+
+	templateSegments := strings.Split(template, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(templateSegments) != len(pathSegments) {
+		return pathParams
+	}
+
+	for i, templateSegment := range templateSegments {
+		if !utils.HasPathParam(templateSegment) {
+			continue
+		}
+		values, ok := utils.MatchPathSegmentTemplate(templateSegment, pathSegments[i])
+		if !ok {
+			continue
+		}
+		for j, name := range getPathParamNames(templateSegment) {
+			pathParams[name] = values[j]
+		}
+	}
+
+	return pathParams
+}
+
+// createValidationFindings flattens the openapi3filter validation error into findings.
+// kind and location are inherited by nested schema errors.
+func createValidationFindings(kind ValidationFindingKind, location Location, err error) []ValidationFinding {
+	switch e := err.(type) {
+	case oapi_spec.MultiError:
+		var findings []ValidationFinding
+		for _, nestedErr := range e {
+			findings = append(findings, createValidationFindings(kind, location, nestedErr)...)
+		}
+		return findings
+	case *openapi3filter.RequestError:
+		kind, location = getRequestErrorKindAndLocation(e)
+		if e.Err == nil {
+			return []ValidationFinding{{Kind: kind, Location: location, Message: e.Error()}}
+		}
+		return createCauseValidationFindings(kind, location, e.Err, e.Error())
+	case *openapi3filter.ResponseError:
+		if e.Err == nil {
+			return []ValidationFinding{{Kind: getResponseErrorKind(e), Location: location, Message: e.Error()}}
+		}
+		return createCauseValidationFindings(kind, location, e.Err, e.Error())
+	case *oapi_spec.SchemaError:
+		return []ValidationFinding{
+			{
+				Kind:        kind,
+				Location:    appendLocation(location, e.JSONPointer()...),
+				SchemaField: e.SchemaField,
+				Value:       e.Value,
+				Message:     e.Reason,
+			},
+		}
+	default:
+		return []ValidationFinding{{Kind: kind, Location: location, Message: err.Error()}}
+	}
+}
+
+// createCauseValidationFindings returns the findings of the error cause. If the cause is not a schema
+// validation error (e.g. a required value is missing, or the value could not be decoded), a single finding
+// with the given message is returned.
+func createCauseValidationFindings(kind ValidationFindingKind, location Location, cause error, message string) []ValidationFinding {
+	switch cause.(type) {
+	case oapi_spec.MultiError, *oapi_spec.SchemaError:
+		return createValidationFindings(kind, location, cause)
+	default:
+		return []ValidationFinding{{Kind: kind, Location: location, Message: message}}
+	}
+}
+
+func getRequestErrorKindAndLocation(err *openapi3filter.RequestError) (ValidationFindingKind, Location) {
+	switch {
+	case err.Parameter != nil:
+		return ValidationFindingKindParameter, Location{locationParameters, err.Parameter.In, err.Parameter.Name}
+	case isUnexpectedRequestContentType(err):
+		return ValidationFindingKindContentType, Location{locationRequestBody}
+	default:
+		return ValidationFindingKindRequestBody, Location{locationRequestBody}
+	}
+}
+
+// isUnexpectedRequestContentType checks if the request body error is of a content type that the
+// request body doesn't declare. openapi3filter reports it without a cause error.
+func isUnexpectedRequestContentType(err *openapi3filter.RequestError) bool {
+	if err.RequestBody == nil || err.Err != nil || err.Input == nil || err.Input.Request == nil {
+		return false
+	}
+
+	return err.RequestBody.Content.Get(err.Input.Request.Header.Get(contentTypeHeaderName)) == nil
+}
+
+// getResponseErrorKind returns the kind of a response error without a cause error, which openapi3filter
+// reports for a status code that the operation doesn't declare, or a content type that the response doesn't declare.
+func getResponseErrorKind(err *openapi3filter.ResponseError) ValidationFindingKind {
+	input := err.Input
+	if input == nil || input.RequestValidationInput == nil || input.RequestValidationInput.Route == nil ||
+		input.RequestValidationInput.Route.Operation == nil {
+		return ValidationFindingKindResponseBody
+	}
+
+	responses := input.RequestValidationInput.Route.Operation.Responses
+	responseRef := responses.Get(input.Status)
+	if responseRef == nil {
+		responseRef = responses.Default()
+	}
+	switch {
+	case responseRef == nil:
+		return ValidationFindingKindStatusCode
+	case responseRef.Value != nil && responseRef.Value.Content.Get(input.Header.Get(contentTypeHeaderName)) == nil:
+		return ValidationFindingKindContentType
+	default:
+		return ValidationFindingKindResponseBody
+	}
+}
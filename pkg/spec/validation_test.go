@@ -0,0 +1,234 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+var validationTestProvidedSpec = []byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "validation", "version": "1.0.0"},
+  "servers": [{"url": "https://example.com/api"}],
+  "paths": {
+    "/pets/{petId}": {
+      "parameters": [
+        {"name": "petId", "in": "path", "required": true, "schema": {"type": "integer", "minimum": 1}}
+      ],
+      "get": {
+        "parameters": [
+          {"name": "status", "in": "query", "schema": {"type": "string", "enum": ["available", "sold"]}}
+        ],
+        "responses": {
+          "200": {
+            "description": "pet",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "required": ["name"],
+                  "properties": {
+                    "name": {"type": "string", "pattern": "^[a-z]+$"},
+                    "age": {"type": "integer", "maximum": 30}
+                  }
+                }
+              }
+            }
+          }
+        }
+      },
+      "put": {
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "required": ["name"],
+                "properties": {"name": {"type": "string", "minLength": 2}}
+              }
+            }
+          }
+        },
+        "responses": {"204": {"description": "updated"}}
+      }
+    }
+  }
+}`)
+
+func TestSpec_ValidateTelemetry(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{"/pets/{petId}": "1"}))
+
+	tests := []struct {
+		name      string
+		telemetry *Telemetry
+		want      []ValidationFinding
+	}{
+		{
+			name:      "valid",
+			telemetry: createTelemetry("1", http.MethodGet, "/api/pets/1?status=sold", "host", "200", "", `{"name": "rex", "age": 3}`),
+			want:      nil,
+		},
+		{
+			name:      "path not in spec",
+			telemetry: createTelemetry("1", http.MethodGet, "/api/owners/1", "host", "200", "", ""),
+			want:      nil,
+		},
+		{
+			name:      "path param minimum and query param enum",
+			telemetry: createTelemetry("1", http.MethodGet, "/api/pets/0?status=lost", "host", "200", "", `{"name": "rex"}`),
+			want: []ValidationFinding{
+				{
+					Kind:        ValidationFindingKindParameter,
+					Location:    Location{"parameters", "path", "petId"},
+					SchemaField: "minimum",
+					Value:       float64(0),
+					Message:     "number must be at least 1",
+				},
+				{
+					Kind:        ValidationFindingKindParameter,
+					Location:    Location{"parameters", "query", "status"},
+					SchemaField: "enum",
+					Value:       "lost",
+					Message:     "value is not one of the allowed values",
+				},
+			},
+		},
+		{
+			name:      "response maximum and required",
+			telemetry: createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"age": 31}`),
+			want: []ValidationFinding{
+				{
+					Kind:        ValidationFindingKindResponseBody,
+					Location:    Location{"responses", "200", "age"},
+					SchemaField: "maximum",
+					Value:       float64(31),
+					Message:     "number must be at most 30",
+				},
+				{
+					Kind:        ValidationFindingKindResponseBody,
+					Location:    Location{"responses", "200", "name"},
+					SchemaField: "required",
+					Value:       map[string]interface{}{"age": float64(31)},
+					Message:     "property \"name\" is missing",
+				},
+			},
+		},
+		{
+			name:      "response pattern",
+			telemetry: createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"name": "Rex"}`),
+			want: []ValidationFinding{
+				{
+					Kind:        ValidationFindingKindResponseBody,
+					Location:    Location{"responses", "200", "name"},
+					SchemaField: "pattern",
+					Value:       "Rex",
+					Message:     "string doesn't match the regular expression \"^[a-z]+$\"",
+				},
+			},
+		},
+		{
+			name:      "undeclared status code",
+			telemetry: createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "500", "", ""),
+			want: []ValidationFinding{
+				{
+					Kind:     ValidationFindingKindStatusCode,
+					Location: Location{"responses", "500"},
+					Message:  "status is not supported",
+				},
+			},
+		},
+		{
+			name:      "request body min length",
+			telemetry: createTelemetry("1", http.MethodPut, "/api/pets/1", "host", "204", `{"name": "a"}`, ""),
+			want: []ValidationFinding{
+				{
+					Kind:        ValidationFindingKindRequestBody,
+					Location:    Location{"requestBody", "name"},
+					SchemaField: "minLength",
+					Value:       "a",
+					Message:     "minimum string length is 2",
+				},
+			},
+		},
+		{
+			name: "request content type",
+			telemetry: func() *Telemetry {
+				telemetry := createTelemetry("1", http.MethodPut, "/api/pets/1", "host", "204", `name=rex`, "")
+				telemetry.Request.Common.Headers[0].Value = "text/plain"
+				return telemetry
+			}(),
+			want: []ValidationFinding{
+				{
+					Kind:     ValidationFindingKindContentType,
+					Location: Location{"requestBody"},
+					Message:  "request body has an error: header Content-Type has unexpected value \"text/plain\"",
+				},
+			},
+		},
+		{
+			name: "response content type",
+			telemetry: func() *Telemetry {
+				telemetry := createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `name=rex`)
+				telemetry.Response.Common.Headers[0].Value = "text/plain"
+				return telemetry
+			}(),
+			want: []ValidationFinding{
+				{
+					Kind:     ValidationFindingKindContentType,
+					Location: Location{"responses", "200"},
+					Message:  "response header Content-Type has unexpected value: \"text/plain\"",
+				},
+			},
+		},
+		{
+			name:      "truncated response body is not validated",
+			telemetry: createTruncatedResponseTelemetry(createTelemetry("1", http.MethodGet, "/api/pets/1", "host", "200", "", `{"age": 31`)),
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ValidateTelemetry(tt.telemetry)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func createTruncatedResponseTelemetry(telemetry *Telemetry) *Telemetry {
+	telemetry.Response.Common.TruncatedBody = true
+	return telemetry
+}
+
+func TestSpec_DiffTelemetry_ValueValidation(t *testing.T) {
+	s := CreateDefaultSpec("host", "80", testOperationGeneratorConfig)
+	assert.NilError(t, s.LoadProvidedSpec(validationTestProvidedSpec, map[string]string{"/pets/{petId}": "1"}))
+	telemetry := createTelemetry("1", http.MethodGet, "/api/pets/1?status=lost", "host", "200", "", `{"name": "rex"}`)
+
+	got, err := s.DiffTelemetry(telemetry, SpecSourceProvided)
+	assert.NilError(t, err)
+	assert.Assert(t, got.ValidationFindings == nil)
+
+	got, err = s.DiffTelemetry(telemetry, SpecSourceProvided, WithValueValidation())
+	assert.NilError(t, err)
+	assert.Equal(t, len(got.ValidationFindings), 1)
+	assert.DeepEqual(t, got.ValidationFindings[0].Location, Location{"parameters", "query", "status"})
+}
@@ -20,10 +20,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/openclarity/speculator/pkg/pathtrie"
 	_spec "github.com/openclarity/speculator/pkg/spec"
 )
 
@@ -31,10 +33,20 @@ type SpecKey string
 
 type Config struct {
 	OperationGeneratorConfig _spec.OperationGeneratorConfig
+	// DiffMode of DiffTelemetry, exact diff is used by default
+	DiffMode _spec.DiffMode
+	// ValidateValues will validate the telemetry values against the provided spec in DiffTelemetry
+	ValidateValues bool
+	// SuppressionRules are applied in DiffTelemetry, rules with a spec key are applied only to that spec
+	SuppressionRules []_spec.SuppressionRule
+	// CanonicalFormat will generate the specs in a canonical form (see spec.WithCanonicalFormat)
+	CanonicalFormat bool
 }
 
 type Speculator struct {
 	Specs map[SpecKey]*_spec.Spec `json:"specs,omitempty"`
+	// SpecMetadata is the global metadata of the generated specs, overridden by the specs metadata
+	SpecMetadata *_spec.SpecMetadata `json:"specMetadata,omitempty"`
 
 	// config is not exported and is not encoded part of the state
 	config Config
@@ -43,6 +55,9 @@ type Speculator struct {
 // nolint:gochecknoinits
 func init() {
 	gob.Register(json.RawMessage{})
+	// diff changes values (persisted on the diff groups) are generic JSON objects
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
 }
 
 func CreateSpeculator(config Config) *Speculator {
@@ -153,7 +168,7 @@ func (s *Speculator) DiffTelemetry(telemetry *_spec.Telemetry, diffSource _spec.
 		return nil, fmt.Errorf("no spec for key %v", specKey)
 	}
 
-	apiDiff, err := spec.DiffTelemetry(telemetry, diffSource)
+	apiDiff, err := spec.DiffTelemetry(telemetry, diffSource, s.getDiffOptions(specKey)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run DiffTelemetry: %v", err)
 	}
@@ -161,6 +176,64 @@ func (s *Speculator) DiffTelemetry(telemetry *_spec.Telemetry, diffSource _spec.
 	return apiDiff, nil
 }
 
+// DiffTelemetryAggregated diffs the telemetry and aggregates the diff into the spec diff groups.
+// Returns an event only if the diff group is new or changed.
+func (s *Speculator) DiffTelemetryAggregated(telemetry *_spec.Telemetry, diffSource _spec.SpecSource) (*_spec.DiffGroupEvent, error) {
+	destInfo, err := GetAddressInfoFromAddress(telemetry.DestinationAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed get destination info: %v", err)
+	}
+	specKey := GetSpecKey(telemetry.Request.Host, destInfo.Port)
+	spec, ok := s.Specs[specKey]
+	if !ok {
+		return nil, fmt.Errorf("no spec for key %v", specKey)
+	}
+
+	event, err := spec.DiffTelemetryAggregated(telemetry, diffSource, s.getDiffOptions(specKey)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate diff of telemetry: %w", err)
+	}
+
+	return event, nil
+}
+
+func (s *Speculator) getDiffOptions(specKey SpecKey) []_spec.DiffOption {
+	var opts []_spec.DiffOption
+	if s.config.DiffMode != "" {
+		opts = append(opts, _spec.WithDiffMode(s.config.DiffMode))
+	}
+	if s.config.ValidateValues {
+		opts = append(opts, _spec.WithValueValidation())
+	}
+	if rules := getSpecSuppressionRules(s.config.SuppressionRules, specKey); len(rules) > 0 {
+		opts = append(opts, _spec.WithSuppressionRules(rules))
+	}
+
+	return opts
+}
+
+// getSpecSuppressionRules returns the global rules and the rules scoped to the spec key.
+func getSpecSuppressionRules(rules []_spec.SuppressionRule, specKey SpecKey) []_spec.SuppressionRule {
+	var ret []_spec.SuppressionRule
+	for _, rule := range rules {
+		if rule.SpecKey == "" || SpecKey(rule.SpecKey) == specKey {
+			ret = append(ret, rule)
+		}
+	}
+
+	return ret
+}
+
+// GetDiffGroups returns the aggregated diff groups of the spec.
+func (s *Speculator) GetDiffGroups(key SpecKey) ([]_spec.DiffGroup, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	return spec.GetDiffGroups(), nil
+}
+
 func (s *Speculator) HasApprovedSpec(key SpecKey) bool {
 	spec, ok := s.Specs[key]
 	if !ok {
@@ -179,19 +252,47 @@ func (s *Speculator) GetApprovedSpecVersion(key SpecKey) _spec.OASVersion {
 	return spec.ApprovedSpec.GetSpecVersion()
 }
 
-func (s *Speculator) LoadProvidedSpec(key SpecKey, providedSpec []byte, pathToPathID map[string]string) error {
+// GetPathIDs returns the path template to path ID mapping of the spec source.
+func (s *Speculator) GetPathIDs(specKey SpecKey, specSource _spec.SpecSource) (map[string]string, error) {
+	spec, ok := s.Specs[specKey]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", specKey)
+	}
+
+	pathIDs, err := spec.GetPathIDs(specSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path IDs: %v", err)
+	}
+
+	return pathIDs, nil
+}
+
+func (s *Speculator) LoadProvidedSpec(key SpecKey, providedSpec []byte, pathToPathID map[string]string, opts ..._spec.PathIDOption) error {
 	spec, ok := s.Specs[key]
 	if !ok {
 		return fmt.Errorf("no spec found with key: %v", key)
 	}
 
-	if err := spec.LoadProvidedSpec(providedSpec, pathToPathID); err != nil {
+	if err := spec.LoadProvidedSpec(providedSpec, pathToPathID, opts...); err != nil {
 		return fmt.Errorf("failed to load provided spec: %w", err)
 	}
 
 	return nil
 }
 
+func (s *Speculator) LoadProvidedSpecBundle(key SpecKey, bundle *_spec.SpecBundle, pathToPathID map[string]string, opts ..._spec.PathIDOption) error {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	if err := spec.LoadProvidedSpecBundle(bundle, pathToPathID, opts...); err != nil {
+		return fmt.Errorf("failed to load provided spec bundle: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Speculator) UnsetProvidedSpec(key SpecKey) error {
 	spec, ok := s.Specs[key]
 	if !ok {
@@ -219,6 +320,75 @@ func (s *Speculator) HasProvidedSpec(key SpecKey) bool {
 	return spec.HasProvidedSpec()
 }
 
+func (s *Speculator) GetProvidedSpecAmbiguousPaths(key SpecKey) ([]pathtrie.AmbiguousPaths, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	return spec.GetProvidedSpecAmbiguousPaths(), nil
+}
+
+// CompareProvidedWithApproved compares the provided spec with the approved reconstructed spec.
+func (s *Speculator) CompareProvidedWithApproved(key SpecKey) (*_spec.SpecComparison, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	comparison, err := spec.CompareProvidedWithApproved()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare provided with approved spec: %w", err)
+	}
+
+	return comparison, nil
+}
+
+// GetProvidedSpecCoverage returns the coverage report of the provided spec by the observed traffic.
+func (s *Speculator) GetProvidedSpecCoverage(key SpecKey) (*_spec.CoverageReport, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	report, err := spec.GetProvidedSpecCoverage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provided spec coverage: %w", err)
+	}
+
+	return report, nil
+}
+
+// AdoptProvidedSpec seeds the approved spec with the provided spec.
+func (s *Speculator) AdoptProvidedSpec(key SpecKey) error {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	if err := spec.AdoptProvidedSpec(); err != nil {
+		return fmt.Errorf("failed to adopt provided spec: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteProvidedSpec proposes an updated provided spec with the observed undocumented paths, operations,
+// parameters and status codes.
+func (s *Speculator) CompleteProvidedSpec(key SpecKey) (*_spec.ProvidedSpecCompletion, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	completion, err := spec.CompleteProvidedSpec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete provided spec: %w", err)
+	}
+
+	return completion, nil
+}
+
 func (s *Speculator) GetProvidedSpecVersion(key SpecKey) _spec.OASVersion {
 	spec, ok := s.Specs[key]
 	if !ok {
@@ -228,10 +398,101 @@ func (s *Speculator) GetProvidedSpecVersion(key SpecKey) _spec.OASVersion {
 	return spec.ProvidedSpec.GetSpecVersion()
 }
 
+// SetGlobalSpecMetadata sets the metadata of all the generated specs, used for the fields that are not set by the spec metadata.
+func (s *Speculator) SetGlobalSpecMetadata(metadata *_spec.SpecMetadata) {
+	s.SpecMetadata = metadata
+}
+
+// SetSpecMetadata sets the metadata of the generated spec.
+func (s *Speculator) SetSpecMetadata(key SpecKey, metadata *_spec.SpecMetadata) error {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	spec.SetMetadata(metadata)
+	return nil
+}
+
+// SetOperationOverride overrides the operation id, summary and tags of the approved path operations.
+func (s *Speculator) SetOperationOverride(key SpecKey, path string, override *_spec.OperationOverride) error {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	if err := spec.SetOperationOverride(path, override); err != nil {
+		return fmt.Errorf("failed to set operation override: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateOASJson generates the approved spec with the spec and global metadata.
+func (s *Speculator) GenerateOASJson(key SpecKey, version _spec.OASVersion) ([]byte, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	oasJSON, err := spec.GenerateOASJson(version, s.getGenerateOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Open API Spec: %w", err)
+	}
+
+	return oasJSON, nil
+}
+
+// GenerateOASYaml generates the approved spec with the spec and global metadata.
+func (s *Speculator) GenerateOASYaml(key SpecKey, version _spec.OASVersion) ([]byte, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	oasYaml, err := spec.GenerateOASYaml(version, s.getGenerateOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Open API Spec: %w", err)
+	}
+
+	return oasYaml, nil
+}
+
+// ExportPostmanCollection exports the approved or the learning spec to a Postman Collection v2.1.
+func (s *Speculator) ExportPostmanCollection(key SpecKey, source _spec.ExportSource) (*_spec.PostmanCollection, error) {
+	spec, ok := s.Specs[key]
+	if !ok {
+		return nil, fmt.Errorf("no spec found with key: %v", key)
+	}
+
+	collection, err := spec.ExportPostmanCollection(source, s.getGenerateOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export Postman collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+func (s *Speculator) getGenerateOptions() []_spec.GenerateOption {
+	opts := []_spec.GenerateOption{_spec.WithGlobalMetadata(s.SpecMetadata)}
+	if s.config.CanonicalFormat {
+		opts = append(opts, _spec.WithCanonicalFormat())
+	}
+
+	return opts
+}
+
 func (s *Speculator) DumpSpecs() {
 	log.Infof("Generating Open API Specs...\n")
-	for specKey, spec := range s.Specs {
-		approvedYaml, err := spec.GenerateOASYaml(_spec.OASv3)
+	specKeys := make([]SpecKey, 0, len(s.Specs))
+	for specKey := range s.Specs {
+		specKeys = append(specKeys, specKey)
+	}
+	sort.Slice(specKeys, func(i, j int) bool {
+		return specKeys[i] < specKeys[j]
+	})
+	for _, specKey := range specKeys {
+		approvedYaml, err := s.GenerateOASYaml(specKey, _spec.OASv3)
 		if err != nil {
 			log.Errorf("failed to generate OAS yaml for %v.: %v", specKey, err)
 			continue
@@ -277,6 +538,11 @@ func DecodeState(filePath string, config Config) (*Speculator, error) {
 	}
 
 	r.config = config
+	for _, spec := range r.Specs {
+		// states encoded before the path param nodes were kept apart have them under the static nodes
+		spec.ApprovedPathTrie.NormalizeParamChildren()
+		spec.ProvidedPathTrie.NormalizeParamChildren()
+	}
 
 	log.Info("Speculator state was decoded")
 	log.Debugf("Speculator Config %+v", config)
@@ -136,3 +136,85 @@ func TestDecodeState(t *testing.T) {
 		return
 	}
 }
+
+func TestDecodeState_DiffGroups(t *testing.T) {
+	testSpec := GetSpecKey("host", "port")
+	testStatePath := "/tmp/" + uuid.NewV4().String() + "state.gob"
+	defer func() {
+		_ = os.Remove(testStatePath)
+	}()
+
+	speculator := CreateSpeculator(Config{})
+	speculator.Specs[testSpec] = spec.CreateDefaultSpec("host", "port", speculator.config.OperationGeneratorConfig)
+	speculator.Specs[testSpec].DiffGroups = map[string]*spec.DiffGroup{
+		"fingerprint": {
+			Fingerprint: "fingerprint",
+			Count:       3,
+			Changes: []spec.DiffChange{
+				{
+					Location: spec.Location{"parameters", "query", "limit"},
+					Kind:     spec.DiffChangeKindAdded,
+					NewValue: map[string]interface{}{"in": "query", "name": "limit", "tags": []interface{}{"a"}},
+				},
+			},
+		},
+	}
+
+	if err := speculator.EncodeState(testStatePath); err != nil {
+		t.Errorf("EncodeState() error = %v", err)
+		return
+	}
+
+	got, err := DecodeState(testStatePath, Config{})
+	if err != nil {
+		t.Errorf("DecodeState() error = %v", err)
+		return
+	}
+
+	groups, err := got.GetDiffGroups(testSpec)
+	if err != nil {
+		t.Errorf("GetDiffGroups() error = %v", err)
+		return
+	}
+	if len(groups) != 1 || groups[0].Count != 3 || len(groups[0].Changes) != 1 {
+		t.Errorf("GetDiffGroups() not as expected = %+v", groups)
+	}
+}
+
+func TestDecodeState_SpecMetadata(t *testing.T) {
+	testSpec := GetSpecKey("host", "8080")
+	testStatePath := "/tmp/" + uuid.NewV4().String() + "state.gob"
+	defer func() {
+		_ = os.Remove(testStatePath)
+	}()
+
+	speculator := CreateSpeculator(Config{})
+	speculator.Specs[testSpec] = spec.CreateDefaultSpec("host", "8080", speculator.config.OperationGeneratorConfig)
+	speculator.SetGlobalSpecMetadata(&spec.SpecMetadata{Title: "Global", Version: "2.0.0"})
+	if err := speculator.SetSpecMetadata(testSpec, &spec.SpecMetadata{Title: "Spec"}); err != nil {
+		t.Errorf("SetSpecMetadata() error = %v", err)
+		return
+	}
+	if err := speculator.SetSpecMetadata(GetSpecKey("other", "80"), &spec.SpecMetadata{}); err == nil {
+		t.Errorf("SetSpecMetadata() expected an error for a missing spec")
+		return
+	}
+
+	if err := speculator.EncodeState(testStatePath); err != nil {
+		t.Errorf("EncodeState() error = %v", err)
+		return
+	}
+
+	got, err := DecodeState(testStatePath, Config{})
+	if err != nil {
+		t.Errorf("DecodeState() error = %v", err)
+		return
+	}
+
+	if got.SpecMetadata == nil || got.SpecMetadata.Title != "Global" {
+		t.Errorf("SpecMetadata not as expected = %+v", got.SpecMetadata)
+	}
+	if metadata := got.Specs[testSpec].Metadata; metadata == nil || metadata.Title != "Spec" {
+		t.Errorf("Spec Metadata not as expected = %+v", metadata)
+	}
+}
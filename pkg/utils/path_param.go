@@ -22,7 +22,96 @@ const (
 	ParamSuffix = "}"
 )
 
+// IsPathParam returns true if the whole segment is a single path param, e.g. `{id}`.
 func IsPathParam(segment string) bool {
 	return strings.HasPrefix(segment, ParamPrefix) &&
-		strings.HasSuffix(segment, ParamSuffix)
+		strings.HasSuffix(segment, ParamSuffix) &&
+		strings.Count(segment, ParamPrefix) == 1 &&
+		strings.Count(segment, ParamSuffix) == 1
+}
+
+// HasPathParam returns true if the segment holds at least one path param,
+// either as the whole segment (e.g. `{id}`) or embedded in it (e.g. `{name}.{ext}`, `report-{id}`).
+func HasPathParam(segment string) bool {
+	for _, token := range SplitPathSegmentTemplate(segment) {
+		if IsPathParam(token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SplitPathSegmentTemplate splits a segment into literal and param tokens,
+// e.g. `report-{id}.{ext}` will be split into `report-`, `{id}`, `.`, `{ext}`.
+// An unclosed param prefix is treated as a literal.
+func SplitPathSegmentTemplate(segment string) []string {
+	var tokens []string
+
+	for len(segment) > 0 {
+		start := strings.Index(segment, ParamPrefix)
+		if start < 0 {
+			return append(tokens, segment)
+		}
+		end := strings.Index(segment[start:], ParamSuffix)
+		if end < 0 {
+			return append(tokens, segment)
+		}
+		end += start + len(ParamSuffix)
+
+		if start > 0 {
+			tokens = append(tokens, segment[:start])
+		}
+		tokens = append(tokens, segment[start:end])
+		segment = segment[end:]
+	}
+
+	return tokens
+}
+
+// ClearPathParamNames replaces every param name in the path (or segment) with an empty param,
+// e.g. `/users/{id}/report-{reportId}.{ext}` will be `/users/{}/report-{}.{}`.
+// Path templates that differ only by their param names are cleared into the same path.
+func ClearPathParamNames(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		tokens := SplitPathSegmentTemplate(segment)
+		for j, token := range tokens {
+			if IsPathParam(token) {
+				tokens[j] = ParamPrefix + ParamSuffix
+			}
+		}
+		segments[i] = strings.Join(tokens, "")
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// MatchPathSegmentTemplate checks if the segment matches the template, and returns the values of the template params
+// (by order of appearance). e.g. `report-{id}.{ext}` will match `report-1.json` and return `1`, `json`.
+// Every param must match at least one char.
+func MatchPathSegmentTemplate(template, segment string) ([]string, bool) {
+	return matchPathSegmentTokens(SplitPathSegmentTemplate(template), segment)
+}
+
+func matchPathSegmentTokens(tokens []string, segment string) ([]string, bool) {
+	if len(tokens) == 0 {
+		return nil, segment == ""
+	}
+
+	token := tokens[0]
+	if !IsPathParam(token) {
+		if !strings.HasPrefix(segment, token) {
+			return nil, false
+		}
+		return matchPathSegmentTokens(tokens[1:], segment[len(token):])
+	}
+
+	for i := 1; i <= len(segment); i++ {
+		if values, ok := matchPathSegmentTokens(tokens[1:], segment[i:]); ok {
+			return append([]string{segment[:i]}, values...), true
+		}
+	}
+
+	return nil, false
 }
@@ -0,0 +1,226 @@
+// Copyright © 2021 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsPathParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment string
+		want    bool
+	}{
+		{
+			name:    "path param",
+			segment: "{id}",
+			want:    true,
+		},
+		{
+			name:    "static segment",
+			segment: "id",
+			want:    false,
+		},
+		{
+			name:    "partial path params",
+			segment: "{name}.{ext}",
+			want:    false,
+		},
+		{
+			name:    "prefixed path param",
+			segment: "report-{id}",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPathParam(tt.segment); got != tt.want {
+				t.Errorf("IsPathParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasPathParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment string
+		want    bool
+	}{
+		{
+			name:    "path param",
+			segment: "{id}",
+			want:    true,
+		},
+		{
+			name:    "partial path params",
+			segment: "{name}.{ext}",
+			want:    true,
+		},
+		{
+			name:    "static segment",
+			segment: "report",
+			want:    false,
+		},
+		{
+			name:    "unclosed param",
+			segment: "report-{id",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasPathParam(tt.segment); got != tt.want {
+				t.Errorf("HasPathParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitPathSegmentTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment string
+		want    []string
+	}{
+		{
+			name:    "static segment",
+			segment: "report",
+			want:    []string{"report"},
+		},
+		{
+			name:    "path param",
+			segment: "{id}",
+			want:    []string{"{id}"},
+		},
+		{
+			name:    "partial path params",
+			segment: "report-{id}.{ext}",
+			want:    []string{"report-", "{id}", ".", "{ext}"},
+		},
+		{
+			name:    "unclosed param",
+			segment: "{id}-{ext",
+			want:    []string{"{id}", "-{ext"},
+		},
+		{
+			name:    "empty segment",
+			segment: "",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitPathSegmentTemplate(tt.segment); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitPathSegmentTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearPathParamNames(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "no params",
+			path: "/api/users",
+			want: "/api/users",
+		},
+		{
+			name: "whole and partial segment params",
+			path: "/users/{id}/report-{reportId}.{ext}",
+			want: "/users/{}/report-{}.{}",
+		},
+		{
+			name: "segment",
+			path: "{name}.{ext}",
+			want: "{}.{}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClearPathParamNames(tt.path); got != tt.want {
+				t.Errorf("ClearPathParamNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPathSegmentTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		segment    string
+		wantValues []string
+		wantMatch  bool
+	}{
+		{
+			name:       "path param",
+			template:   "{id}",
+			segment:    "1",
+			wantValues: []string{"1"},
+			wantMatch:  true,
+		},
+		{
+			name:       "partial path params",
+			template:   "{name}.{ext}",
+			segment:    "file.tar.gz",
+			wantValues: []string{"file", "tar.gz"},
+			wantMatch:  true,
+		},
+		{
+			name:       "prefixed path param",
+			template:   "report-{id}",
+			segment:    "report-1",
+			wantValues: []string{"1"},
+			wantMatch:  true,
+		},
+		{
+			name:      "missing literal",
+			template:  "{name}.{ext}",
+			segment:   "file",
+			wantMatch: false,
+		},
+		{
+			name:      "empty param value",
+			template:  "report-{id}",
+			segment:   "report-",
+			wantMatch: false,
+		},
+		{
+			name:      "static segment not match",
+			template:  "report",
+			segment:   "reports",
+			wantMatch: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValues, gotMatch := MatchPathSegmentTemplate(tt.template, tt.segment)
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("MatchPathSegmentTemplate() gotValues = %v, want %v", gotValues, tt.wantValues)
+			}
+			if gotMatch != tt.wantMatch {
+				t.Errorf("MatchPathSegmentTemplate() gotMatch = %v, want %v", gotMatch, tt.wantMatch)
+			}
+		})
+	}
+}